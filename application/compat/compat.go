@@ -0,0 +1,424 @@
+// Package compat detects breaking changes between two plugin manifests and
+// suggests the semantic version bump a release requires.
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+)
+
+// ChangeKind classifies a manifest change by its impact on hosts and policies.
+type ChangeKind int
+
+const (
+	// Cosmetic changes do not affect behaviour (descriptions, examples, narrowed grants).
+	Cosmetic ChangeKind = iota
+	// Additive changes extend the plugin without breaking existing callers.
+	Additive
+	// Breaking changes can break existing host policies or callers.
+	Breaking
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Cosmetic:
+		return "cosmetic"
+	case Additive:
+		return "additive"
+	case Breaking:
+		return "breaking"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Bump is a semantic version increment.
+type Bump int
+
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpNone:
+		return "none"
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Bump) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Change describes a single difference between two manifests.
+type Change struct {
+	// Path locates the change, e.g. "services.dns.operations.resolve.input.hostname".
+	Path    string     `json:"path"`
+	Message string     `json:"message"`
+	Kind    ChangeKind `json:"kind"`
+}
+
+// Report is the result of comparing two manifests.
+type Report struct {
+	OldVersion string `json:"old_version"`
+	NewVersion string `json:"new_version"`
+	// SuggestedVersion is the lowest version satisfying RequiredBump, empty if
+	// OldVersion is not a semantic version.
+	SuggestedVersion string   `json:"suggested_version,omitempty"`
+	Changes          []Change `json:"changes"`
+	RequiredBump     Bump     `json:"required_bump"`
+	// VersionOK is true if NewVersion is at least SuggestedVersion.
+	VersionOK bool `json:"version_ok"`
+}
+
+// HasBreaking reports whether any change is breaking.
+func (r *Report) HasBreaking() bool {
+	return len(r.ByKind(Breaking)) > 0
+}
+
+// ByKind returns the changes of the given kind.
+func (r *Report) ByKind(kind ChangeKind) []Change {
+	var out []Change
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// LoadManifest reads a JSON manifest from a file.
+func LoadManifest(path string) (*entities.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m entities.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// CompareFile compares a baseline manifest stored as JSON against a current
+// manifest, typically one produced by a live PluginDefinition.
+func CompareFile(baselinePath string, current *entities.Manifest) (*Report, error) {
+	baseline, err := LoadManifest(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(baseline, current), nil
+}
+
+// Compare classifies every difference between oldM and newM and suggests the
+// version bump the change set requires relative to oldM.Version.
+func Compare(oldM, newM *entities.Manifest) *Report {
+	d := &differ{}
+
+	if oldM.MinHostVersion != newM.MinHostVersion {
+		kind := Additive
+		if newM.MinHostVersion != "" && compareVersions(newM.MinHostVersion, oldM.MinHostVersion) > 0 {
+			kind = Breaking
+		}
+		d.add(kind, "min_host_version", "changed from %q to %q", oldM.MinHostVersion, newM.MinHostVersion)
+	}
+	if oldM.Description != newM.Description {
+		d.add(Cosmetic, "description", "description changed")
+	}
+
+	d.compareSchemas("config_schema", oldM.ConfigSchema, newM.ConfigSchema, inputDirection)
+	d.compareGrants("capabilities", &oldM.Capabilities, &newM.Capabilities)
+	d.compareServices(oldM.Services, newM.Services)
+
+	report := &Report{
+		OldVersion: oldM.Version,
+		NewVersion: newM.Version,
+		Changes:    d.changes,
+	}
+	report.RequiredBump = requiredBump(d.changes, oldM.Version)
+	if suggested, ok := bumpVersion(oldM.Version, report.RequiredBump); ok {
+		report.SuggestedVersion = suggested
+		report.VersionOK = compareVersions(newM.Version, suggested) >= 0
+	}
+	return report
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path, format string, args ...any) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) compareServices(oldSvcs, newSvcs map[string]entities.ServiceManifest) {
+	for _, name := range unionKeys(oldSvcs, newSvcs) {
+		path := "services." + name
+		oldSvc, inOld := oldSvcs[name]
+		newSvc, inNew := newSvcs[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "service removed")
+			continue
+		case !inOld:
+			d.add(Additive, path, "service added")
+			continue
+		}
+		if oldSvc.Description != newSvc.Description {
+			d.add(Cosmetic, path, "description changed")
+		}
+		d.compareOperations(path, oldSvc.Operations, newSvc.Operations)
+	}
+}
+
+func (d *differ) compareOperations(svcPath string, oldOps, newOps []entities.OperationManifest) {
+	oldByName := indexOperations(oldOps)
+	newByName := indexOperations(newOps)
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		path := svcPath + ".operations." + name
+		oldOp, inOld := oldByName[name]
+		newOp, inNew := newByName[name]
+		switch {
+		case !inNew:
+			d.add(Breaking, path, "operation removed")
+			continue
+		case !inOld:
+			d.add(Additive, path, "operation added")
+			if newOp.Capabilities != nil && !newOp.Capabilities.IsEmpty() {
+				d.compareGrants(path+".capabilities", nil, newOp.Capabilities)
+			}
+			continue
+		}
+
+		if oldOp.Description != newOp.Description {
+			d.add(Cosmetic, path, "description changed")
+		}
+		if !examplesEqual(oldOp.Examples, newOp.Examples) {
+			d.add(Cosmetic, path+".examples", "examples changed")
+		}
+		if len(oldOp.InputSchema) > 0 && len(newOp.InputSchema) > 0 {
+			d.compareSchemas(path+".input", oldOp.InputSchema, newOp.InputSchema, inputDirection)
+		} else {
+			d.compareInputFields(path+".input", oldOp.InputFields, newOp.InputFields)
+		}
+		d.compareSchemas(path+".output", oldOp.OutputSchema, newOp.OutputSchema, outputDirection)
+		d.compareGrants(path+".capabilities", oldOp.Capabilities, newOp.Capabilities)
+	}
+}
+
+// compareInputFields compares the top-level input field lists of an operation,
+// used when either manifest predates input schemas.
+func (d *differ) compareInputFields(path string, oldFields, newFields []string) {
+	oldSet := toSet(oldFields)
+	newSet := toSet(newFields)
+	for _, f := range sortedSet(oldSet) {
+		if !newSet[f] {
+			d.add(Breaking, path+"."+f, "input field removed")
+		}
+	}
+	for _, f := range sortedSet(newSet) {
+		if !oldSet[f] {
+			d.add(Additive, path+"."+f, "input field added")
+		}
+	}
+}
+
+// compareGrants reports widened grants as breaking (operators must approve
+// them) and narrowed grants as cosmetic.
+func (d *differ) compareGrants(path string, oldG, newG *entities.GrantSet) {
+	if oldG == nil {
+		oldG = &entities.GrantSet{}
+	}
+	if newG == nil {
+		newG = &entities.GrantSet{}
+	}
+	if added := newG.Difference(oldG); !added.IsEmpty() {
+		d.add(Breaking, path, "capabilities widened: %s", describeGrants(added))
+	}
+	if removed := oldG.Difference(newG); !removed.IsEmpty() {
+		d.add(Cosmetic, path, "capabilities narrowed: %s", describeGrants(removed))
+	}
+}
+
+// requiredBump maps the most severe change to a version increment.
+// Pre-1.0 versions bump minor for breaking changes and patch for additive ones.
+func requiredBump(changes []Change, oldVersion string) Bump {
+	severity := -1
+	for _, c := range changes {
+		if int(c.Kind) > severity {
+			severity = int(c.Kind)
+		}
+	}
+	preRelease := false
+	if v, ok := parseVersion(oldVersion); ok && v[0] == 0 {
+		preRelease = true
+	}
+
+	switch {
+	case severity < 0:
+		return BumpNone
+	case ChangeKind(severity) == Breaking && preRelease:
+		return BumpMinor
+	case ChangeKind(severity) == Breaking:
+		return BumpMajor
+	case ChangeKind(severity) == Additive && preRelease:
+		return BumpPatch
+	case ChangeKind(severity) == Additive:
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// parseVersion parses "MAJOR.MINOR.PATCH" with an optional "v" prefix,
+// ignoring any pre-release or build suffix.
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+func bumpVersion(v string, bump Bump) (string, bool) {
+	parsed, ok := parseVersion(v)
+	if !ok {
+		return "", false
+	}
+	switch bump {
+	case BumpMajor:
+		parsed = [3]int{parsed[0] + 1, 0, 0}
+	case BumpMinor:
+		parsed = [3]int{parsed[0], parsed[1] + 1, 0}
+	case BumpPatch:
+		parsed[2]++
+	}
+	return fmt.Sprintf("%d.%d.%d", parsed[0], parsed[1], parsed[2]), true
+}
+
+// compareVersions returns -1, 0 or 1. Unparseable versions compare as equal.
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0
+	}
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func describeGrants(g *entities.GrantSet) string {
+	var parts []string
+	if g.Network != nil {
+		for _, r := range g.Network.Rules {
+			parts = append(parts, fmt.Sprintf("network %v:%v", r.Hosts, r.Ports))
+		}
+	}
+	if g.FS != nil {
+		for _, r := range g.FS.Rules {
+			if len(r.Read) > 0 {
+				parts = append(parts, fmt.Sprintf("fs read %v", r.Read))
+			}
+			if len(r.Write) > 0 {
+				parts = append(parts, fmt.Sprintf("fs write %v", r.Write))
+			}
+		}
+	}
+	if g.Env != nil && len(g.Env.Variables) > 0 {
+		parts = append(parts, fmt.Sprintf("env %v", g.Env.Variables))
+	}
+	if g.Exec != nil && len(g.Exec.Commands) > 0 {
+		parts = append(parts, fmt.Sprintf("exec %v", g.Exec.Commands))
+	}
+	if g.KV != nil {
+		for _, r := range g.KV.Rules {
+			parts = append(parts, fmt.Sprintf("kv %s %v", r.Operation, r.Keys))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func indexOperations(ops []entities.OperationManifest) map[string]entities.OperationManifest {
+	m := make(map[string]entities.OperationManifest, len(ops))
+	for _, op := range ops {
+		m[op.Name] = op
+	}
+	return m
+}
+
+func examplesEqual(a, b []entities.OperationExample) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	return sortedSet(set)
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
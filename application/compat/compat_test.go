@@ -0,0 +1,292 @@
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func baseManifest() *entities.Manifest {
+	return &entities.Manifest{
+		Name:    "dns",
+		Version: "1.2.3",
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"hostname": {"type": "string"},
+				"record_type": {"type": "string", "enum": ["A", "AAAA"], "default": "A"}
+			},
+			"required": ["hostname"],
+			"additionalProperties": false
+		}`),
+		Capabilities: entities.GrantSet{
+			Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{"*"}, Ports: []string{"53"}}}},
+		},
+		Services: map[string]entities.ServiceManifest{
+			"dns": {
+				Name: "dns",
+				Operations: []entities.OperationManifest{
+					{
+						Name:         "resolve",
+						Description:  "Resolve a hostname",
+						InputFields:  []string{"hostname", "record_type"},
+						OutputSchema: json.RawMessage(`{"$ref":"#/$defs/Out","$defs":{"Out":{"type":"object","properties":{"records":{"type":"array","items":{"type":"string"}}},"required":["records"]}}}`),
+					},
+				},
+			},
+		},
+	}
+}
+
+// clone deep-copies a manifest through JSON.
+func clone(t *testing.T, m *entities.Manifest) *entities.Manifest {
+	t.Helper()
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	var out entities.Manifest
+	require.NoError(t, json.Unmarshal(data, &out))
+	return &out
+}
+
+func TestCompare_Identical(t *testing.T) {
+	m := baseManifest()
+	report := Compare(m, clone(t, m))
+
+	assert.Empty(t, report.Changes)
+	assert.Equal(t, BumpNone, report.RequiredBump)
+	assert.Equal(t, "1.2.3", report.SuggestedVersion)
+	assert.True(t, report.VersionOK)
+}
+
+func TestCompare_Cosmetic(t *testing.T) {
+	oldM := baseManifest()
+	newM := clone(t, oldM)
+	newM.Version = "1.2.4"
+	newM.Description = "new description"
+	svc := newM.Services["dns"]
+	svc.Operations[0].Description = "Resolve a DNS name"
+	newM.Services["dns"] = svc
+
+	report := Compare(oldM, newM)
+
+	assert.Len(t, report.ByKind(Cosmetic), 2)
+	assert.False(t, report.HasBreaking())
+	assert.Equal(t, BumpPatch, report.RequiredBump)
+	assert.Equal(t, "1.2.4", report.SuggestedVersion)
+	assert.True(t, report.VersionOK)
+}
+
+func TestCompare_Additive(t *testing.T) {
+	oldM := baseManifest()
+	newM := clone(t, oldM)
+	newM.ConfigSchema = json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"hostname": {"type": "string"},
+			"record_type": {"type": "string", "enum": ["A", "AAAA", "MX"], "default": "A"},
+			"nameserver": {"type": "string"}
+		},
+		"required": ["hostname"]
+	}`)
+	svc := newM.Services["dns"]
+	svc.Operations = append(svc.Operations, entities.OperationManifest{Name: "reverse"})
+	newM.Services["dns"] = svc
+
+	report := Compare(oldM, newM)
+
+	assert.False(t, report.HasBreaking(), "%+v", report.Changes)
+	assert.Contains(t, report.Changes, Change{Kind: Additive, Path: "config_schema.nameserver", Message: "field added"})
+	assert.Contains(t, report.Changes, Change{Kind: Additive, Path: "config_schema.record_type", Message: `enum values added: "MX"`})
+	assert.Contains(t, report.Changes, Change{Kind: Additive, Path: "services.dns.operations.reverse", Message: "operation added"})
+	assert.Equal(t, BumpMinor, report.RequiredBump)
+	assert.Equal(t, "1.3.0", report.SuggestedVersion)
+	assert.False(t, report.VersionOK, "version was not bumped")
+}
+
+func TestCompare_Breaking(t *testing.T) {
+	tests := []struct {
+		mutate func(m *entities.Manifest)
+		name   string
+		path   string
+	}{
+		{
+			name: "service removed",
+			path: "services.dns",
+			mutate: func(m *entities.Manifest) {
+				delete(m.Services, "dns")
+			},
+		},
+		{
+			name: "operation removed",
+			path: "services.dns.operations.resolve",
+			mutate: func(m *entities.Manifest) {
+				svc := m.Services["dns"]
+				svc.Operations = nil
+				m.Services["dns"] = svc
+			},
+		},
+		{
+			name: "input field removed",
+			path: "services.dns.operations.resolve.input.record_type",
+			mutate: func(m *entities.Manifest) {
+				m.Services["dns"].Operations[0].InputFields = []string{"hostname"}
+			},
+		},
+		{
+			name: "required config field added",
+			path: "config_schema.timeout",
+			mutate: func(m *entities.Manifest) {
+				m.ConfigSchema = json.RawMessage(`{"type":"object","properties":{"hostname":{"type":"string"},"record_type":{"type":"string","enum":["A","AAAA"],"default":"A"},"timeout":{"type":"integer"}},"required":["hostname","timeout"]}`)
+			},
+		},
+		{
+			name: "config enum narrowed",
+			path: "config_schema.record_type",
+			mutate: func(m *entities.Manifest) {
+				m.ConfigSchema = json.RawMessage(`{"type":"object","properties":{"hostname":{"type":"string"},"record_type":{"type":"string","enum":["A"],"default":"A"}},"required":["hostname"]}`)
+			},
+		},
+		{
+			name: "output field retyped",
+			path: "services.dns.operations.resolve.output.records[]",
+			mutate: func(m *entities.Manifest) {
+				m.Services["dns"].Operations[0].OutputSchema = json.RawMessage(`{"type":"object","properties":{"records":{"type":"array","items":{"type":"integer"}}},"required":["records"]}`)
+			},
+		},
+		{
+			name: "capabilities widened",
+			path: "capabilities",
+			mutate: func(m *entities.Manifest) {
+				m.Capabilities.Exec = &entities.ExecCapability{Commands: []string{"dig"}}
+			},
+		},
+		{
+			name: "operation capabilities widened",
+			path: "services.dns.operations.resolve.capabilities",
+			mutate: func(m *entities.Manifest) {
+				m.Services["dns"].Operations[0].Capabilities = &entities.GrantSet{
+					Env: &entities.EnvironmentCapability{Variables: []string{"RESOLVER"}},
+				}
+			},
+		},
+		{
+			name: "min host version raised",
+			path: "min_host_version",
+			mutate: func(m *entities.Manifest) {
+				m.MinHostVersion = "2.0.0"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldM := baseManifest()
+			oldM.MinHostVersion = "1.0.0"
+			newM := clone(t, oldM)
+			tt.mutate(newM)
+
+			report := Compare(oldM, newM)
+
+			breaking := report.ByKind(Breaking)
+			require.NotEmpty(t, breaking, "%+v", report.Changes)
+			assert.Equal(t, tt.path, breaking[0].Path)
+			assert.Equal(t, BumpMajor, report.RequiredBump)
+			assert.Equal(t, "2.0.0", report.SuggestedVersion)
+		})
+	}
+}
+
+func TestCompare_InputSchema(t *testing.T) {
+	oldM := baseManifest()
+	oldM.Services["dns"].Operations[0].InputSchema = json.RawMessage(`{"type":"object","properties":{"hostname":{"type":"string"},"server":{"$ref":"#/$defs/Server"}},"required":["hostname"],"$defs":{"Server":{"type":"object","properties":{"port":{"type":"integer"}}}}}`)
+	newM := clone(t, oldM)
+	newM.Services["dns"].Operations[0].InputSchema = json.RawMessage(`{"type":"object","properties":{"hostname":{"type":"string"},"server":{"$ref":"#/$defs/Server"}},"required":["hostname"],"$defs":{"Server":{"type":"object","properties":{"port":{"type":"integer"},"host":{"type":"string"}},"required":["host"]}}}`)
+	// Field lists are ignored once both sides publish schemas.
+	newM.Services["dns"].Operations[0].InputFields = []string{"hostname"}
+
+	report := Compare(oldM, newM)
+
+	require.Len(t, report.Changes, 1, "%+v", report.Changes)
+	assert.Equal(t, Change{Kind: Breaking, Path: "services.dns.operations.resolve.input.server.host", Message: "required field added"}, report.Changes[0])
+}
+
+func TestCompare_RecursiveSchema(t *testing.T) {
+	const tree = `{"$ref":"#/$defs/Node","$defs":{"Node":{"type":"object","properties":{"name":{"type":"string"%s},"children":{"type":"array","items":{"$ref":"#/$defs/Node"}}}}}}`
+	oldM := baseManifest()
+	oldM.Services["dns"].Operations[0].OutputSchema = json.RawMessage(fmt.Sprintf(tree, ""))
+
+	assert.Empty(t, Compare(oldM, clone(t, oldM)).Changes)
+
+	newM := clone(t, oldM)
+	newM.Services["dns"].Operations[0].OutputSchema = json.RawMessage(fmt.Sprintf(tree, `,"description":"Node name"`))
+	report := Compare(oldM, newM)
+
+	require.Len(t, report.Changes, 1, "%+v", report.Changes)
+	assert.Equal(t, Change{Kind: Cosmetic, Path: "services.dns.operations.resolve.output.name", Message: "description changed"}, report.Changes[0])
+}
+
+func TestCompare_CapabilitiesNarrowedIsCosmetic(t *testing.T) {
+	oldM := baseManifest()
+	newM := clone(t, oldM)
+	newM.Version = "1.2.4"
+	newM.Capabilities = entities.GrantSet{}
+
+	report := Compare(oldM, newM)
+
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, Cosmetic, report.Changes[0].Kind)
+	assert.Contains(t, report.Changes[0].Message, "capabilities narrowed")
+}
+
+func TestCompare_PreReleaseVersion(t *testing.T) {
+	oldM := baseManifest()
+	oldM.Version = "0.4.1"
+	newM := clone(t, oldM)
+	newM.Version = "0.5.0"
+	delete(newM.Services, "dns")
+
+	report := Compare(oldM, newM)
+
+	assert.Equal(t, BumpMinor, report.RequiredBump)
+	assert.Equal(t, "0.5.0", report.SuggestedVersion)
+	assert.True(t, report.VersionOK)
+}
+
+func TestCompare_NonSemverVersion(t *testing.T) {
+	oldM := baseManifest()
+	oldM.Version = "dev"
+	newM := clone(t, oldM)
+	delete(newM.Services, "dns")
+
+	report := Compare(oldM, newM)
+
+	assert.Equal(t, BumpMajor, report.RequiredBump)
+	assert.Empty(t, report.SuggestedVersion)
+	assert.False(t, report.VersionOK)
+}
+
+func TestCompareFile(t *testing.T) {
+	oldM := baseManifest()
+	data, err := json.Marshal(oldM)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	newM := clone(t, oldM)
+	newM.Version = "2.0.0"
+	delete(newM.Services, "dns")
+
+	report, err := CompareFile(path, newM)
+	require.NoError(t, err)
+	assert.True(t, report.HasBreaking())
+	assert.True(t, report.VersionOK)
+
+	_, err = CompareFile(filepath.Join(t.TempDir(), "missing.json"), newM)
+	assert.Error(t, err)
+}
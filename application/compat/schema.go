@@ -0,0 +1,293 @@
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// direction says who produces documents matching a schema, which decides
+// whether a change can break existing users.
+type direction int
+
+const (
+	// inputDirection schemas describe documents the host sends to the plugin
+	// (config, operation input): tightening them breaks existing configs.
+	inputDirection direction = iota
+	// outputDirection schemas describe documents the plugin returns: removing
+	// or retyping fields breaks consumers.
+	outputDirection
+)
+
+// schemaDoc is a parsed JSON Schema with its root kept for $ref resolution.
+type schemaDoc struct {
+	root map[string]any
+}
+
+func parseSchema(raw json.RawMessage) (*schemaDoc, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var root map[string]any
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+	return &schemaDoc{root: root}, nil
+}
+
+// resolve follows local "#/$defs/..." and "#/definitions/..." references.
+func (d *schemaDoc) resolve(node map[string]any) map[string]any {
+	for i := 0; i < 32 && node != nil; i++ {
+		ref, ok := node["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return node
+		}
+		var cur any = d.root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, ok := cur.(map[string]any)
+			if !ok {
+				return node
+			}
+			cur = m[part]
+		}
+		next, ok := cur.(map[string]any)
+		if !ok {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// compareSchemas diffs two JSON Schemas, recursing into properties and items.
+func (d *differ) compareSchemas(path string, oldRaw, newRaw json.RawMessage, dir direction) {
+	oldDoc, errOld := parseSchema(oldRaw)
+	newDoc, errNew := parseSchema(newRaw)
+	if errOld != nil || errNew != nil {
+		if string(oldRaw) != string(newRaw) {
+			d.add(Breaking, path, "schema changed and could not be parsed")
+		}
+		return
+	}
+	switch {
+	case oldDoc == nil && newDoc == nil:
+		return
+	case oldDoc == nil:
+		d.add(Additive, path, "schema added")
+		return
+	case newDoc == nil:
+		d.add(Breaking, path, "schema removed")
+		return
+	}
+
+	sc := &schemaComparer{differ: d, oldDoc: oldDoc, newDoc: newDoc, dir: dir, active: map[string]bool{}}
+	sc.compare(path, oldDoc.root, newDoc.root)
+}
+
+type schemaComparer struct {
+	*differ
+	oldDoc, newDoc *schemaDoc
+	// active holds the resolved node pairs being compared, so a recursive
+	// $ref stops at the pair it started from.
+	active map[string]bool
+	dir    direction
+}
+
+func (sc *schemaComparer) compare(path string, oldNode, newNode map[string]any) {
+	oldNode = sc.oldDoc.resolve(oldNode)
+	newNode = sc.newDoc.resolve(newNode)
+
+	// Guard against recursive $ref cycles. The key leaves out path, which
+	// grows on every step of a cycle.
+	key := fmt.Sprintf("%p|%p", oldNode, newNode)
+	if sc.active[key] {
+		return
+	}
+	sc.active[key] = true
+	defer delete(sc.active, key)
+
+	if oldNode["description"] != newNode["description"] {
+		sc.add(Cosmetic, path, "description changed")
+	}
+
+	oldTypes, newTypes := schemaTypes(oldNode), schemaTypes(newNode)
+	if !reflect.DeepEqual(oldTypes, newTypes) {
+		kind := Breaking
+		// Accepting more input types, or producing fewer output types, is safe.
+		if sc.dir == inputDirection && isSubset(oldTypes, newTypes) && len(oldTypes) > 0 {
+			kind = Additive
+		}
+		if sc.dir == outputDirection && isSubset(newTypes, oldTypes) && len(newTypes) > 0 {
+			kind = Additive
+		}
+		sc.add(kind, path, "type changed from %v to %v", oldTypes, newTypes)
+		if kind == Breaking {
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldNode["default"], newNode["default"]) {
+		sc.add(Additive, path, "default changed from %v to %v", formatValue(oldNode["default"]), formatValue(newNode["default"]))
+	}
+
+	sc.compareEnum(path, oldNode, newNode)
+	sc.compareProperties(path, oldNode, newNode)
+
+	oldItems, _ := oldNode["items"].(map[string]any)
+	newItems, _ := newNode["items"].(map[string]any)
+	if oldItems != nil && newItems != nil {
+		sc.compare(path+"[]", oldItems, newItems)
+	}
+}
+
+func (sc *schemaComparer) compareEnum(path string, oldNode, newNode map[string]any) {
+	oldEnum, hasOld := oldNode["enum"].([]any)
+	newEnum, hasNew := newNode["enum"].([]any)
+	if !hasOld && !hasNew {
+		return
+	}
+
+	removed := enumDifference(oldEnum, newEnum)
+	added := enumDifference(newEnum, oldEnum)
+	// A missing enum means any value is allowed.
+	if !hasNew {
+		removed, added = nil, []string{"any value"}
+	}
+	if !hasOld {
+		removed, added = []string{"any value"}, nil
+	}
+
+	narrowing, widening := Breaking, Additive
+	if sc.dir == outputDirection {
+		narrowing, widening = Additive, Breaking
+	}
+	if len(removed) > 0 {
+		sc.add(narrowing, path, "enum values removed: %s", strings.Join(removed, ", "))
+	}
+	if len(added) > 0 {
+		sc.add(widening, path, "enum values added: %s", strings.Join(added, ", "))
+	}
+}
+
+func (sc *schemaComparer) compareProperties(path string, oldNode, newNode map[string]any) {
+	oldProps, _ := oldNode["properties"].(map[string]any)
+	newProps, _ := newNode["properties"].(map[string]any)
+	oldReq := toSet(stringSlice(oldNode["required"]))
+	newReq := toSet(stringSlice(newNode["required"]))
+
+	names := make(map[string]bool, len(oldProps)+len(newProps))
+	for name := range oldProps {
+		names[name] = true
+	}
+	for name := range newProps {
+		names[name] = true
+	}
+
+	for _, name := range sortedSet(names) {
+		propPath := joinPath(path, name)
+		oldProp, inOld := oldProps[name].(map[string]any)
+		newProp, inNew := newProps[name].(map[string]any)
+
+		switch {
+		case !inNew:
+			// Input documents that still set the field are rejected by
+			// additionalProperties: false; output consumers lose the field.
+			sc.add(Breaking, propPath, "field removed")
+		case !inOld:
+			if sc.dir == inputDirection && newReq[name] {
+				sc.add(Breaking, propPath, "required field added")
+			} else {
+				sc.add(Additive, propPath, "field added")
+			}
+		default:
+			sc.compareRequired(propPath, oldReq[name], newReq[name])
+			sc.compare(propPath, oldProp, newProp)
+		}
+	}
+}
+
+func (sc *schemaComparer) compareRequired(path string, wasRequired, isRequired bool) {
+	switch {
+	case wasRequired == isRequired:
+	case sc.dir == inputDirection && isRequired:
+		sc.add(Breaking, path, "field became required")
+	case sc.dir == inputDirection:
+		sc.add(Additive, path, "field became optional")
+	case isRequired:
+		sc.add(Additive, path, "field is now always present")
+	default:
+		sc.add(Breaking, path, "field may now be omitted")
+	}
+}
+
+func schemaTypes(node map[string]any) []string {
+	switch t := node["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := stringSlice(t)
+		sort.Strings(out)
+		return out
+	}
+	return nil
+}
+
+func stringSlice(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// isSubset reports whether every element of a is in b.
+func isSubset(a, b []string) bool {
+	set := toSet(b)
+	for _, s := range a {
+		if !set[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// enumDifference returns the values in a that are not in b, formatted.
+func enumDifference(a, b []any) []string {
+	var out []string
+	for _, va := range a {
+		found := false
+		for _, vb := range b {
+			if reflect.DeepEqual(va, vb) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, formatValue(va))
+		}
+	}
+	return out
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return "none"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func joinPath(base, name string) string {
+	if base == "" {
+		return name
+	}
+	return base + "." + name
+}
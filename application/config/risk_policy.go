@@ -0,0 +1,38 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseRiskPolicy decodes and validates a risk policy in YAML or JSON.
+// The document is converted to JSON and decoded with the policy's JSON field
+// names. Unknown fields are rejected so typos do not silently disable a rule.
+func ParseRiskPolicy(data []byte) (*entities.RiskPolicy, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("risk policy: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("risk policy: empty document")
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("risk policy: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var policy entities.RiskPolicy
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("risk policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
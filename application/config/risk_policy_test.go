@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlPolicy = `
+rules:
+  - id: fs-shadow
+    kind: fs
+    level: critical
+    match:
+      access: read
+      paths: ["/etc/shadow", "/etc/gshadow"]
+  - id: kv-shared-write
+    kind: kv
+    level: medium
+    score: 5
+    match:
+      ops: [write]
+      keys: ["shared/*"]
+`
+
+func TestParseRiskPolicy(t *testing.T) {
+	policy, err := ParseRiskPolicy([]byte(yamlPolicy))
+	require.NoError(t, err)
+	require.Len(t, policy.Rules, 2)
+	assert.Equal(t, []string{"/etc/shadow", "/etc/gshadow"}, policy.Rules[0].Match.Paths)
+	assert.Equal(t, []string{"write"}, policy.Rules[1].Match.Operations)
+	assert.Equal(t, 5, policy.Rules[1].Score)
+
+	policy, err = ParseRiskPolicy([]byte(`{"rules":[{"id":"env","kind":"env","level":"LOW","match":{"vars":["AWS_*"]}}]}`))
+	require.NoError(t, err, "JSON is accepted")
+	assert.Equal(t, []string{"AWS_*"}, policy.Rules[0].Match.Variables)
+
+	invalid := map[string]string{
+		"missing id":    `rules: [{kind: env, level: low}]`,
+		"duplicate id":  `rules: [{id: a, kind: env, level: low}, {id: a, kind: exec, level: low}]`,
+		"unknown kind":  `rules: [{id: a, kind: disk, level: low}]`,
+		"unknown level": `rules: [{id: a, kind: env, level: severe}]`,
+		"unknown field": `rules: [{id: a, kind: env, level: low, match: {variables: [HOME]}}]`,
+		"bad access":    `rules: [{id: a, kind: fs, level: low, match: {access: execute}}]`,
+		"bad operation": `rules: [{id: a, kind: kv, level: low, match: {ops: [delete]}}]`,
+		"negative":      `rules: [{id: a, kind: env, level: low, score: -1}]`,
+		"empty":         ``,
+		"not yaml":      `rules: [`,
+	}
+	for name, data := range invalid {
+		_, err := ParseRiskPolicy([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
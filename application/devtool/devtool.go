@@ -0,0 +1,467 @@
+// Package devtool runs a plugin natively for development: it prints the
+// manifest, invokes operations, runs registered examples, reports risk and
+// renders documentation without a Reglet host.
+//
+// A plugin exposes the tool through a small native main package:
+//
+//	//go:build !wasip1
+//
+//	package main
+//
+//	func main() {
+//	    devtool.Main(core.Plugin, devtool.WithClient(&plugintest.FakeDNSResolver{...}))
+//	}
+//
+// The reglet-plugin command generates such a main for a plugin package.
+package devtool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/config"
+	"github.com/reglet-dev/reglet-plugin-sdk/application/docs"
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK       = 0
+	ExitFailure  = 1
+	ExitUsageErr = 2
+)
+
+// Tool runs development commands against a plugin definition.
+type Tool struct {
+	plugin   *plugin.PluginDefinition
+	client   any
+	analyzer entities.RiskAnalyzer
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithClient injects the client passed to handlers, typically fakes from the
+// plugintest package so no real host is needed.
+func WithClient(client any) Option {
+	return func(t *Tool) {
+		t.client = client
+	}
+}
+
+// WithRiskAnalyzer overrides the analyzer used by the risk and docs commands
+// when no -policy file is given. Defaults to entities.NewSimpleRiskAnalyzer().
+func WithRiskAnalyzer(a entities.RiskAnalyzer) Option {
+	return func(t *Tool) {
+		t.analyzer = a
+	}
+}
+
+// WithIO overrides the standard streams.
+func WithIO(stdin io.Reader, stdout, stderr io.Writer) Option {
+	return func(t *Tool) {
+		t.stdin, t.stdout, t.stderr = stdin, stdout, stderr
+	}
+}
+
+// New creates a Tool for the plugin.
+func New(p *plugin.PluginDefinition, opts ...Option) *Tool {
+	t := &Tool{
+		plugin:   p,
+		analyzer: entities.NewSimpleRiskAnalyzer(),
+		stdin:    os.Stdin,
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Main runs the tool with os.Args and exits with its status code.
+func Main(p *plugin.PluginDefinition, opts ...Option) {
+	os.Exit(New(p, opts...).Run(context.Background(), os.Args[1:]))
+}
+
+// Run executes one command and returns the process exit code.
+func (t *Tool) Run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		t.usage()
+		return ExitUsageErr
+	}
+
+	var err error
+	switch args[0] {
+	case "manifest":
+		err = t.runManifest(ctx, args[1:])
+	case "invoke":
+		err = t.runInvoke(ctx, args[1:])
+	case "examples":
+		err = t.runExamples(ctx, args[1:])
+	case "risk":
+		err = t.runRisk(ctx, args[1:])
+	case "docs":
+		err = t.runDocs(ctx, args[1:])
+	case "help", "-h", "--help":
+		t.usage()
+		return ExitOK
+	default:
+		fmt.Fprintf(t.stderr, "unknown command %q\n\n", args[0])
+		t.usage()
+		return ExitUsageErr
+	}
+
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(t.stderr, err)
+		return ExitUsageErr
+	case errors.Is(err, errSilent):
+		return ExitFailure
+	default:
+		fmt.Fprintln(t.stderr, "error:", err)
+		return ExitFailure
+	}
+}
+
+func (t *Tool) usage() {
+	fmt.Fprint(t.stderr, `Usage: <plugin-devtool> <command> [flags]
+
+Commands:
+  manifest [-format json|yaml]                      print the plugin manifest
+  invoke [-input FILE] [-set key=value]... SVC/OP   run an operation
+  examples                                          run all registered examples
+  risk [-ops] [-policy FILE]                        report capability risk
+  docs [-format markdown|html] [-o FILE] [-policy FILE]
+                                                    render documentation
+`)
+}
+
+// usageError reports invalid command-line arguments.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string { return e.msg }
+
+// errSilent signals a failure whose details were already printed.
+var errSilent = errors.New("failed")
+
+func (t *Tool) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(t.stderr)
+	return fs
+}
+
+func (t *Tool) runManifest(ctx context.Context, args []string) error {
+	fs := t.newFlagSet("manifest")
+	format := fs.String("format", "json", "output format: json or yaml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	manifest, err := t.plugin.Manifest(ctx)
+	if err != nil {
+		return err
+	}
+	return t.write(*format, manifest)
+}
+
+func (t *Tool) runInvoke(ctx context.Context, args []string) error {
+	fs := t.newFlagSet("invoke")
+	inputFile := fs.String("input", "", "read JSON input from `file` (\"-\" for stdin)")
+	format := fs.String("format", "json", "output format: json or yaml")
+	var sets setFlags
+	fs.Var(&sets, "set", "set an input field as `key=value` (repeatable; values are parsed as JSON when possible)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError{msg: "invoke: expected exactly one SERVICE/OPERATION argument"}
+	}
+	svcName, opName, ok := strings.Cut(fs.Arg(0), "/")
+	if !ok || svcName == "" || opName == "" {
+		return usageError{msg: fmt.Sprintf("invoke: %q is not of the form SERVICE/OPERATION", fs.Arg(0))}
+	}
+
+	input, err := t.buildInput(*inputFile, sets)
+	if err != nil {
+		return err
+	}
+
+	result, err := t.Invoke(ctx, svcName, opName, input)
+	if err != nil {
+		return err
+	}
+	// Print stacks as text rather than base64 inside the result.
+	if result.Error != nil && len(result.Error.Stack) > 0 {
+		fmt.Fprintf(t.stderr, "%s\n", result.Error.Stack)
+		result.Error.Stack = nil
+	}
+	if err := t.write(*format, result); err != nil {
+		return err
+	}
+	if result.Status == entities.ResultStatusError {
+		return errSilent
+	}
+	return nil
+}
+
+// Invoke runs a single operation with the tool's client, through the plugin's
+// middleware chain. Handler errors and panics are converted to error Results.
+func (t *Tool) Invoke(ctx context.Context, svcName, opName string, input json.RawMessage) (*entities.Result, error) {
+	handler, ok := t.plugin.GetHandler(svcName, opName)
+	if !ok {
+		return nil, fmt.Errorf("operation %s/%s is not registered", svcName, opName)
+	}
+
+	cfg, err := t.plugin.Config()
+	if err != nil {
+		return nil, fmt.Errorf("plugin config: %w", err)
+	}
+
+	ctx = plugin.WithOperation(ctx, plugin.OperationInfo{Service: svcName, Operation: opName})
+	handler = plugin.Recover()(handler)
+	result, err := handler(ctx, &plugin.Request{Client: t.client, Config: cfg, Raw: input})
+	if err != nil {
+		res := entities.ResultError(&entities.ErrorDetail{Message: err.Error(), Type: "internal"})
+		return &res, nil
+	}
+	if result == nil {
+		return nil, fmt.Errorf("operation %s/%s returned no result", svcName, opName)
+	}
+	return result, nil
+}
+
+// buildInput merges the input file (if any) with -set overrides.
+func (t *Tool) buildInput(path string, sets setFlags) (json.RawMessage, error) {
+	input := map[string]any{}
+	if path != "" {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(t.stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		if err := decodeJSON(data, &input); err != nil {
+			return nil, fmt.Errorf("failed to parse input %s: %w", path, err)
+		}
+	}
+	for _, kv := range sets {
+		input[kv.key] = kv.value
+	}
+	return json.Marshal(input)
+}
+
+func (t *Tool) runExamples(ctx context.Context, args []string) error {
+	fs := t.newFlagSet("examples")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	results := plugin.RunExamples(ctx, t.plugin, t.client)
+	failed := 0
+	for _, r := range results {
+		name := fmt.Sprintf("%s/%s/%s", r.Service, r.Operation, r.Example)
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(t.stdout, "FAIL %s\n", name)
+			for _, line := range strings.Split(r.Err.Error(), "\n") {
+				fmt.Fprintf(t.stdout, "     %s\n", line)
+			}
+			continue
+		}
+		fmt.Fprintf(t.stdout, "ok   %s\n", name)
+	}
+	fmt.Fprintf(t.stdout, "%d examples, %d failed\n", len(results), failed)
+
+	if failed > 0 {
+		return errSilent
+	}
+	return nil
+}
+
+func (t *Tool) runRisk(ctx context.Context, args []string) error {
+	fs := t.newFlagSet("risk")
+	perOp := fs.Bool("ops", false, "also report risk for each operation")
+	policy := fs.String("policy", "", "score with the risk policy in `file` (JSON or YAML)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	analyzer, err := t.riskAnalyzer(*policy)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := t.plugin.Manifest(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.printRisk(manifest.Name, analyzer.Analyze(&manifest.Capabilities))
+	if *perOp {
+		reports := entities.AnalyzeOperations(analyzer, manifest)
+		for _, key := range sortedKeys(reports) {
+			t.printRisk(key, reports[key])
+		}
+	}
+	return nil
+}
+
+func (t *Tool) runDocs(ctx context.Context, args []string) error {
+	fs := t.newFlagSet("docs")
+	format := fs.String("format", string(docs.FormatMarkdown), "output format: markdown or html")
+	output := fs.String("o", "", "write to `file` instead of stdout")
+	policy := fs.String("policy", "", "annotate risk with the policy in `file` (JSON or YAML)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != string(docs.FormatMarkdown) && *format != string(docs.FormatHTML) {
+		return usageError{msg: fmt.Sprintf("unknown format %q (want markdown or html)", *format)}
+	}
+	analyzer, err := t.riskAnalyzer(*policy)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := t.plugin.Manifest(ctx)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := docs.Render(&buf, docs.Format(*format), manifest, docs.WithRiskAnalyzer(analyzer)); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = t.stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0o644)
+}
+
+// riskAnalyzer returns the analyzer for the policy file at path, or the
+// tool's analyzer when path is empty.
+func (t *Tool) riskAnalyzer(path string) (entities.RiskAnalyzer, error) {
+	if path == "" {
+		return t.analyzer, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := config.ParseRiskPolicy(data)
+	if err != nil {
+		return nil, err
+	}
+	return entities.NewPolicyRiskAnalyzer(policy)
+}
+
+func (t *Tool) printRisk(name string, report entities.RiskReport) {
+	fmt.Fprintf(t.stdout, "%s: %s (score %d)\n", name, report.Level, report.Score)
+	for _, f := range report.RiskFactors {
+		fmt.Fprintf(t.stdout, "  [%s] %s (%s)\n", f.Level, f.Description, f.RuleID)
+	}
+}
+
+// write prints v as indented JSON or YAML.
+func (t *Tool) write(format string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		_, err = fmt.Fprintln(t.stdout, string(data))
+		return err
+	case "yaml":
+		// Round-trip through JSON so field names and embedded schemas match the JSON form.
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = t.stdout.Write(out)
+		return err
+	default:
+		return usageError{msg: fmt.Sprintf("unknown format %q (want json or yaml)", format)}
+	}
+}
+
+// setFlags collects repeated -set key=value flags.
+type setFlags []setFlag
+
+type setFlag struct {
+	value any
+	key   string
+}
+
+func (s *setFlags) String() string { return "" }
+
+func (s *setFlags) Set(raw string) error {
+	key, value, ok := strings.Cut(raw, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", raw)
+	}
+	*s = append(*s, setFlag{key: key, value: parseValue(value)})
+	return nil
+}
+
+// parseValue interprets a -set value as JSON (numbers, booleans, objects),
+// falling back to a plain string.
+func parseValue(s string) any {
+	var v any
+	if err := decodeJSON([]byte(s), &v); err == nil {
+		return v
+	}
+	return s
+}
+
+// decodeJSON decodes a single JSON value into v, keeping numbers as
+// json.Number so large integers reach the plugin unchanged.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
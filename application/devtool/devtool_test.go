@@ -0,0 +1,323 @@
+package devtool_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/devtool"
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ResolveInput struct {
+	Hostname string `json:"hostname"`
+}
+
+type ResolveOutput struct {
+	Addresses []string `json:"addresses"`
+}
+
+type DevDNSService struct {
+	plugin.Service `name:"dns" desc:"DNS lookups"`
+
+	Resolve plugin.Op[ResolveInput, ResolveOutput] `desc:"Resolve a hostname" method:"ResolveHandler"`
+}
+
+func (s *DevDNSService) ResolveHandler(ctx context.Context, in *ResolveInput) (*ResolveOutput, error) {
+	addrs, err := plugin.GetClient[ports.DNSResolver](ctx).LookupHost(ctx, in.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolveOutput{Addresses: addrs}, nil
+}
+
+type CountInput struct {
+	N int64 `json:"n"`
+}
+
+type CountOutput struct {
+	Text string `json:"text"`
+}
+
+type DevCountService struct {
+	plugin.Service `name:"count" desc:"Echoes a counter"`
+
+	Echo plugin.Op[CountInput, CountOutput] `desc:"Echo the counter" method:"EchoHandler"`
+}
+
+func (s *DevCountService) EchoHandler(ctx context.Context, in *CountInput) (*CountOutput, error) {
+	return &CountOutput{Text: strconv.FormatInt(in.N, 10)}, nil
+}
+
+func init() {
+	plugin.RegisterServiceOp[DevCountService, CountInput, CountOutput]("Echo")
+	plugin.RegisterServiceOp[DevDNSService, ResolveInput, ResolveOutput]("Resolve",
+		plugin.Example[ResolveInput, ResolveOutput]{
+			Name:           "basic",
+			Input:          ResolveInput{Hostname: "example.com"},
+			ExpectedOutput: &ResolveOutput{Addresses: []string{"93.184.216.34"}},
+		},
+		plugin.Example[ResolveInput, ResolveOutput]{
+			Name:           "wrong",
+			Input:          ResolveInput{Hostname: "example.com"},
+			ExpectedOutput: &ResolveOutput{Addresses: []string{"10.0.0.1"}},
+		},
+	)
+}
+
+func newTestPlugin(t *testing.T) *plugin.PluginDefinition {
+	t.Helper()
+	p := plugin.DefinePlugin(plugin.PluginDef{
+		Name:    "dev-dns",
+		Version: "1.0.0",
+		Capabilities: entities.GrantSet{
+			Network: &entities.NetworkCapability{
+				Rules: []entities.NetworkRule{{Hosts: []string{"*"}, Ports: []string{"53"}}},
+			},
+		},
+	})
+	require.NoError(t, plugin.RegisterService(p, &DevDNSService{}))
+	return p
+}
+
+func fakeResolver() *plugintest.FakeDNSResolver {
+	return &plugintest.FakeDNSResolver{
+		Hosts: map[string][]string{"example.com": {"93.184.216.34"}},
+	}
+}
+
+func run(t *testing.T, p *plugin.PluginDefinition, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	tool := devtool.New(p,
+		devtool.WithClient(fakeResolver()),
+		devtool.WithIO(strings.NewReader(stdin), &stdout, &stderr),
+	)
+	code := tool.Run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestManifest(t *testing.T) {
+	p := newTestPlugin(t)
+
+	code, out, _ := run(t, p, "", "manifest")
+	require.Equal(t, devtool.ExitOK, code)
+	var m entities.Manifest
+	require.NoError(t, json.Unmarshal([]byte(out), &m))
+	assert.Equal(t, "dev-dns", m.Name)
+	assert.Contains(t, m.Services, "dns")
+
+	code, out, _ = run(t, p, "", "manifest", "-format", "yaml")
+	require.Equal(t, devtool.ExitOK, code)
+	assert.Contains(t, out, "name: dev-dns")
+	assert.Contains(t, out, "sdk_version:")
+
+	code, _, stderr := run(t, p, "", "manifest", "-format", "toml")
+	assert.Equal(t, devtool.ExitUsageErr, code)
+	assert.Contains(t, stderr, "unknown format")
+}
+
+func TestInvoke(t *testing.T) {
+	p := newTestPlugin(t)
+
+	t.Run("set flag", func(t *testing.T) {
+		code, out, _ := run(t, p, "", "invoke", "-set", "hostname=example.com", "dns/resolve")
+		require.Equal(t, devtool.ExitOK, code)
+		var result entities.Result
+		require.NoError(t, json.Unmarshal([]byte(out), &result))
+		assert.Equal(t, entities.ResultStatusSuccess, result.Status)
+		assert.Equal(t, []any{"93.184.216.34"}, result.Data["addresses"])
+	})
+
+	t.Run("input file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "input.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"hostname":"example.com"}`), 0o600))
+		code, out, _ := run(t, p, "", "invoke", "-input", path, "dns/resolve")
+		require.Equal(t, devtool.ExitOK, code)
+		assert.Contains(t, out, "93.184.216.34")
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		code, out, _ := run(t, p, `{"hostname":"example.com"}`, "invoke", "-input", "-", "dns/resolve")
+		require.Equal(t, devtool.ExitOK, code)
+		assert.Contains(t, out, "93.184.216.34")
+	})
+
+	t.Run("large integers keep precision", func(t *testing.T) {
+		p := plugin.DefinePlugin(plugin.PluginDef{Name: "dev-count", Version: "1.0.0"})
+		require.NoError(t, plugin.RegisterService(p, &DevCountService{}))
+
+		for _, args := range [][]string{
+			{"invoke", "-input", "-", "count/echo"},
+			{"invoke", "-set", "n=9007199254740993", "count/echo"},
+		} {
+			code, out, _ := run(t, p, `{"n":9007199254740993}`, args...)
+			require.Equal(t, devtool.ExitOK, code, out)
+			assert.Contains(t, out, `"text": "9007199254740993"`)
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		code, out, _ := run(t, p, "", "invoke", "-set", "hostname=unknown.test", "dns/resolve")
+		assert.Equal(t, devtool.ExitFailure, code)
+		assert.Contains(t, out, `"status": "error"`)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		code, _, stderr := run(t, p, "", "invoke", "dns/missing")
+		assert.Equal(t, devtool.ExitFailure, code)
+		assert.Contains(t, stderr, "dns/missing is not registered")
+	})
+
+	t.Run("bad target", func(t *testing.T) {
+		code, _, _ := run(t, p, "", "invoke", "resolve")
+		assert.Equal(t, devtool.ExitUsageErr, code)
+	})
+}
+
+func TestInvoke_RecoversPanics(t *testing.T) {
+	p := newTestPlugin(t)
+	tool := devtool.New(p) // no client: GetClient panics
+
+	result, err := tool.Invoke(context.Background(), "dns", "resolve", json.RawMessage(`{"hostname":"example.com"}`))
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResultStatusError, result.Status)
+	assert.Contains(t, result.Error.Message, "dns/resolve panicked")
+}
+
+func TestExamples(t *testing.T) {
+	p := newTestPlugin(t)
+
+	code, out, _ := run(t, p, "", "examples")
+	assert.Equal(t, devtool.ExitFailure, code)
+	assert.Contains(t, out, "ok   dns/resolve/basic")
+	assert.Contains(t, out, "FAIL dns/resolve/wrong")
+	assert.Contains(t, out, "2 examples, 1 failed")
+}
+
+func TestRisk(t *testing.T) {
+	p := newTestPlugin(t)
+
+	code, out, _ := run(t, p, "", "risk")
+	require.Equal(t, devtool.ExitOK, code)
+	assert.Contains(t, out, "dev-dns: CRITICAL")
+	assert.Contains(t, out, "Unrestricted network access")
+}
+
+func TestRisk_Policy(t *testing.T) {
+	p := newTestPlugin(t)
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - id: dns-any
+    kind: network
+    level: low
+    description: DNS queries
+    match:
+      ports: ["53"]
+`), 0o600))
+
+	code, out, _ := run(t, p, "", "risk", "-policy", path)
+	require.Equal(t, devtool.ExitOK, code)
+	assert.Contains(t, out, "dev-dns: LOW (score 1)")
+	assert.Contains(t, out, "[LOW] DNS queries (dns-any)")
+
+	require.NoError(t, os.WriteFile(path, []byte(`rules: [{id: x, kind: disk, level: low}]`), 0o600))
+	code, _, stderr := run(t, p, "", "risk", "-policy", path)
+	assert.Equal(t, devtool.ExitFailure, code)
+	assert.Contains(t, stderr, `unknown kind "disk"`)
+}
+
+func TestDocs(t *testing.T) {
+	p := newTestPlugin(t)
+
+	code, out, _ := run(t, p, "", "docs")
+	require.Equal(t, devtool.ExitOK, code)
+	assert.Contains(t, out, "# dev-dns")
+	assert.Contains(t, out, "### dns/resolve")
+
+	path := filepath.Join(t.TempDir(), "PLUGIN.html")
+	code, _, _ = run(t, p, "", "docs", "-format", "html", "-o", path)
+	require.Equal(t, devtool.ExitOK, code)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<title>dev-dns</title>")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, stderr := run(t, newTestPlugin(t), "", "bogus")
+	assert.Equal(t, devtool.ExitUsageErr, code)
+	assert.Contains(t, stderr, `unknown command "bogus"`)
+}
+
+func TestRunExamples_ReportsPanics(t *testing.T) {
+	results := plugin.RunExamples(context.Background(), newTestPlugin(t), nil)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		require.Error(t, r.Err)
+		assert.Contains(t, r.Err.Error(), "panicked")
+	}
+}
+
+type greetConfig struct {
+	Greeting string `json:"greeting,omitempty" jsonschema:"default=hello"`
+}
+
+type GreetInput struct {
+	Name string `json:"name"`
+}
+
+type GreetOutput struct {
+	Message string `json:"message"`
+}
+
+type GreetService struct {
+	plugin.Service `name:"greet" desc:"Greetings"`
+
+	Greet plugin.Op[GreetInput, GreetOutput] `desc:"Greet someone" method:"GreetHandler"`
+}
+
+func (s *GreetService) GreetHandler(ctx context.Context, in *GreetInput) (*GreetOutput, error) {
+	cfg := plugin.GetConfig[*greetConfig](ctx)
+	return &GreetOutput{Message: cfg.Greeting + ", " + in.Name}, nil
+}
+
+func TestInvokeAndExamples_InjectConfig(t *testing.T) {
+	plugin.RegisterServiceOp[GreetService, GreetInput, GreetOutput]("Greet",
+		plugin.Example[GreetInput, GreetOutput]{
+			Name:           "default greeting",
+			Input:          GreetInput{Name: "ada"},
+			ExpectedOutput: &GreetOutput{Message: "hello, ada"},
+		},
+	)
+	p := plugin.DefinePlugin(plugin.PluginDef{Name: "greeter", Version: "1.0.0", Config: &greetConfig{}})
+	require.NoError(t, plugin.RegisterService(p, &GreetService{}))
+
+	tool := devtool.New(p)
+
+	// Before _init handlers get the zero config rather than none.
+	result, err := tool.Invoke(context.Background(), "greet", "greet", json.RawMessage(`{"name":"ada"}`))
+	require.NoError(t, err)
+	require.Equal(t, entities.ResultStatusSuccess, result.Status, result.Error)
+	assert.Equal(t, ", ada", result.Data["message"])
+
+	require.NoError(t, p.Init(context.Background(), []byte(`{}`)))
+	result, err = tool.Invoke(context.Background(), "greet", "greet", json.RawMessage(`{"name":"ada"}`))
+	require.NoError(t, err)
+	require.Equal(t, entities.ResultStatusSuccess, result.Status, result.Error)
+	assert.Equal(t, "hello, ada", result.Data["message"], "schema defaults apply after _init")
+
+	examples := plugin.RunExamples(context.Background(), p, nil)
+	require.Len(t, examples, 1)
+	assert.NoError(t, examples[0].Err)
+}
@@ -0,0 +1,128 @@
+// Package docs renders plugin documentation from a manifest.
+//
+// The output covers the operations table, input and output field tables
+// derived from the JSON Schemas, examples and the declared capabilities
+// annotated with risk levels. To keep a plugin's docs in sync, add a
+// go generate directive next to the plugin definition:
+//
+//	//go:generate go run github.com/reglet-dev/reglet-plugin-sdk/cmd/reglet-plugin docs -pkg . -o PLUGIN.md
+package docs
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Format selects the documentation output format.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+type options struct {
+	analyzer entities.RiskAnalyzer
+	title    string
+}
+
+// Option configures documentation rendering.
+type Option func(*options)
+
+// WithRiskAnalyzer sets the analyzer used to annotate capabilities.
+// Defaults to entities.NewSimpleRiskAnalyzer().
+func WithRiskAnalyzer(a entities.RiskAnalyzer) Option {
+	return func(o *options) {
+		o.analyzer = a
+	}
+}
+
+// WithTitle overrides the document title, which defaults to the plugin name.
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+var (
+	markdownTemplate = texttemplate.Must(texttemplate.New("plugin.md.tmpl").
+				Funcs(texttemplate.FuncMap{"cell": markdownCell}).
+				ParseFS(templateFS, "templates/plugin.md.tmpl"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("plugin.html.tmpl").
+			Funcs(htmltemplate.FuncMap{"lower": strings.ToLower}).
+			ParseFS(templateFS, "templates/plugin.html.tmpl"))
+)
+
+// Render writes documentation for the manifest in the given format.
+func Render(w io.Writer, format Format, m *entities.Manifest, opts ...Option) error {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(w, m, opts...)
+	case FormatHTML:
+		return HTML(w, m, opts...)
+	default:
+		return fmt.Errorf("unknown documentation format %q", format)
+	}
+}
+
+// Markdown writes the manifest as a Markdown document.
+func Markdown(w io.Writer, m *entities.Manifest, opts ...Option) error {
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, buildPage(m, newOptions(opts))); err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	_, err := w.Write(collapseBlankLines(buf.Bytes()))
+	return err
+}
+
+// HTML writes the manifest as a standalone HTML page.
+func HTML(w io.Writer, m *entities.Manifest, opts ...Option) error {
+	if err := htmlTemplate.Execute(w, buildPage(m, newOptions(opts))); err != nil {
+		return fmt.Errorf("failed to render html: %w", err)
+	}
+	return nil
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{analyzer: entities.NewSimpleRiskAnalyzer()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// markdownCell escapes a value for use inside a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// collapseBlankLines removes runs of blank lines left by template conditionals.
+func collapseBlankLines(b []byte) []byte {
+	lines := strings.Split(string(b), "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	inCode := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+		}
+		isBlank := strings.TrimSpace(line) == ""
+		if isBlank && blank && !inCode {
+			continue
+		}
+		blank = isBlank
+		out = append(out, line)
+	}
+	return []byte(strings.TrimSpace(strings.Join(out, "\n")) + "\n")
+}
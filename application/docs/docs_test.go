@@ -0,0 +1,135 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testManifest() *entities.Manifest {
+	return &entities.Manifest{
+		Name:        "dns",
+		Version:     "1.2.0",
+		Description: "DNS checks",
+		SDKVersion:  "0.1.0",
+		ConfigSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"hostname": {"type": "string", "description": "Name to resolve"},
+				"record_type": {"type": "string", "enum": ["A", "AAAA"], "default": "A"},
+				"servers": {"type": "array", "items": {"$ref": "#/$defs/Server"}}
+			},
+			"required": ["hostname"],
+			"$defs": {"Server": {"type": "object", "properties": {"port": {"type": "integer", "description": "Port | number"}}}}
+		}`),
+		Capabilities: entities.GrantSet{
+			Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{"*"}, Ports: []string{"53"}}}},
+		},
+		Services: map[string]entities.ServiceManifest{
+			"dns": {
+				Name:        "dns",
+				Description: "DNS lookups",
+				Operations: []entities.OperationManifest{
+					{
+						Name:         "resolve",
+						Description:  "Resolve a hostname",
+						InputFields:  []string{"hostname", "record_type"},
+						OutputSchema: json.RawMessage(`{"type":"object","properties":{"addresses":{"type":"array","items":{"type":"string"}}},"required":["addresses"]}`),
+						Examples: []entities.OperationExample{
+							{
+								Name:           "basic",
+								Description:    "Resolve example.com",
+								Input:          json.RawMessage(`{"hostname":"example.com"}`),
+								ExpectedOutput: json.RawMessage(`{"addresses":["93.184.216.34"]}`),
+							},
+							{Name: "invalid", Input: json.RawMessage(`{"hostname":""}`), ExpectedError: "hostname is required"},
+						},
+						Capabilities: &entities.GrantSet{
+							Exec: &entities.ExecCapability{Commands: []string{"dig"}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, testManifest()))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "# dns\n"))
+	assert.Contains(t, out, "| Version | `1.2.0` |")
+	assert.Contains(t, out, "| dns | [`resolve`](#dns-resolve) | Resolve a hostname | CRITICAL |")
+	// Config and input tables come from the config schema.
+	assert.Contains(t, out, "| `hostname` | string | yes |  | Name to resolve |")
+	assert.Contains(t, out, "| `record_type` | string | no | `\"A\"` | One of: \"A\", \"AAAA\" |")
+	assert.Contains(t, out, "| `servers` | object[] | no |  |  |")
+	assert.Contains(t, out, "| `servers[].port` | integer | no |  | Port \\| number |")
+	// Output table from the output schema.
+	assert.Contains(t, out, "| `addresses` | string[] | yes |  |  |")
+	// Examples.
+	assert.Contains(t, out, "##### basic")
+	assert.Contains(t, out, "```json\n{\n  \"hostname\": \"example.com\"\n}\n```")
+	assert.Contains(t, out, "Expected error: `hostname is required`")
+	// Capabilities with risk.
+	assert.Contains(t, out, "#### Capabilities (risk: CRITICAL)")
+	assert.Contains(t, out, "| exec | dig |")
+	assert.Contains(t, out, "Overall risk: **CRITICAL**")
+	assert.Contains(t, out, "| network | hosts *, ports 53 |")
+	assert.Contains(t, out, "| CRITICAL | Unrestricted network access |")
+	assert.NotContains(t, out, "\n\n\n")
+}
+
+func TestMarkdown_InputSchema(t *testing.T) {
+	m := testManifest()
+	m.Services["dns"].Operations[0].InputSchema = json.RawMessage(`{"type":"object","properties":{"name":{"type":"string","description":"Host to resolve"},"timeout":{"type":"integer","default":5}},"required":["name"]}`)
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, m))
+	out := buf.String()
+
+	input := out[strings.Index(out, "#### Input"):strings.Index(out, "#### Output")]
+	assert.Contains(t, input, "| `name` | string | yes |  | Host to resolve |")
+	assert.Contains(t, input, "| `timeout` | integer | no | `5` |  |")
+	assert.NotContains(t, input, "record_type")
+}
+
+func TestMarkdown_NoCapabilities(t *testing.T) {
+	m := testManifest()
+	m.Capabilities = entities.GrantSet{}
+	m.Services = nil
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, m, WithTitle("DNS Plugin")))
+
+	assert.Contains(t, buf.String(), "# DNS Plugin\n")
+	assert.Contains(t, buf.String(), "This plugin requests no capabilities.")
+}
+
+func TestHTML(t *testing.T) {
+	m := testManifest()
+	m.Description = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	require.NoError(t, HTML(&buf, m))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>dns</title>")
+	assert.Contains(t, out, `<h3 id="dns-resolve">dns/resolve</h3>`)
+	assert.Contains(t, out, `<td class="risk risk-critical">CRITICAL</td>`)
+	assert.Contains(t, out, "<code>servers[].port</code>")
+	assert.Contains(t, out, "&lt;script&gt;")
+	assert.NotContains(t, out, "<script>")
+}
+
+func TestRender_UnknownFormat(t *testing.T) {
+	err := Render(&bytes.Buffer{}, "pdf", testManifest())
+	assert.ErrorContains(t, err, `unknown documentation format "pdf"`)
+}
@@ -0,0 +1,216 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+)
+
+// page is the render-ready view of a manifest shared by all formats.
+type page struct {
+	Title        string
+	Name         string
+	Version      string
+	Description  string
+	SDKVersion   string
+	MinHost      string
+	Config       []field
+	Services     []service
+	Risk         riskView
+	Capabilities []capability
+}
+
+type service struct {
+	Name        string
+	Description string
+	Operations  []operation
+}
+
+type operation struct {
+	Name         string
+	Anchor       string
+	Description  string
+	Input        []field
+	Output       []field
+	Examples     []example
+	Capabilities []capability
+	Risk         riskView
+}
+
+// field is one row of a field table.
+type field struct {
+	Name        string
+	Type        string
+	Default     string
+	Description string
+	Required    bool
+}
+
+type example struct {
+	Name           string
+	Description    string
+	Input          string
+	ExpectedOutput string
+	ExpectedError  string
+}
+
+// capability is one declared grant rendered as kind and rule.
+type capability struct {
+	Kind string
+	Rule string
+}
+
+type riskView struct {
+	Level   string
+	Factors []entities.RiskFactor
+}
+
+func buildPage(m *entities.Manifest, o *options) page {
+	p := page{
+		Title:        o.title,
+		Name:         m.Name,
+		Version:      m.Version,
+		Description:  m.Description,
+		SDKVersion:   m.SDKVersion,
+		MinHost:      m.MinHostVersion,
+		Config:       schemaFields(m.ConfigSchema),
+		Risk:         analyze(o.analyzer, &m.Capabilities),
+		Capabilities: capabilities(&m.Capabilities),
+	}
+	if p.Title == "" {
+		p.Title = m.Name
+	}
+
+	configByName := make(map[string]field, len(p.Config))
+	for _, f := range p.Config {
+		configByName[f.Name] = f
+	}
+
+	names := make([]string, 0, len(m.Services))
+	for name := range m.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		svc := m.Services[name]
+		sv := service{Name: name, Description: svc.Description}
+		for _, op := range svc.Operations {
+			ov := operation{
+				Name:        op.Name,
+				Anchor:      anchor(name + "-" + op.Name),
+				Description: op.Description,
+				Input:       inputFields(op, configByName),
+				Output:      schemaFields(op.OutputSchema),
+				Examples:    examples(op.Examples),
+			}
+			if op.Capabilities != nil && !op.Capabilities.IsEmpty() {
+				ov.Capabilities = capabilities(op.Capabilities)
+				ov.Risk = analyze(o.analyzer, op.Capabilities)
+			}
+			sv.Operations = append(sv.Operations, ov)
+		}
+		p.Services = append(p.Services, sv)
+	}
+	return p
+}
+
+// inputFields describes an operation's input from its input schema, falling
+// back to the config schema entries for its input fields.
+func inputFields(op entities.OperationManifest, config map[string]field) []field {
+	if len(op.InputSchema) > 0 {
+		return schemaFields(op.InputSchema)
+	}
+	var out []field
+	for _, name := range op.InputFields {
+		if f, ok := config[name]; ok {
+			out = append(out, f)
+			continue
+		}
+		out = append(out, field{Name: name})
+	}
+	return out
+}
+
+func analyze(a entities.RiskAnalyzer, grants *entities.GrantSet) riskView {
+	report := a.Analyze(grants)
+	return riskView{Level: report.Level.String(), Factors: report.RiskFactors}
+}
+
+func capabilities(g *entities.GrantSet) []capability {
+	var out []capability
+	if g.Network != nil {
+		for _, r := range g.Network.Rules {
+			out = append(out, capability{Kind: "network", Rule: fmt.Sprintf("hosts %s, ports %s", list(r.Hosts), list(r.Ports))})
+		}
+	}
+	if g.FS != nil {
+		for _, r := range g.FS.Rules {
+			if len(r.Read) > 0 {
+				out = append(out, capability{Kind: "fs", Rule: "read " + list(r.Read)})
+			}
+			if len(r.Write) > 0 {
+				out = append(out, capability{Kind: "fs", Rule: "write " + list(r.Write)})
+			}
+		}
+	}
+	if g.Env != nil && len(g.Env.Variables) > 0 {
+		out = append(out, capability{Kind: "env", Rule: list(g.Env.Variables)})
+	}
+	if g.Exec != nil && len(g.Exec.Commands) > 0 {
+		out = append(out, capability{Kind: "exec", Rule: list(g.Exec.Commands)})
+	}
+	if g.KV != nil {
+		for _, r := range g.KV.Rules {
+			out = append(out, capability{Kind: "kv", Rule: r.Operation + " " + list(r.Keys)})
+		}
+	}
+	return out
+}
+
+func list(items []string) string {
+	return strings.Join(items, ", ")
+}
+
+func examples(exs []entities.OperationExample) []example {
+	out := make([]example, 0, len(exs))
+	for _, ex := range exs {
+		out = append(out, example{
+			Name:           ex.Name,
+			Description:    ex.Description,
+			Input:          prettyJSON(ex.Input),
+			ExpectedOutput: prettyJSON(ex.ExpectedOutput),
+			ExpectedError:  ex.ExpectedError,
+		})
+	}
+	return out
+}
+
+func prettyJSON(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// anchor turns a heading into a GitHub-style fragment identifier.
+func anchor(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ' || r == '/':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaFields flattens a JSON Schema into field rows. Nested objects and
+// arrays of objects are expanded with dotted paths ("servers[].port").
+func schemaFields(raw json.RawMessage) []field {
+	if len(raw) == 0 {
+		return nil
+	}
+	var root map[string]any
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil
+	}
+	w := &schemaWalker{root: root}
+	w.walk("", w.resolve(root), 0)
+	return w.fields
+}
+
+type schemaWalker struct {
+	root   map[string]any
+	fields []field
+}
+
+// maxDepth bounds expansion of recursive schemas.
+const maxDepth = 8
+
+func (w *schemaWalker) walk(prefix string, node map[string]any, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	props, _ := node["properties"].(map[string]any)
+	required := map[string]bool{}
+	if req, ok := node["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propNode, _ := props[name].(map[string]any)
+		prop := w.resolve(propNode)
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		w.fields = append(w.fields, field{
+			Name:        path,
+			Type:        w.typeName(prop),
+			Required:    required[name],
+			Default:     formatDefault(prop["default"]),
+			Description: describe(prop),
+		})
+
+		if _, ok := prop["properties"]; ok {
+			w.walk(path, prop, depth+1)
+		}
+		if items, ok := prop["items"].(map[string]any); ok {
+			if items = w.resolve(items); items["properties"] != nil {
+				w.walk(path+"[]", items, depth+1)
+			}
+		}
+	}
+}
+
+// resolve follows local "#/..." references.
+func (w *schemaWalker) resolve(node map[string]any) map[string]any {
+	for i := 0; i < maxDepth && node != nil; i++ {
+		ref, ok := node["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			break
+		}
+		var cur any = w.root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := cur.(map[string]any)
+			cur = m[part]
+		}
+		next, ok := cur.(map[string]any)
+		if !ok {
+			break
+		}
+		node = next
+	}
+	return node
+}
+
+func (w *schemaWalker) typeName(node map[string]any) string {
+	var t string
+	switch v := node["type"].(type) {
+	case string:
+		t = v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, p := range v {
+			parts = append(parts, fmt.Sprint(p))
+		}
+		t = strings.Join(parts, " | ")
+	}
+	if t == "array" {
+		if items, ok := node["items"].(map[string]any); ok {
+			if inner := w.typeName(w.resolve(items)); inner != "" {
+				return inner + "[]"
+			}
+		}
+	}
+	if format, ok := node["format"].(string); ok && t != "" {
+		t += " (" + format + ")"
+	}
+	return t
+}
+
+// describe combines the description with enum constraints.
+func describe(node map[string]any) string {
+	desc, _ := node["description"].(string)
+	if enum, ok := node["enum"].([]any); ok && len(enum) > 0 {
+		values := make([]string, 0, len(enum))
+		for _, v := range enum {
+			values = append(values, formatDefault(v))
+		}
+		oneOf := "One of: " + strings.Join(values, ", ")
+		if desc == "" {
+			return oneOf
+		}
+		return strings.TrimSuffix(desc, ".") + ". " + oneOf
+	}
+	return desc
+}
+
+func formatDefault(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -16,6 +16,20 @@ type Plugin interface {
 	Check(ctx context.Context, config []byte) (*entities.Result, error)
 }
 
+// Initializer is implemented by plugins that need one-time setup, such as
+// parsing configuration, building API clients or warming caches.
+// The host calls it through the _init export before any _observe call.
+type Initializer interface {
+	// Init receives the plugin-level configuration as JSON.
+	Init(ctx context.Context, config []byte) error
+}
+
+// Shutdowner is implemented by plugins that need to release resources
+// when the host unloads them. The host calls it through the _shutdown export.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 // Internal variable to hold the user's plugin implementation.
 var userPlugin Plugin
 
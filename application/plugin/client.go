@@ -1,6 +1,10 @@
 package plugin
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
 
 // clientKey is the context key for storing the plugin client.
 type clientKey struct{}
@@ -44,3 +48,86 @@ func TryGetClient[T any](ctx context.Context) (T, bool) {
 	client, ok := v.(T)
 	return client, ok
 }
+
+// configKey is the context key for storing the decoded plugin config.
+type configKey struct{}
+
+// WithConfig returns a new context with the plugin config attached.
+// Called by the SDK wrapper before invoking typed handlers.
+func WithConfig(ctx context.Context, config any) context.Context {
+	return context.WithValue(ctx, configKey{}, config)
+}
+
+// GetConfig extracts the decoded plugin-level config from the context.
+// T is the pointer type of PluginDef.Config (e.g. *HTTPConfig).
+// Panics if the config is not present or is the wrong type.
+//
+// Example:
+//
+//	func (s *HTTPService) CheckHandler(ctx context.Context, in *CheckInput) (*CheckOutput, error) {
+//	    cfg := plugin.GetConfig[*HTTPConfig](ctx)
+//	    // ...
+//	}
+func GetConfig[T any](ctx context.Context) T {
+	v := ctx.Value(configKey{})
+	if v == nil {
+		panic("plugin: no config in context - ensure PluginDef.Config is set and the plugin was initialized")
+	}
+	config, ok := v.(T)
+	if !ok {
+		panic("plugin: config type mismatch")
+	}
+	return config
+}
+
+// TryGetConfig extracts the decoded plugin config from the context.
+// Returns the zero value and false if not present or wrong type.
+func TryGetConfig[T any](ctx context.Context) (T, bool) {
+	var zero T
+	v := ctx.Value(configKey{})
+	if v == nil {
+		return zero, false
+	}
+	config, ok := v.(T)
+	return config, ok
+}
+
+// ClientFactory builds the client injected into handlers from the decoded
+// plugin config. Create one with NewClientFactory.
+type ClientFactory interface {
+	// configType is the struct type the factory expects its config as.
+	configType() reflect.Type
+	newClient(ctx context.Context, config any) (any, error)
+}
+
+type clientFactory[C, T any] func(ctx context.Context, cfg *C) (T, error)
+
+func (f clientFactory[C, T]) configType() reflect.Type {
+	return reflect.TypeFor[C]()
+}
+
+func (f clientFactory[C, T]) newClient(ctx context.Context, config any) (any, error) {
+	cfg, ok := config.(*C)
+	if !ok {
+		return nil, fmt.Errorf("plugin: client factory expects *%s, got %T", reflect.TypeFor[C](), config)
+	}
+	return f(ctx, cfg)
+}
+
+// NewClientFactory wraps a typed constructor as a PluginDef.Client factory.
+// The SDK validates the plugin config against ConfigSchema, decodes it into
+// *C and passes it to fn; the returned client is available to handlers via
+// GetClient[T] and the config via GetConfig[*C].
+//
+// Example:
+//
+//	var Plugin = plugin.DefinePlugin(plugin.PluginDef{
+//	    Name: "aws",
+//	    Config: &AWSConfig{},
+//	    Client: plugin.NewClientFactory(func(ctx context.Context, cfg *AWSConfig) (*AWSClient, error) {
+//	        return NewAWSClient(cfg.Region)
+//	    }),
+//	})
+func NewClientFactory[C, T any](fn func(ctx context.Context, cfg *C) (T, error)) ClientFactory {
+	return clientFactory[C, T](fn)
+}
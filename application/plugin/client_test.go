@@ -46,3 +46,45 @@ func TestTryGetClient(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "test", got.Value)
 }
+
+func TestGetConfig(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Panics(t, func() {
+		GetConfig[*testConfig](ctx)
+	})
+
+	ctx = WithConfig(ctx, &testConfig{Name: "cfg"})
+	assert.Equal(t, "cfg", GetConfig[*testConfig](ctx).Name)
+
+	assert.Panics(t, func() {
+		GetConfig[*mockClient](ctx)
+	}, "wrong type")
+}
+
+func TestTryGetConfig(t *testing.T) {
+	ctx := context.Background()
+
+	got, ok := TryGetConfig[*testConfig](ctx)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+
+	ctx = WithConfig(ctx, &testConfig{Name: "cfg"})
+	got, ok = TryGetConfig[*testConfig](ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "cfg", got.Name)
+}
+
+func TestDefinePlugin_ClientFactoryConfigMismatch(t *testing.T) {
+	factory := NewClientFactory(func(ctx context.Context, cfg *lifecycleConfig) (*mockClient, error) {
+		return &mockClient{}, nil
+	})
+
+	assert.Panics(t, func() {
+		DefinePlugin(PluginDef{Name: "mismatch", Config: &testConfig{}, Client: factory})
+	})
+
+	def := DefinePlugin(PluginDef{Name: "inferred", Client: factory})
+	assert.IsType(t, &lifecycleConfig{}, def.def.Config, "config defaults to the factory's config type")
+	assert.NotEmpty(t, def.configSchema)
+}
@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"context"
+
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
+)
+
+// RequestID returns the host's request ID for the current invocation, or ""
+// if the host did not send one.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(wasmcontext.RequestIDKey).(string)
+	return id
+}
+
+// CheckCanceled asks the host whether the current invocation has been
+// canceled and returns ctx.Err() if so. Once the host reports cancellation,
+// ctx.Done() is closed and host calls made with ctx carry the cancellation.
+// Call it periodically in long-running loops.
+//
+// Example:
+//
+//	for _, target := range in.Targets {
+//	    if err := plugin.CheckCanceled(ctx); err != nil {
+//	        return nil, err
+//	    }
+//	    // ...
+//	}
+func CheckCanceled(ctx context.Context) error {
+	return wasmcontext.PollCancellation(ctx)
+}
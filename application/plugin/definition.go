@@ -1,13 +1,29 @@
 package plugin
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/application/schema"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
+)
+
+// Compile-time interface compliance check
+var (
+	_ Plugin      = (*PluginDefinition)(nil)
+	_ Initializer = (*PluginDefinition)(nil)
+	_ Shutdowner  = (*PluginDefinition)(nil)
 )
 
 // PluginDef defines plugin identity and configuration.
@@ -17,14 +33,38 @@ type PluginDef struct {
 	Description  string
 	Config       interface{} // Struct for schema generation
 	Capabilities entities.GrantSet
+
+	// Init is called once when the host initializes the plugin, with the
+	// plugin-level config after it has been validated against ConfigSchema
+	// and had schema defaults applied. Optional.
+	Init func(ctx context.Context, config json.RawMessage) error
+
+	// Shutdown is called once when the host unloads the plugin. Optional.
+	Shutdown func(ctx context.Context) error
+
+	// Client builds the client injected into handlers from the decoded
+	// config. Optional; create it with NewClientFactory. If Config is nil it
+	// defaults to the factory's config type.
+	Client ClientFactory
+
+	// ClientPerInvocation builds a new client for every invocation instead of
+	// once at Init. Per-invocation clients implementing io.Closer are closed
+	// after the handler returns.
+	ClientPerInvocation bool
 }
 
 // PluginDefinition holds the parsed plugin definition and registered services.
 type PluginDefinition struct {
-	services     map[string]*serviceEntry
-	def          PluginDef
-	configSchema json.RawMessage
-	mu           sync.RWMutex
+	services          map[string]*serviceEntry
+	serviceMiddleware map[string][]Middleware
+	def               PluginDef
+	configSchema      json.RawMessage
+	config            json.RawMessage // validated config from Init
+	configValue       any             // config decoded into PluginDef.Config's type
+	client            any             // shared client built by PluginDef.Client
+	middleware        []Middleware
+	mu                sync.RWMutex
+	initialized       bool // config applied by Init
 }
 
 // serviceEntry holds a registered service.
@@ -40,14 +80,32 @@ type operationEntry struct {
 	name        string
 	description string
 	// NEW: Type info for schema generation
-	inputType  reflect.Type
-	outputType reflect.Type
-	examples   []any
+	inputType    reflect.Type
+	outputType   reflect.Type
+	examples     []any
+	capabilities *entities.GrantSet
+	// Schemas generated from inputType/outputType at registration
+	inputSchema  json.RawMessage
+	outputSchema json.RawMessage
 }
 
 // DefinePlugin creates a new plugin definition.
 // Call this once at package level in your plugin.
+// It panics if def.Capabilities contains malformed rules.
 func DefinePlugin(def PluginDef) *PluginDefinition {
+	if res := entities.ValidateGrantSet(&def.Capabilities); !res.Valid {
+		panic("plugin: invalid capabilities: " + formatViolations(res.Errors))
+	}
+
+	if def.Client != nil {
+		want := def.Client.configType()
+		if def.Config == nil {
+			def.Config = reflect.New(want).Interface()
+		} else if got := structType(def.Config); got != want {
+			panic(fmt.Sprintf("plugin: client factory expects config %s, but PluginDef.Config is %s", want, got))
+		}
+	}
+
 	var configSchema []byte
 	var err error
 	if def.Config != nil {
@@ -61,16 +119,354 @@ func DefinePlugin(def PluginDef) *PluginDefinition {
 	}
 
 	return &PluginDefinition{
-		def:          def,
-		configSchema: configSchema,
-		services:     make(map[string]*serviceEntry),
+		def:               def,
+		configSchema:      configSchema,
+		services:          make(map[string]*serviceEntry),
+		serviceMiddleware: make(map[string][]Middleware),
+	}
+}
+
+// Use appends plugin-level middleware that wraps every operation handler.
+// It may be called before or after services are registered.
+func (p *PluginDefinition) Use(mws ...Middleware) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.middleware = append(p.middleware, mws...)
+}
+
+// UseService appends middleware that wraps every operation of the named service.
+// It runs inside plugin-level middleware and may be called before the service is registered.
+func (p *PluginDefinition) UseService(serviceName string, mws ...Middleware) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.serviceMiddleware[serviceName] = append(p.serviceMiddleware[serviceName], mws...)
+}
+
+// wrapHandler applies plugin and service middleware to an operation handler
+// and attaches the operation identity and its declared capabilities to the
+// context. Callers must hold p.mu.
+func (p *PluginDefinition) wrapHandler(serviceName string, op *operationEntry) HandlerFunc {
+	mws := make([]Middleware, 0, len(p.middleware)+len(p.serviceMiddleware[serviceName]))
+	mws = append(mws, p.middleware...)
+	mws = append(mws, p.serviceMiddleware[serviceName]...)
+	chained := chainMiddleware(op.handler, mws...)
+
+	// An operation may use the plugin-wide capabilities plus its own.
+	declared := p.def.Capabilities.Clone()
+	declared.Merge(op.capabilities)
+
+	info := OperationInfo{Service: serviceName, Operation: op.name}
+	return func(ctx context.Context, req *Request) (*entities.Result, error) {
+		ctx = capability.WithDeclared(ctx, declared)
+		return chained(WithOperation(ctx, info), req)
 	}
 }
 
 // Manifest returns the complete plugin manifest.
-func (p *PluginDefinition) Manifest() *entities.Manifest {
+// It implements Plugin, so a PluginDefinition can be passed directly to Register.
+func (p *PluginDefinition) Manifest(ctx context.Context) (*entities.Manifest, error) {
+	return p.buildManifest(), nil
+}
+
+// BuildManifest returns the complete plugin manifest without a context.
+// It is the replacement for the former Manifest() *entities.Manifest signature,
+// for callers that only need the manifest and have no context to pass.
+func (p *PluginDefinition) BuildManifest() *entities.Manifest {
+	return p.buildManifest()
+}
+
+// Init validates the plugin-level config against ConfigSchema, applies schema
+// defaults, builds the client with PluginDef.Client and calls PluginDef.Init.
+// It implements Initializer. If ctx already carries a client (see WithClient),
+// the factory is not called. The factory and hook may use every capability
+// the manifest declares.
+// Validation failures are returned as a *errors.ConfigError listing every violation.
+func (p *PluginDefinition) Init(ctx context.Context, config []byte) error {
+	normalized, err := p.applyConfig(config)
+	if err != nil {
+		return err
+	}
+	ctx = capability.WithDeclared(ctx, &p.buildManifest().Capabilities)
+	if ctx.Value(clientKey{}) == nil {
+		if _, err := p.sharedClient(ctx); err != nil {
+			return err
+		}
+	}
+	if p.def.Init != nil {
+		return p.def.Init(ctx, normalized)
+	}
+	return nil
+}
+
+// applyConfig validates and decodes config and stores it for injection into
+// handlers. Any previously built shared client is closed.
+func (p *PluginDefinition) applyConfig(config []byte) (json.RawMessage, error) {
+	validator, err := schema.NewValidator(p.configSchema)
+	if err != nil {
+		return nil, &errors.ConfigError{Field: "config_schema", Err: err}
+	}
+	normalized, validation, err := validator.ValidateJSON(config)
+	if err != nil {
+		return nil, &errors.ConfigError{Field: "config", Err: err}
+	}
+	if !validation.Valid {
+		return nil, errors.NewValidationConfigError(validation)
+	}
+
+	decoded, err := p.decodeConfig(normalized)
+	if err != nil {
+		return nil, &errors.ConfigError{Field: "config", Err: err}
+	}
+
+	p.mu.Lock()
+	previous := p.client
+	p.config = normalized
+	p.configValue = decoded
+	p.client = nil
+	p.initialized = true
+	p.mu.Unlock()
+	closeClient(previous)
+
+	return normalized, nil
+}
+
+// Config returns the decoded plugin config that handlers read with GetConfig,
+// or nil if PluginDef.Config is not set. Until Init has run it returns the
+// zero config, except for plugins with a client factory: hosts that never
+// call _init still get a client, so such a plugin is initialized from an
+// empty config, with schema defaults applied and missing fields reported.
+func (p *PluginDefinition) Config() (any, error) {
+	p.mu.RLock()
+	initialized, config := p.initialized, p.configValue
+	p.mu.RUnlock()
+
+	switch {
+	case initialized:
+		return config, nil
+	case p.def.Client == nil:
+		return p.decodeConfig(nil)
+	}
+	if _, err := p.applyConfig(nil); err != nil {
+		return nil, err
+	}
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	return p.configValue, nil
+}
+
+// decodeConfig decodes validated config JSON into a new value of PluginDef.Config's type.
+func (p *PluginDefinition) decodeConfig(config json.RawMessage) (any, error) {
+	if p.def.Config == nil {
+		return nil, nil
+	}
+	value := reflect.New(structType(p.def.Config)).Interface()
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// sharedClient returns the client shared by all invocations, building it on
+// first use. It returns nil when there is no factory or clients are built
+// per invocation.
+func (p *PluginDefinition) sharedClient(ctx context.Context) (any, error) {
+	if p.def.Client == nil || p.def.ClientPerInvocation {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+	client, err := p.def.Client.newClient(ctx, p.configValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	p.client = client
+	return client, nil
+}
+
+// newRequest builds the handler request, injecting the decoded config and the
+// client. A client already attached to ctx with WithClient (e.g. a fake in
+// tests) takes precedence over PluginDef.Client.
+// The returned release func closes a per-invocation client.
+func (p *PluginDefinition) newRequest(ctx context.Context, input json.RawMessage) (*Request, func(), error) {
+	config, err := p.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	req := &Request{Raw: input, Config: config}
+
+	if override := ctx.Value(clientKey{}); override != nil {
+		req.Client = override
+		return req, func() {}, nil
+	}
+	if p.def.Client == nil {
+		return req, func() {}, nil
+	}
+
+	if !p.def.ClientPerInvocation {
+		client, err := p.sharedClient(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Client = client
+		return req, func() {}, nil
+	}
+
+	client, err := p.def.Client.newClient(ctx, req.Config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	req.Client = client
+	return req, func() { closeClient(client) }, nil
+}
+
+// closeClient closes clients that implement io.Closer.
+func closeClient(client any) {
+	if closer, ok := client.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// structType returns the struct type behind a config prototype such as &Config{}.
+func structType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Shutdown calls PluginDef.Shutdown, then closes the shared client if it
+// implements io.Closer. It implements Shutdowner.
+func (p *PluginDefinition) Shutdown(ctx context.Context) error {
+	var err error
+	if p.def.Shutdown != nil {
+		err = p.def.Shutdown(ctx)
+	}
+
+	p.mu.Lock()
+	client := p.client
+	p.client = nil
+	p.initialized = false
+	p.mu.Unlock()
+	closeClient(client)
+
+	return err
+}
+
+// Check decodes an invocation envelope and dispatches it to the registered
+// handler for the requested service and operation. It implements Plugin.
+// The handler context carries the deadline, cancellation and request ID
+// sent by the host in the envelope.
+//
+// Service and operation may be omitted when the plugin registers exactly one
+// service or the service has exactly one operation.
+// Unknown services and operations are reported as error Results.
+func (p *PluginDefinition) Check(ctx context.Context, config []byte) (*entities.Result, error) {
+	var inv entities.InvocationRequest
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &inv); err != nil {
+			wireErr := &errors.WireFormatError{Operation: "decode", Type: "InvocationRequest", Err: err}
+			return resultFromError(wireErr), nil
+		}
+	}
+
+	// Apply the host's deadline, cancellation and request ID, and expose the
+	// result to SDK functions that read the current context.
+	ctx, cancel := wasmcontext.WireToContext(ctx, inv.Context)
+	defer cancel()
+	previous := wasmcontext.GetCurrentContext()
+	wasmcontext.SetCurrentContext(ctx)
+	defer wasmcontext.SetCurrentContext(previous)
+
+	handler, err := p.resolveHandler(inv.Service, inv.Operation)
+	if err != nil {
+		return resultFromError(err), nil
+	}
+
+	req, release, err := p.newRequest(ctx, inv.Input)
+	if err != nil {
+		return resultFromError(err), nil
+	}
+	defer release()
+
+	result, err := handler(ctx, req)
+	if err != nil {
+		return resultFromError(err), nil
+	}
+	return result, nil
+}
+
+// resolveHandler finds the handler for an invocation, defaulting the service
+// and operation names when they are unambiguous.
+func (p *PluginDefinition) resolveHandler(serviceName, opName string) (HandlerFunc, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if serviceName == "" && len(p.services) == 1 {
+		for name := range p.services {
+			serviceName = name
+		}
+	}
+	svc, ok := p.services[serviceName]
+	if !ok {
+		return nil, &errors.UnknownServiceError{Service: serviceName, Available: sortedKeys(p.services)}
+	}
+
+	if opName == "" && len(svc.operations) == 1 {
+		for name := range svc.operations {
+			opName = name
+		}
+	}
+	op, ok := svc.operations[opName]
+	if !ok {
+		return nil, &errors.UnknownOperationError{
+			Service:   serviceName,
+			Operation: opName,
+			Available: sortedKeys(svc.operations),
+		}
+	}
+
+	return p.wrapHandler(serviceName, op), nil
+}
+
+// resultFromError converts an error into an error Result, preserving structured details.
+// Plain errors keep the "execution" type that hosts have always received for
+// handler errors.
+func resultFromError(err error) *entities.Result {
+	var detail *entities.ErrorDetail
+	var detailed errors.DetailedError
+	if !stdErrors.As(err, &detail) && !stdErrors.As(err, &detailed) {
+		return entities.ResultErrorPtr("execution", err.Error())
+	}
+	r := entities.ResultError(errors.ToErrorDetail(err))
+	return &r
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildManifest assembles the manifest from the plugin definition and registered services.
+func (p *PluginDefinition) buildManifest() *entities.Manifest {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// Plugin-level capabilities are the union of the declared plugin-wide
+	// grants and every operation's grants.
+	capabilities := p.def.Capabilities.Clone()
 
 	services := make(map[string]entities.ServiceManifest)
 	for name, svc := range p.services {
@@ -86,22 +482,21 @@ func (p *PluginDefinition) Manifest() *entities.Manifest {
 				opManifest.InputFields = extractFieldNames(op.inputType)
 			}
 
-			// Generate output schema from output type (if available)
-			if op.outputType != nil {
-				// We create a new instance of the type to ensure it works with GenerateSchema
-				// reflect.New returns a pointer to the type
-				val := reflect.New(op.outputType).Elem().Interface()
-				outputSchema, err := schema.GenerateSchema(val)
-				if err == nil {
-					opManifest.OutputSchema = outputSchema
-				}
-			}
+			// Schemas were generated at registration; copy them so callers
+			// cannot modify the cached bytes.
+			opManifest.InputSchema = bytes.Clone(op.inputSchema)
+			opManifest.OutputSchema = bytes.Clone(op.outputSchema)
 
 			// Convert examples to manifest format
 			if len(op.examples) > 0 {
 				opManifest.Examples = convertExamplesToManifest(op.examples)
 			}
 
+			if op.capabilities != nil {
+				opManifest.Capabilities = op.capabilities.Clone()
+				capabilities.Merge(op.capabilities)
+			}
+
 			ops = append(ops, opManifest)
 		}
 		services[name] = entities.ServiceManifest{
@@ -116,7 +511,7 @@ func (p *PluginDefinition) Manifest() *entities.Manifest {
 		Version:      p.def.Version,
 		Description:  p.def.Description,
 		SDKVersion:   Version, // From sdk version.go
-		Capabilities: p.def.Capabilities,
+		Capabilities: *capabilities,
 		ConfigSchema: p.configSchema,
 		Services:     services,
 	}
@@ -131,6 +526,26 @@ func (p *PluginDefinition) RegisterHandler(
 	inputType, outputType reflect.Type,
 	examples []any,
 ) {
+	p.registerOperation(serviceName, serviceDesc, &operationEntry{
+		name:        opName,
+		description: opDesc,
+		handler:     handler,
+		inputType:   inputType,
+		outputType:  outputType,
+		examples:    examples,
+	})
+}
+
+// registerOperation adds an operation to a service, creating the service on first use.
+// Input and output schemas are generated here once rather than on every Manifest call.
+func (p *PluginDefinition) registerOperation(serviceName, serviceDesc string, op *operationEntry) {
+	if op.inputType != nil && op.inputSchema == nil {
+		op.inputSchema, _ = inputSchemaForType(op.inputType)
+	}
+	if op.outputType != nil && op.outputSchema == nil {
+		op.outputSchema, _ = schemaForType(op.outputType)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -144,17 +559,24 @@ func (p *PluginDefinition) RegisterHandler(
 		p.services[serviceName] = svc
 	}
 
-	svc.operations[opName] = &operationEntry{
-		name:        opName,
-		description: opDesc,
-		handler:     handler,
-		inputType:   inputType,
-		outputType:  outputType,
-		examples:    examples,
+	svc.operations[op.name] = op
+}
+
+// hasOperation reports whether a service/operation is already registered.
+func (p *PluginDefinition) hasOperation(serviceName, opName string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	svc, ok := p.services[serviceName]
+	if !ok {
+		return false
 	}
+	_, ok = svc.operations[opName]
+	return ok
 }
 
-// GetHandler returns a handler for the given service/operation.
+// GetHandler returns a handler for the given service/operation,
+// wrapped in the registered middleware.
 func (p *PluginDefinition) GetHandler(serviceName, opName string) (HandlerFunc, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -169,7 +591,7 @@ func (p *PluginDefinition) GetHandler(serviceName, opName string) (HandlerFunc,
 		return nil, false
 	}
 
-	return op.handler, true
+	return p.wrapHandler(serviceName, op), true
 }
 
 // extractFieldNames gets JSON field names from a struct type.
@@ -227,3 +649,12 @@ func convertExamplesToManifest(examples []any) []entities.OperationExample {
 
 	return result
 }
+
+// formatViolations joins validation errors as "field: message; ...".
+func formatViolations(errs []entities.ValidationError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Field + ": " + e.Message
+	}
+	return strings.Join(parts, "; ")
+}
@@ -3,9 +3,11 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +36,7 @@ func TestManifest_WithTypedOp(t *testing.T) {
 	})
 
 	// Simulate service registration with type info
-	typeInfo, _ := getOpTypeInfo("TestOp")
+	typeInfo, _, _ := lookupOpTypeInfo(nil, "TestOp", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
 	plugin.RegisterHandler(
 		"test", "Test service",
 		"test_op", "Test operation",
@@ -46,7 +48,7 @@ func TestManifest_WithTypedOp(t *testing.T) {
 		typeInfo.examples,
 	)
 
-	manifest := plugin.Manifest()
+	manifest := plugin.buildManifest()
 
 	// Verify service exists
 	svc, ok := manifest.Services["test"]
@@ -72,3 +74,181 @@ func TestManifest_WithTypedOp(t *testing.T) {
 	assert.NotEmpty(t, op.Examples[0].Input)
 	assert.NotEmpty(t, op.Examples[0].ExpectedOutput)
 }
+
+type schemaTestServer struct {
+	Host string `json:"host" jsonschema:"required,description=Server hostname"`
+	Port int    `json:"port,omitempty" jsonschema:"default=53"`
+}
+
+type schemaTestInput struct {
+	Mode    string             `json:"mode" jsonschema:"required,enum=fast,enum=slow"`
+	Label   string             `json:"label"`
+	Servers []schemaTestServer `json:"servers,omitempty"`
+}
+
+func TestBuildManifest_MatchesManifest(t *testing.T) {
+	plugin := DefinePlugin(PluginDef{Name: "test", Version: "1.0.0"})
+
+	manifest, err := plugin.Manifest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, manifest, plugin.BuildManifest())
+}
+
+func TestManifest_InputSchema(t *testing.T) {
+	plugin := DefinePlugin(PluginDef{Name: "test", Version: "1.0.0"})
+	plugin.RegisterHandler("svc", "", "op", "",
+		func(ctx context.Context, req *Request) (*entities.Result, error) { return nil, nil },
+		reflect.TypeOf(schemaTestInput{}), reflect.TypeOf(testOutput{}), nil)
+
+	manifest, err := plugin.Manifest(context.Background())
+	require.NoError(t, err)
+	op := manifest.Services["svc"].Operations[0]
+	require.NotEmpty(t, op.InputSchema)
+
+	// The schema carries nested types, required fields, enums, defaults and descriptions.
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(op.InputSchema, &doc))
+	assert.Equal(t, []any{"mode"}, doc["required"], "only jsonschema:\"required\" fields are required")
+	assert.NotContains(t, doc, "additionalProperties", "unknown fields are allowed")
+	mode := doc["properties"].(map[string]any)["mode"].(map[string]any)
+	assert.Equal(t, []any{"fast", "slow"}, mode["enum"])
+
+	server := doc["$defs"].(map[string]any)["schemaTestServer"].(map[string]any)
+	assert.Equal(t, []any{"host"}, server["required"])
+	serverProps := server["properties"].(map[string]any)
+	assert.Equal(t, "Server hostname", serverProps["host"].(map[string]any)["description"])
+	assert.Equal(t, float64(53), serverProps["port"].(map[string]any)["default"])
+}
+
+func TestManifest_SchemasAreCached(t *testing.T) {
+	first, err := schemaForType(reflect.TypeOf(schemaTestInput{}))
+	require.NoError(t, err)
+	second, err := schemaForType(reflect.TypeOf(schemaTestInput{}))
+	require.NoError(t, err)
+	assert.Same(t, &first[0], &second[0], "schema should be generated once per type")
+
+	plugin := DefinePlugin(PluginDef{Name: "test", Version: "1.0.0"})
+	plugin.RegisterHandler("svc", "", "op", "",
+		func(ctx context.Context, req *Request) (*entities.Result, error) { return nil, nil },
+		reflect.TypeOf(schemaTestInput{}), reflect.TypeOf(testOutput{}), nil)
+
+	// Manifests get copies, so mutating one does not affect the cache.
+	m1 := plugin.buildManifest()
+	m1.Services["svc"].Operations[0].InputSchema[0] = 'X'
+	m2 := plugin.buildManifest()
+	assert.Equal(t, byte('{'), m2.Services["svc"].Operations[0].InputSchema[0])
+}
+
+type lifecycleConfig struct {
+	Endpoint string `json:"endpoint" jsonschema:"required"`
+	Retries  int    `json:"retries,omitempty" jsonschema:"default=3"`
+}
+
+func TestInit_ValidatesConfigAndCallsHook(t *testing.T) {
+	var got json.RawMessage
+	plugin := DefinePlugin(PluginDef{
+		Name:    "lifecycle",
+		Version: "1.0.0",
+		Config:  &lifecycleConfig{},
+		Init: func(ctx context.Context, config json.RawMessage) error {
+			got = config
+			return nil
+		},
+	})
+
+	require.NoError(t, plugin.Init(context.Background(), []byte(`{"endpoint":"https://api.example.com"}`)))
+	assert.JSONEq(t, `{"endpoint":"https://api.example.com","retries":3}`, string(got), "defaults are applied")
+}
+
+func TestCheck_WithoutInitUsesZeroConfig(t *testing.T) {
+	// Plugins may declare Config only to publish ConfigSchema and run on
+	// hosts that never call _init; Check must not validate an empty config.
+	plugin := DefinePlugin(PluginDef{Name: "lifecycle", Version: "1.0.0", Config: &lifecycleConfig{}})
+	var got *lifecycleConfig
+	plugin.RegisterHandler("svc", "", "op", "",
+		func(ctx context.Context, req *Request) (*entities.Result, error) {
+			got = req.Config.(*lifecycleConfig)
+			res := entities.ResultSuccess("ok", nil)
+			return &res, nil
+		},
+		reflect.TypeOf(testOutput{}), reflect.TypeOf(testOutput{}), nil)
+
+	res, err := plugin.Check(context.Background(), []byte(`{"input":{}}`))
+	require.NoError(t, err)
+	require.Nil(t, res.Error)
+	assert.Equal(t, &lifecycleConfig{}, got)
+}
+
+func TestInit_InvalidConfig(t *testing.T) {
+	called := false
+	plugin := DefinePlugin(PluginDef{
+		Name:   "lifecycle",
+		Config: &lifecycleConfig{},
+		Init: func(ctx context.Context, config json.RawMessage) error {
+			called = true
+			return nil
+		},
+	})
+
+	err := plugin.Init(context.Background(), []byte(`{"retries":"many"}`))
+	require.Error(t, err)
+	assert.False(t, called, "hook must not run with invalid config")
+
+	var cfgErr *errors.ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	fields := map[string]bool{}
+	for _, v := range cfgErr.Violations {
+		fields[v.Field] = true
+	}
+	assert.True(t, fields["endpoint"])
+	assert.True(t, fields["retries"])
+
+	detail := errors.ToErrorDetail(err)
+	assert.Equal(t, "config", detail.Type)
+}
+
+func TestInit_HookErrorIsReturned(t *testing.T) {
+	hookErr := &errors.NetworkError{Operation: "connect", Target: "api.example.com"}
+	plugin := DefinePlugin(PluginDef{
+		Name: "lifecycle",
+		Init: func(ctx context.Context, config json.RawMessage) error { return hookErr },
+	})
+
+	err := plugin.Init(context.Background(), nil)
+	assert.Same(t, hookErr, err)
+}
+
+func TestShutdown(t *testing.T) {
+	plugin := DefinePlugin(PluginDef{Name: "lifecycle"})
+	assert.NoError(t, plugin.Init(context.Background(), nil), "no config schema and no hook")
+	assert.NoError(t, plugin.Shutdown(context.Background()), "no hook")
+
+	calls := 0
+	plugin = DefinePlugin(PluginDef{
+		Name: "lifecycle",
+		Shutdown: func(ctx context.Context) error {
+			calls++
+			return nil
+		},
+	})
+	require.NoError(t, plugin.Shutdown(context.Background()))
+	assert.Equal(t, 1, calls)
+}
+
+func TestDefinePlugin_InvalidCapabilitiesPanics(t *testing.T) {
+	assert.PanicsWithValue(t,
+		`plugin: invalid capabilities: network.rules[0].ports[0]: port range "80-": end is missing; fs.rules[0].read[0]: path "etc/hosts" must be absolute`,
+		func() {
+			DefinePlugin(PluginDef{
+				Name: "invalid",
+				Capabilities: entities.GrantSet{
+					Network: &entities.NetworkCapability{
+						Rules: []entities.NetworkRule{{Hosts: []string{"example.com"}, Ports: []string{"80-"}}},
+					},
+					FS: &entities.FileSystemCapability{
+						Rules: []entities.FileSystemRule{{Read: []string{"etc/hosts"}}},
+					},
+				},
+			})
+		})
+}
@@ -0,0 +1,30 @@
+package plugin
+
+// Failure reports that a check ran to completion but the target is not
+// compliant. Typed handlers return it as their error to produce a Result with
+// ResultStatusFailure instead of ResultStatusError. Use Fail to create one.
+type Failure struct {
+	// Output is serialized into Result.Data. If nil, the handler's returned
+	// output value is used instead.
+	Output  any
+	Message string
+}
+
+func (f *Failure) Error() string {
+	return f.Message
+}
+
+// Fail returns a Failure carrying a message and optional output data.
+//
+// Example:
+//
+//	func (s *TLSService) Check(ctx context.Context, in *CheckInput) (*CheckOutput, error) {
+//	    out := &CheckOutput{DaysLeft: days}
+//	    if days < in.MinDays {
+//	        return nil, plugin.Fail("certificate expires too soon", out)
+//	    }
+//	    return out, nil
+//	}
+func Fail(message string, output any) error {
+	return &Failure{Message: message, Output: output}
+}
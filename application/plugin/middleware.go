@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+)
+
+// Middleware wraps a HandlerFunc with cross-cutting behaviour such as timing,
+// logging, panic recovery or retries.
+//
+// Middleware is applied when a handler is looked up, in this order (outermost first):
+//  1. plugin-level middleware registered with PluginDefinition.Use, in call order
+//  2. service-level middleware registered with PluginDefinition.UseService, in call order
+//  3. the operation handler
+//
+// The service and operation being invoked are available via OperationFromContext.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// OperationInfo identifies the operation a handler invocation belongs to.
+type OperationInfo struct {
+	Service   string
+	Operation string
+}
+
+// operationKey is the context key for storing the current OperationInfo.
+type operationKey struct{}
+
+// WithOperation returns a new context carrying the operation identity.
+// Called by the SDK before invoking the middleware chain.
+func WithOperation(ctx context.Context, info OperationInfo) context.Context {
+	return context.WithValue(ctx, operationKey{}, info)
+}
+
+// OperationFromContext returns the operation identity for the current invocation.
+func OperationFromContext(ctx context.Context) (OperationInfo, bool) {
+	info, ok := ctx.Value(operationKey{}).(OperationInfo)
+	return info, ok
+}
+
+// chainMiddleware wraps handler so that mws[0] is the outermost middleware.
+func chainMiddleware(handler HandlerFunc, mws ...Middleware) HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// Recover converts a panic inside the handler into an error Result for that
+// operation only, instead of aborting the whole plugin call.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (result *entities.Result, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					info, _ := OperationFromContext(ctx)
+					detail := &entities.ErrorDetail{
+						Message: fmt.Sprintf("operation %s/%s panicked: %v", info.Service, info.Operation, r),
+						Type:    "panic",
+						Stack:   debug.Stack(),
+					}
+					res := entities.ResultError(detail)
+					result, err = &res, nil
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Timing records the start and end time of each invocation in Result.Metadata
+// unless the handler already set it.
+func Timing() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+			if result != nil && result.Metadata == nil {
+				result.Metadata = entities.NewRunMetadata(start, time.Now())
+			}
+			return result, err
+		}
+	}
+}
+
+// Logging logs each invocation with its service, operation, outcome and duration.
+// Top-level input fields named in redact are replaced before the input is logged.
+// A nil logger uses slog.Default().
+func Logging(logger *slog.Logger, redact ...string) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			info, _ := OperationFromContext(ctx)
+			logger.DebugContext(ctx, "sdk: operation started",
+				"service", info.Service,
+				"operation", info.Operation,
+				"input", redactInput(req.Raw, redact))
+
+			start := time.Now()
+			result, err := next(ctx, req)
+			attrs := []any{
+				"service", info.Service,
+				"operation", info.Operation,
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+
+			switch {
+			case err != nil:
+				logger.ErrorContext(ctx, "sdk: operation failed", append(attrs, "error", err.Error())...)
+			case result != nil && result.Status == entities.ResultStatusError:
+				msg := result.Message
+				if result.Error != nil {
+					msg = result.Error.Message
+				}
+				logger.ErrorContext(ctx, "sdk: operation failed", append(attrs, "error", msg)...)
+			case result != nil:
+				logger.InfoContext(ctx, "sdk: operation completed", append(attrs, "status", string(result.Status))...)
+			}
+			return result, err
+		}
+	}
+}
+
+// redactInput returns the raw input with the named top-level fields masked.
+func redactInput(raw []byte, fields []string) string {
+	if len(fields) == 0 || len(raw) == 0 {
+		return string(raw)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return "<unparseable>"
+	}
+	for _, f := range fields {
+		if _, ok := m[f]; ok {
+			m[f] = "[REDACTED]"
+		}
+	}
+	redacted, err := json.Marshal(m)
+	if err != nil {
+		return "<unparseable>"
+	}
+	return string(redacted)
+}
+
+// Timeout bounds each invocation with the given timeout.
+// A zero or negative duration disables the middleware.
+func Timeout(d time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		if d <= 0 {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Retry re-invokes the handler up to attempts times in total when it fails with
+// a transient error: a Go error that is a net.Error, context.DeadlineExceeded,
+// *errors.TimeoutError or *errors.NetworkError, or an error Result of type
+// "network" or "timeout". Other errors are returned at once. It waits backoff
+// between attempts, doubling each time, and stops early if the context is done.
+func Retry(attempts int, backoff time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		if attempts <= 1 {
+			return next
+		}
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			var result *entities.Result
+			var err error
+			wait := backoff
+			for i := 0; i < attempts; i++ {
+				result, err = next(ctx, req)
+				if !isRetryable(result, err) || i == attempts-1 {
+					break
+				}
+				select {
+				case <-ctx.Done():
+					return result, err
+				case <-time.After(wait):
+				}
+				wait *= 2
+			}
+			return result, err
+		}
+	}
+}
+
+// isRetryable reports whether an invocation outcome is a transient failure.
+func isRetryable(result *entities.Result, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		var timeoutErr *errors.TimeoutError
+		var networkErr *errors.NetworkError
+		return stdErrors.As(err, &netErr) ||
+			stdErrors.Is(err, context.DeadlineExceeded) ||
+			stdErrors.As(err, &timeoutErr) ||
+			stdErrors.As(err, &networkErr)
+	}
+	if result == nil || result.Status != entities.ResultStatusError || result.Error == nil {
+		return false
+	}
+	return result.Error.IsTimeout || result.Error.Type == "network" || result.Error.Type == "timeout"
+}
+
+// MetricsRecorder receives one observation per handler invocation.
+type MetricsRecorder func(info OperationInfo, status entities.ResultStatus, duration time.Duration)
+
+// Metrics reports the outcome and duration of every invocation to record.
+// Invocations that return a Go error are reported with ResultStatusError.
+func Metrics(record MetricsRecorder) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+
+			status := entities.ResultStatusError
+			if err == nil && result != nil {
+				status = result.Status
+			}
+			info, _ := OperationFromContext(ctx)
+			record(info, status, time.Since(start))
+			return result, err
+		}
+	}
+}
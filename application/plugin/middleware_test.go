@@ -0,0 +1,187 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	sdkerrors "github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMiddlewareTestPlugin(handler HandlerFunc) *PluginDefinition {
+	def := DefinePlugin(PluginDef{Name: "mw", Version: "1.0.0"})
+	def.RegisterHandler("svc", "Service", "op", "Operation", handler, nil, nil, nil)
+	return def
+}
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			*calls = append(*calls, name+":before")
+			res, err := next(ctx, req)
+			*calls = append(*calls, name+":after")
+			return res, err
+		}
+	}
+}
+
+func TestMiddleware_Order(t *testing.T) {
+	var calls []string
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		calls = append(calls, "handler")
+		return entities.ResultSuccessPtr("ok", nil), nil
+	})
+
+	def.UseService("svc", recordingMiddleware("service", &calls))
+	def.Use(recordingMiddleware("plugin1", &calls), recordingMiddleware("plugin2", &calls))
+	def.UseService("other", recordingMiddleware("other", &calls))
+
+	handler, ok := def.GetHandler("svc", "op")
+	require.True(t, ok)
+	_, err := handler(context.Background(), &Request{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"plugin1:before", "plugin2:before", "service:before",
+		"handler",
+		"service:after", "plugin2:after", "plugin1:after",
+	}, calls)
+}
+
+func TestMiddleware_OperationInfo(t *testing.T) {
+	var seen OperationInfo
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		return entities.ResultSuccessPtr("ok", nil), nil
+	})
+	def.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (*entities.Result, error) {
+			seen, _ = OperationFromContext(ctx)
+			return next(ctx, req)
+		}
+	})
+
+	_, err := def.Check(context.Background(), []byte(`{"service":"svc","operation":"op"}`))
+	require.NoError(t, err)
+	assert.Equal(t, OperationInfo{Service: "svc", Operation: "op"}, seen)
+}
+
+func TestMiddleware_Recover(t *testing.T) {
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		panic("boom")
+	})
+	def.Use(Recover())
+
+	res, err := def.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResultStatusError, res.Status)
+	assert.Equal(t, "panic", res.Error.Type)
+	assert.Contains(t, res.Error.Message, "svc/op panicked: boom")
+	assert.NotEmpty(t, res.Error.Stack)
+}
+
+func TestMiddleware_Timing(t *testing.T) {
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		return entities.ResultSuccessPtr("ok", nil), nil
+	})
+	def.Use(Timing())
+
+	res, err := def.Check(context.Background(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, res.Metadata)
+	assert.False(t, res.Metadata.StartTime.IsZero())
+}
+
+func TestMiddleware_Retry(t *testing.T) {
+	attempts := 0
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		attempts++
+		if attempts < 3 {
+			return entities.ResultErrorPtr("network", "connection reset"), nil
+		}
+		return entities.ResultSuccessPtr("ok", nil), nil
+	})
+	def.Use(Retry(5, time.Millisecond))
+
+	res, err := def.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResultStatusSuccess, res.Status)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestMiddleware_RetryNotForConfigErrors(t *testing.T) {
+	attempts := 0
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		attempts++
+		return entities.ResultErrorPtr("config", "bad input"), nil
+	})
+	def.Use(Retry(5, time.Millisecond))
+
+	_, err := def.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestMiddleware_RetryGoErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"permanent", errors.New("invalid hostname"), 1},
+		{"deadline", fmt.Errorf("lookup: %w", context.DeadlineExceeded), 3},
+		{"net error", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, 3},
+		{"network", &sdkerrors.NetworkError{Operation: "dns", Err: errors.New("refused")}, 3},
+		{"timeout", &sdkerrors.TimeoutError{Operation: "http", Duration: time.Second}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+				attempts++
+				return nil, tt.err
+			})
+			def.Use(Retry(3, time.Millisecond))
+
+			_, _ = def.Check(context.Background(), nil)
+			assert.Equal(t, tt.attempts, attempts)
+		})
+	}
+}
+
+func TestMiddleware_Timeout(t *testing.T) {
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return entities.ResultSuccessPtr("ok", nil), nil
+	})
+	def.Use(Timeout(time.Second))
+
+	_, err := def.Check(context.Background(), nil)
+	require.NoError(t, err)
+}
+
+func TestMiddleware_Metrics(t *testing.T) {
+	var statuses []entities.ResultStatus
+	def := newMiddlewareTestPlugin(func(ctx context.Context, req *Request) (*entities.Result, error) {
+		return nil, errors.New("failed")
+	})
+	def.Use(Metrics(func(info OperationInfo, status entities.ResultStatus, d time.Duration) {
+		assert.Equal(t, "op", info.Operation)
+		statuses = append(statuses, status)
+	}))
+
+	_, _ = def.Check(context.Background(), nil)
+	assert.Equal(t, []entities.ResultStatus{entities.ResultStatusError}, statuses)
+}
+
+func TestRedactInput(t *testing.T) {
+	out := redactInput([]byte(`{"user":"bob","password":"hunter2"}`), []string{"password"})
+	assert.Contains(t, out, `"password":"[REDACTED]"`)
+	assert.Contains(t, out, `"user":"bob"`)
+}
@@ -1,13 +1,21 @@
 package plugin
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 )
 
 // operation is a marker interface for identifying Op fields via reflection.
+// opTypes exposes the I and O type parameters, which reflect cannot extract
+// from an instantiated generic type on its own.
 type operation interface {
 	isOp()
+	opTypes() (input, output reflect.Type)
 }
 
 // Op defines a typed operation with explicit input and output types.
@@ -17,6 +25,10 @@ type Op[I, O any] struct{}
 
 func (Op[I, O]) isOp() {}
 
+func (Op[I, O]) opTypes() (input, output reflect.Type) {
+	return reflect.TypeFor[I](), reflect.TypeFor[O]()
+}
+
 // Example defines a sample input/output pair for documentation and testing.
 type Example[I, O any] struct {
 	// Name is a short identifier (e.g., "basic", "with_tls", "error_case")
@@ -36,22 +48,79 @@ type Example[I, O any] struct {
 	ExpectedError string
 }
 
-// opTypeInfo stores type information captured during registration.
-// Since Go's reflect cannot extract type parameters from instantiated generics,
-// we capture them at registration time using the generic RegisterOp function.
+// OpOption configures an operation registered with RegisterOp.
+// Example values and WithCapabilities both implement OpOption.
+type OpOption interface {
+	applyOp(info *opTypeInfo)
+}
+
+func (ex Example[I, O]) applyOp(info *opTypeInfo) {
+	info.examples = append(info.examples, ex)
+}
+
+// capabilitiesOption declares the grants an operation needs.
+type capabilitiesOption struct {
+	grants entities.GrantSet
+}
+
+func (o capabilitiesOption) applyOp(info *opTypeInfo) {
+	if info.capabilities == nil {
+		info.capabilities = &entities.GrantSet{}
+	}
+	info.capabilities.Merge(&o.grants)
+}
+
+// WithCapabilities declares the capabilities an operation needs.
+// The manifest lists them on the operation, and the plugin-level capabilities
+// become the merge of PluginDef.Capabilities and every operation's grants.
+//
+// Example:
+//
+//	plugin.RegisterOp[RestartInput, RestartOutput]("Restart",
+//	    plugin.WithCapabilities(entities.GrantSet{
+//	        Exec: &entities.ExecCapability{Commands: []string{"systemctl"}},
+//	    }),
+//	)
+func WithCapabilities(grants entities.GrantSet) OpOption {
+	return capabilitiesOption{grants: *grants.Clone()}
+}
+
+// opTypeInfo stores examples and capabilities captured by RegisterOp or
+// RegisterServiceOp. Input and output types are recorded for registrations
+// but are always read from the Op[I,O] field itself when a service is registered.
 type opTypeInfo struct {
+	inputType    reflect.Type
+	outputType   reflect.Type
+	capabilities *entities.GrantSet
+	examples     []any // []Example[I,O] stored as any for type erasure
+	hasOptions   bool
+}
+
+// opKey identifies an operation registration. service is nil for
+// registrations made with RegisterOp, which apply to any service whose field
+// has the same name and the same I/O types.
+type opKey struct {
+	service    reflect.Type
 	inputType  reflect.Type
 	outputType reflect.Type
-	examples   []any // []Example[I,O] stored as any for type erasure
+	fieldName  string
 }
 
 var (
-	opRegistry   = make(map[string]opTypeInfo)
+	opRegistry   = make(map[opKey]opTypeInfo)
 	opRegistryMu sync.RWMutex
 )
 
-// RegisterOp captures type parameters and examples for an operation.
-// Must be called in init() BEFORE MustRegisterService for each Op field.
+// RegisterOp attaches examples and capabilities to an Op field.
+// Call it in init() before MustRegisterService. Operations without examples
+// or capabilities do not need to be registered.
+//
+// The registration applies to any service with a field of this name whose type
+// is Op[I, O]. Registering the service fails if the field has other I/O types
+// and no registration matches them, so a mismatch never drops examples or
+// capabilities silently. Use RegisterServiceOp to scope a registration to one
+// service type.
+// Registering the same field name and types twice with options panics.
 //
 // Example:
 //
@@ -64,39 +133,139 @@ var (
 //	    )
 //	    plugin.MustRegisterService(core.Plugin, &DNSService{})
 //	}
-func RegisterOp[I, O any](fieldName string, examples ...Example[I, O]) {
-	opRegistryMu.Lock()
-	defer opRegistryMu.Unlock()
+func RegisterOp[I, O any](fieldName string, opts ...OpOption) {
+	info := newOpTypeInfo[I, O](fieldName, opts)
+	key := opKey{fieldName: fieldName, inputType: info.inputType, outputType: info.outputType}
+	if err := storeOpTypeInfo(key, info); err != nil {
+		panic(err.Error())
+	}
+}
 
-	var zeroIn I
-	var zeroOut O
+// RegisterServiceOp attaches examples and capabilities to an Op field of the
+// service struct S. It panics if S has no field fieldName of type Op[I, O], or
+// if the field was already registered for S with options.
+//
+// Example:
+//
+//	plugin.RegisterServiceOp[DNSService, ResolveInput, ResolveOutput]("Resolve",
+//	    plugin.Example[ResolveInput, ResolveOutput]{
+//	        Name:  "basic",
+//	        Input: ResolveInput{Hostname: "example.com"},
+//	    },
+//	    plugin.WithCapabilities(entities.GrantSet{
+//	        Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{"*"}, Ports: []string{"53"}}}},
+//	    }),
+//	)
+func RegisterServiceOp[S, I, O any](fieldName string, opts ...OpOption) {
+	svcType := reflect.TypeFor[S]()
+	if svcType.Kind() == reflect.Ptr {
+		svcType = svcType.Elem()
+	}
+
+	field, ok := svcType.FieldByName(fieldName)
+	if svcType.Kind() != reflect.Struct || !ok {
+		panic(fmt.Sprintf("plugin: RegisterServiceOp: %s has no field %q", svcType, fieldName))
+	}
+	if field.Type != reflect.TypeFor[Op[I, O]]() {
+		panic(fmt.Sprintf("plugin: RegisterServiceOp: field %s.%s has type %s, not %s",
+			svcType, fieldName, field.Type, reflect.TypeFor[Op[I, O]]()))
+	}
 
-	// Convert typed examples to []any for storage
-	examplesAny := make([]any, len(examples))
-	for i, ex := range examples {
-		examplesAny[i] = ex
+	info := newOpTypeInfo[I, O](fieldName, opts)
+	key := opKey{service: svcType, fieldName: fieldName, inputType: info.inputType, outputType: info.outputType}
+	if err := storeOpTypeInfo(key, info); err != nil {
+		panic(err.Error())
 	}
+}
 
-	opRegistry[fieldName] = opTypeInfo{
-		inputType:  reflect.TypeOf(zeroIn),
-		outputType: reflect.TypeOf(zeroOut),
-		examples:   examplesAny,
+// newOpTypeInfo applies options for an Op[I, O], checking example types.
+func newOpTypeInfo[I, O any](fieldName string, opts []OpOption) opTypeInfo {
+	info := opTypeInfo{
+		inputType:  reflect.TypeFor[I](),
+		outputType: reflect.TypeFor[O](),
+		examples:   []any{},
+		hasOptions: len(opts) > 0,
+	}
+	for _, opt := range opts {
+		if _, isExample := opt.(interface{ isExample() }); isExample {
+			if _, ok := opt.(Example[I, O]); !ok {
+				panic(fmt.Sprintf("plugin: RegisterOp(%q): example type %T does not match Op[%s, %s]",
+					fieldName, opt, info.inputType, info.outputType))
+			}
+		}
+		opt.applyOp(&info)
 	}
+	return info
 }
 
-// getOpTypeInfo retrieves registered type info for an operation field.
-// Returns false if the field was not registered.
-func getOpTypeInfo(fieldName string) (opTypeInfo, bool) {
+func (Example[I, O]) isExample() {}
+
+// storeOpTypeInfo records a registration, rejecting conflicting duplicates.
+// Re-registering without options, or with identical options, is a no-op so
+// init-time registration stays idempotent. The conflict check and the insert
+// happen under one lock, so of several concurrent conflicting registrations
+// exactly one succeeds.
+func storeOpTypeInfo(key opKey, info opTypeInfo) error {
+	opRegistryMu.Lock()
+	defer opRegistryMu.Unlock()
+
+	if existing, ok := opRegistry[key]; ok {
+		if !info.hasOptions || sameOptions(existing, info) {
+			return nil
+		}
+		if existing.hasOptions {
+			scope := "any service"
+			if key.service != nil {
+				scope = key.service.String()
+			}
+			return fmt.Errorf("plugin: operation %q (Op[%s, %s]) for %s is already registered with examples or capabilities",
+				key.fieldName, key.inputType, key.outputType, scope)
+		}
+	}
+	opRegistry[key] = info
+	return nil
+}
+
+// sameOptions reports whether two registrations carry the same examples and capabilities.
+func sameOptions(a, b opTypeInfo) bool {
+	return reflect.DeepEqual(a.examples, b.examples) && reflect.DeepEqual(a.capabilities, b.capabilities)
+}
+
+// lookupOpTypeInfo returns the registration for an Op field of a service,
+// preferring a service-scoped registration over a RegisterOp one. It returns
+// an error if the field name is registered for the service, or for any
+// service, only with other I/O types, since the registration's examples and
+// capabilities would otherwise be dropped silently.
+func lookupOpTypeInfo(svcType reflect.Type, fieldName string, inputType, outputType reflect.Type) (opTypeInfo, bool, error) {
 	opRegistryMu.RLock()
 	defer opRegistryMu.RUnlock()
 
-	info, ok := opRegistry[fieldName]
-	return info, ok
+	key := opKey{service: svcType, fieldName: fieldName, inputType: inputType, outputType: outputType}
+	if info, ok := opRegistry[key]; ok {
+		return info, true, nil
+	}
+	key.service = nil
+	if info, ok := opRegistry[key]; ok {
+		return info, true, nil
+	}
+
+	var registered []string
+	for k := range opRegistry {
+		if k.fieldName == fieldName && (k.service == nil || k.service == svcType) {
+			registered = append(registered, fmt.Sprintf("Op[%s, %s]", k.inputType, k.outputType))
+		}
+	}
+	if len(registered) > 0 {
+		sort.Strings(registered)
+		return opTypeInfo{}, false, fmt.Errorf("operation %q is registered as %s but the field is Op[%s, %s]",
+			fieldName, strings.Join(registered, ", "), inputType, outputType)
+	}
+	return opTypeInfo{}, false, nil
 }
 
 // clearOpRegistry clears the registry (for testing only).
 func clearOpRegistry() {
 	opRegistryMu.Lock()
 	defer opRegistryMu.Unlock()
-	opRegistry = make(map[string]opTypeInfo)
+	opRegistry = make(map[opKey]opTypeInfo)
 }
@@ -1,9 +1,13 @@
 package plugin
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +36,7 @@ func TestRegisterOp(t *testing.T) {
 		},
 	)
 
-	info, ok := getOpTypeInfo("TestOp")
+	info, ok, _ := lookupOpTypeInfo(nil, "TestOp", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
 	require.True(t, ok)
 
 	assert.Equal(t, reflect.TypeOf(testInput{}), info.inputType)
@@ -43,6 +47,150 @@ func TestRegisterOp(t *testing.T) {
 func TestRegisterOp_NotRegistered(t *testing.T) {
 	clearOpRegistry()
 
-	_, ok := getOpTypeInfo("NonExistent")
+	_, ok, err := lookupOpTypeInfo(nil, "NonExistent", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
+	assert.NoError(t, err)
 	assert.False(t, ok)
 }
+
+func TestRegisterOp_WithCapabilities(t *testing.T) {
+	clearOpRegistry()
+
+	RegisterOp[testInput, testOutput]("TestOp",
+		Example[testInput, testOutput]{Name: "basic", Input: testInput{Name: "test"}},
+		WithCapabilities(entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"systemctl"}},
+		}),
+	)
+
+	info, ok, _ := lookupOpTypeInfo(nil, "TestOp", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
+	require.True(t, ok)
+	assert.Len(t, info.examples, 1)
+	require.NotNil(t, info.capabilities)
+	assert.Equal(t, []string{"systemctl"}, info.capabilities.Exec.Commands)
+}
+
+func TestRegisterOp_MismatchedExamplePanics(t *testing.T) {
+	clearOpRegistry()
+
+	assert.Panics(t, func() {
+		RegisterOp[testInput, testOutput]("TestOp",
+			Example[testOutput, testInput]{Name: "wrong"},
+		)
+	})
+}
+
+type opTestServiceA struct {
+	Service `name:"a"`
+	Check   Op[testInput, testOutput]
+}
+
+type opTestServiceB struct {
+	Service `name:"b"`
+	Check   Op[testOutput, testInput]
+}
+
+func TestRegisterOp_SameFieldNameDifferentTypes(t *testing.T) {
+	clearOpRegistry()
+
+	RegisterOp[testInput, testOutput]("Check",
+		Example[testInput, testOutput]{Name: "a"},
+	)
+	RegisterOp[testOutput, testInput]("Check",
+		Example[testOutput, testInput]{Name: "b"},
+	)
+
+	a, ok, err := lookupOpTypeInfo(reflect.TypeOf(opTestServiceA{}), "Check", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
+	require.NoError(t, err)
+	require.True(t, ok)
+	b, ok, err := lookupOpTypeInfo(reflect.TypeOf(opTestServiceB{}), "Check", reflect.TypeOf(testOutput{}), reflect.TypeOf(testInput{}))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "a", a.examples[0].(Example[testInput, testOutput]).Name)
+	assert.Equal(t, "b", b.examples[0].(Example[testOutput, testInput]).Name)
+}
+
+func TestRegisterService_MismatchedRegistrationFails(t *testing.T) {
+	clearOpRegistry()
+
+	// Registered with the types of opTestServiceA's field, used with B's.
+	RegisterOp[testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "a"})
+
+	def := DefinePlugin(PluginDef{Name: "mismatch"})
+	err := RegisterService(def, &opTestServiceB{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `operation "Check" is registered as Op[plugin.testInput, plugin.testOutput] but the field is Op[plugin.testOutput, plugin.testInput]`)
+	assert.False(t, def.hasOperation("b", "check"))
+}
+
+func TestRegisterOp_ConflictingRegistrationPanics(t *testing.T) {
+	clearOpRegistry()
+
+	RegisterOp[testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "first"})
+	// Identical registration is idempotent
+	RegisterOp[testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "first"})
+
+	assert.PanicsWithValue(t,
+		`plugin: operation "Check" (Op[plugin.testInput, plugin.testOutput]) for any service is already registered with examples or capabilities`,
+		func() {
+			RegisterOp[testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "second"})
+		})
+}
+
+func TestRegisterOp_ConcurrentConflictingRegistrations(t *testing.T) {
+	clearOpRegistry()
+
+	const n = 16
+	var wg sync.WaitGroup
+	var panics atomic.Int32
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if recover() != nil {
+					panics.Add(1)
+				}
+			}()
+			RegisterOp[testInput, testOutput]("Race", Example[testInput, testOutput]{Name: fmt.Sprintf("example-%d", i)})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(n-1), panics.Load(), "exactly one registration wins")
+}
+
+func TestRegisterServiceOp(t *testing.T) {
+	clearOpRegistry()
+
+	RegisterOp[testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "global"})
+	RegisterServiceOp[opTestServiceA, testInput, testOutput]("Check", Example[testInput, testOutput]{Name: "scoped"})
+
+	info, ok, err := lookupOpTypeInfo(reflect.TypeOf(opTestServiceA{}), "Check", reflect.TypeOf(testInput{}), reflect.TypeOf(testOutput{}))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "scoped", info.examples[0].(Example[testInput, testOutput]).Name)
+
+	assert.Panics(t, func() {
+		RegisterServiceOp[opTestServiceA, testInput, testOutput]("Missing")
+	})
+	assert.Panics(t, func() {
+		RegisterServiceOp[opTestServiceB, testInput, testOutput]("Check")
+	})
+}
+
+func TestRegisterService_InvalidOpCapabilities(t *testing.T) {
+	clearOpRegistry()
+
+	RegisterOp[testInput, testOutput]("Check",
+		WithCapabilities(entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"systemctl restart sshd"}},
+		}),
+	)
+
+	def := DefinePlugin(PluginDef{Name: "invalid-op"})
+	err := RegisterService(def, &opTestServiceA{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service a, operation check: invalid capabilities: exec.commands[0]:")
+	assert.False(t, def.hasOperation("a", "check"))
+}
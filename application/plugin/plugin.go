@@ -73,6 +73,48 @@ func _observe(configPtr uint32, configLen uint32) uint64 {
 	})
 }
 
+//go:wasmexport _init
+func _init(configPtr uint32, configLen uint32) uint64 {
+	return handleExportedCall(func() (interface{}, error) {
+		if userPlugin == nil {
+			return nil, fmt.Errorf("plugin not registered")
+		}
+
+		configBytes := abi.BytesFromPtr(abi.PackPtrLen(configPtr, configLen))
+
+		ctx := wasmcontext.GetCurrentContext()
+		wasmcontext.SetCurrentContext(ctx)
+		defer wasmcontext.ResetContext()
+
+		if initializer, ok := userPlugin.(Initializer); ok {
+			if err := initializer.Init(ctx, configBytes); err != nil {
+				return nil, err
+			}
+		}
+		return entities.Result{Status: entities.ResultStatusSuccess, Message: "initialized", Timestamp: time.Now()}, nil
+	})
+}
+
+//go:wasmexport _shutdown
+func _shutdown() uint64 {
+	return handleExportedCall(func() (interface{}, error) {
+		if userPlugin == nil {
+			return nil, fmt.Errorf("plugin not registered")
+		}
+
+		ctx := wasmcontext.GetCurrentContext()
+		wasmcontext.SetCurrentContext(ctx)
+		defer wasmcontext.ResetContext()
+
+		if shutdowner, ok := userPlugin.(Shutdowner); ok {
+			if err := shutdowner.Shutdown(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return entities.Result{Status: entities.ResultStatusSuccess, Message: "shutdown", Timestamp: time.Now()}, nil
+	})
+}
+
 // handleExportedCall is a generic wrapper for WASM exported functions.
 // It provides panic recovery, error handling, and JSON serialization.
 // It ensures that on any error or panic, a structured Evidence with ErrorDetail is returned.
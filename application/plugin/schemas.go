@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/schema"
+)
+
+// schemaCache holds generated JSON Schemas keyed by Go type and use. Schemas
+// are generated once per type and shared by input validation and the manifest.
+var schemaCache sync.Map // map[schemaKey]json.RawMessage
+
+type schemaKey struct {
+	t     reflect.Type
+	input bool
+}
+
+// schemaForType returns the JSON Schema for t, generating it on first use.
+func schemaForType(t reflect.Type) (json.RawMessage, error) {
+	return cachedSchema(schemaKey{t: t}, schema.GenerateSchema)
+}
+
+// inputSchemaForType returns the lenient operation input schema for t, as
+// generated by schema.GenerateInputSchema.
+func inputSchemaForType(t reflect.Type) (json.RawMessage, error) {
+	return cachedSchema(schemaKey{t: t, input: true}, schema.GenerateInputSchema)
+}
+
+func cachedSchema(key schemaKey, generate func(interface{}) ([]byte, error)) (json.RawMessage, error) {
+	if cached, ok := schemaCache.Load(key); ok {
+		return cached.(json.RawMessage), nil
+	}
+
+	generated, err := generate(reflect.New(key.t).Elem().Interface())
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := schemaCache.LoadOrStore(key, json.RawMessage(generated))
+	return actual.(json.RawMessage), nil
+}
@@ -3,12 +3,15 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	stdErrors "errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 
+	"github.com/reglet-dev/reglet-plugin-sdk/application/schema"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
 )
 
 // Service is embedded in service structs to provide metadata.
@@ -57,6 +60,22 @@ func RegisterService(plugin *PluginDefinition, svc interface{}) error {
 		return err
 	}
 
+	// Reject operations that collide with ones already registered on this plugin
+	for _, op := range ops {
+		if plugin.hasOperation(serviceName, op.name) {
+			return fmt.Errorf("service %s: operation %s (field %s) is already registered",
+				serviceName, op.name, op.fieldName)
+		}
+	}
+
+	// Reject malformed capability declarations before anything is registered
+	for _, op := range ops {
+		if res := entities.ValidateGrantSet(op.capabilities); !res.Valid {
+			return fmt.Errorf("service %s, operation %s: invalid capabilities: %s",
+				serviceName, op.name, formatViolations(res.Errors))
+		}
+	}
+
 	// Match operations to methods and register
 	for _, op := range ops {
 		method := svcValue.MethodByName(op.methodName)
@@ -81,14 +100,15 @@ func RegisterService(plugin *PluginDefinition, svc interface{}) error {
 				serviceName, op.name, wrapErr)
 		}
 
-		plugin.RegisterHandler(
-			serviceName, serviceDesc,
-			op.name, op.description,
-			handler,
-			op.inputType,
-			op.outputType,
-			op.examples,
-		)
+		plugin.registerOperation(serviceName, serviceDesc, &operationEntry{
+			name:         op.name,
+			description:  op.description,
+			handler:      handler,
+			inputType:    op.inputType,
+			outputType:   op.outputType,
+			examples:     op.examples,
+			capabilities: op.capabilities,
+		})
 	}
 
 	return nil
@@ -113,14 +133,15 @@ func extractServiceMetadata(t reflect.Type) (name, desc string, err error) {
 
 // opInfo holds operation metadata extracted from struct fields.
 type opInfo struct {
-	fieldName   string // PascalCase field name
-	methodName  string // Method name to invoke
-	name        string // snake_case operation name
-	description string
-	isTyped     bool         // true if Op[I,O], false if legacy Op
-	inputType   reflect.Type // nil for legacy
-	outputType  reflect.Type // nil for legacy
-	examples    []any        // nil for legacy
+	fieldName    string // PascalCase field name
+	methodName   string // Method name to invoke
+	name         string // snake_case operation name
+	description  string
+	isTyped      bool               // true if Op[I,O], false if legacy Op
+	inputType    reflect.Type       // nil for legacy
+	outputType   reflect.Type       // nil for legacy
+	examples     []any              // nil for legacy
+	capabilities *entities.GrantSet // nil if the op declares none
 }
 
 // extractOperations finds all Op fields and extracts their metadata.
@@ -150,17 +171,20 @@ func extractOperations(t reflect.Type) ([]opInfo, error) {
 
 		// Check if typed (Op[I,O]) or legacy (Op)
 		if isTypedOp(field.Type) {
-			typeInfo, ok := getOpTypeInfo(field.Name)
-			if !ok {
-				// Require registration for typed ops
-				return nil, fmt.Errorf(
-					"Op field %s not registered - call plugin.RegisterOp[I,O](%q, ...) in init() before MustRegisterService",
-					field.Name, field.Name)
-			}
+			// I/O types come from the field type itself; registrations only
+			// contribute examples and capabilities.
+			opValue := reflect.Zero(field.Type).Interface().(operation)
 			op.isTyped = true
-			op.inputType = typeInfo.inputType
-			op.outputType = typeInfo.outputType
-			op.examples = typeInfo.examples
+			op.inputType, op.outputType = opValue.opTypes()
+
+			typeInfo, ok, err := lookupOpTypeInfo(t, field.Name, op.inputType, op.outputType)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				op.examples = typeInfo.examples
+				op.capabilities = typeInfo.capabilities
+			}
 		}
 
 		ops = append(ops, op)
@@ -218,37 +242,75 @@ func wrapTypedMethod(method reflect.Value, inputType, outputType reflect.Type) (
 		return nil, fmt.Errorf("second return must be error")
 	}
 
+	// Compile the input schema once so every invocation is validated against
+	// the same schema that is published in the manifest.
+	inputSchema, err := inputSchemaForType(inputType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate input schema: %w", err)
+	}
+	validator, err := schema.NewValidator(inputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile input schema: %w", err)
+	}
+
 	return func(ctx context.Context, req *Request) (*entities.Result, error) {
-		// 1. Inject client into context
+		// 1. Inject client and plugin config into context
 		ctx = WithClient(ctx, req.Client)
+		if req.Config != nil {
+			ctx = WithConfig(ctx, req.Config)
+		}
 
-		// 2. Parse config JSON into input type
+		// 2. Validate input against the schema and apply declared defaults
+		normalized, validation, err := validator.ValidateJSON(req.Raw)
+		if err != nil {
+			return entities.ResultErrorPtr("config", fmt.Sprintf("failed to parse config: %v", err)), nil
+		}
+		if !validation.Valid {
+			return resultFromError(errors.NewValidationConfigError(validation)), nil
+		}
+
+		// 3. Parse config JSON into input type
 		inputPtr := reflect.New(inputType)
-		if len(req.Raw) > 0 {
-			if err := json.Unmarshal(req.Raw, inputPtr.Interface()); err != nil {
-				return entities.ResultErrorPtr("config", fmt.Sprintf("failed to parse config: %v", err)), nil
-			}
+		if err := json.Unmarshal(normalized, inputPtr.Interface()); err != nil {
+			return entities.ResultErrorPtr("config", fmt.Sprintf("failed to parse config: %v", err)), nil
 		}
 
-		// 3. Call the typed handler
+		// 4. Call the typed handler
 		args := []reflect.Value{
 			reflect.ValueOf(ctx),
 			inputPtr,
 		}
 		results := method.Call(args)
 
-		// 4. Handle error return
+		// 5. Handle error return: a Failure is a compliance result, anything
+		// else keeps its structured ErrorDetail (type, code, timeout, details).
 		if !results[1].IsNil() {
 			err := results[1].Interface().(error)
-			return entities.ResultErrorPtr("execution", err.Error()), nil
+
+			var failure *Failure
+			if stdErrors.As(err, &failure) {
+				output := failure.Output
+				if output == nil && !results[0].IsNil() {
+					output = results[0].Interface()
+				}
+				var data map[string]any
+				if output != nil {
+					if data, err = structToMap(output); err != nil {
+						return entities.ResultErrorPtr("output", fmt.Sprintf("failed to serialize output: %v", err)), nil
+					}
+				}
+				return entities.ResultFailurePtr(failure.Message, data), nil
+			}
+
+			return resultFromError(err), nil
 		}
 
-		// 5. Handle nil output
+		// 6. Handle nil output
 		if results[0].IsNil() {
 			return entities.ResultSuccessPtr("ok", nil), nil
 		}
 
-		// 6. Convert output struct to map[string]any for Result.Data
+		// 7. Convert output struct to map[string]any for Result.Data
 		output := results[0].Interface()
 		data, err := structToMap(output)
 		if err != nil {
@@ -2,10 +2,13 @@ package plugin_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	sdkerrors "github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,7 +64,8 @@ func TestServiceRegistration(t *testing.T) {
 	require.NoError(t, err)
 
 	// 3. Generate Manifest
-	manifest := def.Manifest()
+	manifest, err := def.Manifest(context.Background())
+	require.NoError(t, err)
 	assert.NotNil(t, manifest)
 
 	// Verify Service Manifest
@@ -100,3 +104,406 @@ func TestServiceRegistration(t *testing.T) {
 	// Result.Data is map[string]any
 	assert.Equal(t, "hello", res.Data["reply"])
 }
+
+func TestPluginDefinition_Check(t *testing.T) {
+	plugin.RegisterOp[EchoRequest, EchoResponse]("EchoOp")
+	plugin.RegisterOp[AddRequest, AddResponse]("AddOp")
+
+	def := plugin.DefinePlugin(plugin.PluginDef{
+		Name:    "test-plugin",
+		Version: "1.0.0",
+	})
+	require.NoError(t, plugin.RegisterService(def, &TestService{}))
+
+	// PluginDefinition can be registered directly as a Plugin
+	var _ plugin.Plugin = def
+
+	t.Run("dispatches to operation", func(t *testing.T) {
+		res, err := def.Check(context.Background(),
+			[]byte(`{"service":"test_service","operation":"add_op","input":{"a":2,"b":3}}`))
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusSuccess, res.Status)
+		assert.EqualValues(t, 5, res.Data["sum"])
+	})
+
+	t.Run("defaults unambiguous service", func(t *testing.T) {
+		res, err := def.Check(context.Background(),
+			[]byte(`{"operation":"echo_op","input":{"message":"hi"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "hi", res.Data["reply"])
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		res, err := def.Check(context.Background(), []byte(`{"service":"nope","operation":"echo_op"}`))
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, "unknown_service", res.Error.Code)
+		assert.True(t, res.Error.IsNotFound)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		res, err := def.Check(context.Background(), []byte(`{"service":"test_service","operation":"nope"}`))
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, "unknown_operation", res.Error.Code)
+		assert.Contains(t, res.Error.Message, "echo_op")
+	})
+
+	t.Run("malformed envelope", func(t *testing.T) {
+		res, err := def.Check(context.Background(), []byte(`not json`))
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		assert.Equal(t, "wire_format", res.Error.Code)
+	})
+}
+
+type ValidatedService struct {
+	plugin.Service `name:"validated" desc:"Validated service"`
+	FetchOp        plugin.Op[FetchRequest, FetchResponse] `desc:"Fetch a URL" method:"Fetch"`
+}
+
+type FetchRequest struct {
+	URL    string `json:"url" jsonschema:"required"`
+	Method string `json:"method,omitempty" jsonschema:"enum=GET,enum=HEAD,default=GET"`
+}
+
+type FetchResponse struct {
+	Method string `json:"method"`
+}
+
+func (s *ValidatedService) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse, error) {
+	return &FetchResponse{Method: req.Method}, nil
+}
+
+func TestTypedHandler_InputValidation(t *testing.T) {
+	plugin.RegisterOp[FetchRequest, FetchResponse]("FetchOp")
+
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "validated", Version: "1.0.0"})
+	require.NoError(t, plugin.RegisterService(def, &ValidatedService{}))
+
+	handler, ok := def.GetHandler("validated", "fetch_op")
+	require.True(t, ok)
+
+	t.Run("defaults applied", func(t *testing.T) {
+		res, err := handler(context.Background(), &plugin.Request{Raw: []byte(`{"url":"https://example.com"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusSuccess, res.Status)
+		assert.Equal(t, "GET", res.Data["method"])
+	})
+
+	t.Run("unknown fields allowed", func(t *testing.T) {
+		res, err := handler(context.Background(), &plugin.Request{Raw: []byte(`{"url":"https://example.com","extra":true}`)})
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusSuccess, res.Status, res.Error)
+	})
+
+	t.Run("violations reported before handler runs", func(t *testing.T) {
+		res, err := handler(context.Background(), &plugin.Request{Raw: []byte(`{"method":"PUT"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, "config", res.Error.Type)
+		assert.Contains(t, res.Error.Message, "url: is required")
+		assert.Contains(t, res.Error.Message, "method: must be one of")
+		assert.Len(t, res.Error.Details["violations"], 2)
+	})
+}
+
+type OpsService struct {
+	plugin.Service `name:"ops" desc:"Operations with their own capabilities"`
+	StatusOp       plugin.Op[EchoRequest, EchoResponse] `desc:"Read status" method:"Status"`
+	RestartOp      plugin.Op[EchoRequest, EchoResponse] `desc:"Restart a unit" method:"Restart"`
+}
+
+func (s *OpsService) Status(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	return &EchoResponse{Reply: req.Message}, nil
+}
+
+func (s *OpsService) Restart(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	return &EchoResponse{Reply: req.Message}, nil
+}
+
+func TestManifest_PerOperationCapabilities(t *testing.T) {
+	plugin.RegisterOp[EchoRequest, EchoResponse]("StatusOp")
+	plugin.RegisterOp[EchoRequest, EchoResponse]("RestartOp",
+		plugin.WithCapabilities(entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"systemctl"}},
+		}),
+	)
+
+	def := plugin.DefinePlugin(plugin.PluginDef{
+		Name:    "ops",
+		Version: "1.0.0",
+		Capabilities: entities.GrantSet{
+			Network: &entities.NetworkCapability{
+				Rules: []entities.NetworkRule{{Hosts: []string{"localhost"}, Ports: []string{"8080"}}},
+			},
+		},
+	})
+	require.NoError(t, plugin.RegisterService(def, &OpsService{}))
+
+	manifest, err := def.Manifest(context.Background())
+	require.NoError(t, err)
+
+	ops := map[string]entities.OperationManifest{}
+	for _, op := range manifest.Services["ops"].Operations {
+		ops[op.Name] = op
+	}
+	assert.Nil(t, ops["status_op"].Capabilities)
+	require.NotNil(t, ops["restart_op"].Capabilities)
+	assert.Equal(t, []string{"systemctl"}, ops["restart_op"].Capabilities.Exec.Commands)
+
+	// Plugin-level capabilities are the merge of plugin-wide and per-op grants
+	require.NotNil(t, manifest.Capabilities.Network)
+	require.NotNil(t, manifest.Capabilities.Exec)
+	assert.Equal(t, []string{"systemctl"}, manifest.Capabilities.Exec.Commands)
+}
+
+type OutcomeService struct {
+	plugin.Service `name:"outcome" desc:"Reports different outcomes"`
+	EvaluateOp     plugin.Op[EvaluateRequest, EvaluateResponse] `desc:"Evaluate" method:"Evaluate"`
+}
+
+type EvaluateRequest struct {
+	Mode string `json:"mode"`
+}
+
+type EvaluateResponse struct {
+	DaysLeft int `json:"days_left"`
+}
+
+func (s *OutcomeService) Evaluate(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	switch req.Mode {
+	case "fail":
+		return nil, plugin.Fail("certificate expires too soon", &EvaluateResponse{DaysLeft: 3})
+	case "fail_with_output":
+		return &EvaluateResponse{DaysLeft: 5}, plugin.Fail("certificate expires too soon", nil)
+	case "timeout":
+		return nil, fmt.Errorf("probe failed: %w", &sdkerrors.TimeoutError{Operation: "tls_handshake", Duration: time.Second})
+	case "denied":
+		return nil, &sdkerrors.CapabilityError{Required: "network:outbound", Pattern: "example.com:443"}
+	case "plain":
+		return nil, fmt.Errorf("certificate not found")
+	}
+	return &EvaluateResponse{DaysLeft: 90}, nil
+}
+
+func TestTypedHandler_Outcomes(t *testing.T) {
+	plugin.RegisterOp[EvaluateRequest, EvaluateResponse]("EvaluateOp")
+
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "outcome", Version: "1.0.0"})
+	require.NoError(t, plugin.RegisterService(def, &OutcomeService{}))
+
+	handler, ok := def.GetHandler("outcome", "evaluate_op")
+	require.True(t, ok)
+
+	run := func(mode string) *entities.Result {
+		res, err := handler(context.Background(), &plugin.Request{Raw: []byte(`{"mode":"` + mode + `"}`)})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("failure carries output", func(t *testing.T) {
+		res := run("fail")
+		assert.Equal(t, entities.ResultStatusFailure, res.Status)
+		assert.Equal(t, "certificate expires too soon", res.Message)
+		assert.EqualValues(t, 3, res.Data["days_left"])
+	})
+
+	t.Run("failure uses returned output", func(t *testing.T) {
+		res := run("fail_with_output")
+		assert.Equal(t, entities.ResultStatusFailure, res.Status)
+		assert.EqualValues(t, 5, res.Data["days_left"])
+	})
+
+	t.Run("structured timeout preserved", func(t *testing.T) {
+		res := run("timeout")
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		assert.Equal(t, "timeout", res.Error.Type)
+		assert.Equal(t, "tls_handshake", res.Error.Code)
+		assert.True(t, res.Error.IsTimeout)
+	})
+
+	t.Run("capability denial preserved", func(t *testing.T) {
+		res := run("denied")
+		assert.Equal(t, "capability", res.Error.Type)
+		assert.Equal(t, "network:outbound", res.Error.Code)
+	})
+
+	t.Run("plain error is an execution error", func(t *testing.T) {
+		res := run("plain")
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		assert.Equal(t, "execution", res.Error.Type)
+		assert.Equal(t, "certificate not found", res.Error.Message)
+	})
+}
+
+type UnregisteredService struct {
+	plugin.Service `name:"unregistered" desc:"No RegisterOp calls"`
+	PingOp         plugin.Op[EchoRequest, EchoResponse] `desc:"Ping" method:"Ping"`
+}
+
+func (s *UnregisteredService) Ping(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	return &EchoResponse{Reply: "pong"}, nil
+}
+
+func TestRegisterService_WithoutRegisterOp(t *testing.T) {
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "unregistered", Version: "1.0.0"})
+	require.NoError(t, plugin.RegisterService(def, &UnregisteredService{}))
+
+	res, err := def.Check(context.Background(), []byte(`{"input":{"message":"x"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "pong", res.Data["reply"])
+
+	// Registering the same service twice is a collision
+	err = plugin.RegisterService(def, &UnregisteredService{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation ping_op (field PingOp) is already registered")
+}
+
+type GreetConfig struct {
+	Greeting string `json:"greeting,omitempty" jsonschema:"default=hello"`
+}
+
+type greeter struct {
+	prefix string
+	closed bool
+}
+
+func (g *greeter) Close() error {
+	g.closed = true
+	return nil
+}
+
+type GreetService struct {
+	plugin.Service `name:"greet" desc:"Greets"`
+	GreetOp        plugin.Op[EchoRequest, EchoResponse] `desc:"Greets the caller" method:"Greet"`
+}
+
+func (s *GreetService) Greet(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	g := plugin.GetClient[*greeter](ctx)
+	cfg := plugin.GetConfig[*GreetConfig](ctx)
+	return &EchoResponse{Reply: fmt.Sprintf("%s/%s %s", g.prefix, cfg.Greeting, req.Message)}, nil
+}
+
+func TestPluginDefinition_ClientFactory(t *testing.T) {
+	invocation := []byte(`{"service":"greet","operation":"greet_op","input":{"message":"bob"}}`)
+
+	newDef := func(perInvocation bool, built *[]*greeter) *plugin.PluginDefinition {
+		def := plugin.DefinePlugin(plugin.PluginDef{
+			Name:   "greeter",
+			Config: &GreetConfig{},
+			Client: plugin.NewClientFactory(func(ctx context.Context, cfg *GreetConfig) (*greeter, error) {
+				g := &greeter{prefix: fmt.Sprintf("client%d", len(*built)+1)}
+				*built = append(*built, g)
+				return g, nil
+			}),
+			ClientPerInvocation: perInvocation,
+		})
+		require.NoError(t, plugin.RegisterService(def, &GreetService{}))
+		return def
+	}
+
+	t.Run("shared client built at init", func(t *testing.T) {
+		var built []*greeter
+		def := newDef(false, &built)
+		require.NoError(t, def.Init(context.Background(), []byte(`{"greeting":"hi"}`)))
+		require.Len(t, built, 1)
+
+		for i := 0; i < 2; i++ {
+			res, err := def.Check(context.Background(), invocation)
+			require.NoError(t, err)
+			assert.Equal(t, "client1/hi bob", res.Data["reply"])
+		}
+		assert.Len(t, built, 1)
+
+		require.NoError(t, def.Shutdown(context.Background()))
+		assert.True(t, built[0].closed)
+	})
+
+	t.Run("without init applies config defaults", func(t *testing.T) {
+		var built []*greeter
+		def := newDef(false, &built)
+		res, err := def.Check(context.Background(), invocation)
+		require.NoError(t, err)
+		assert.Equal(t, "client1/hello bob", res.Data["reply"])
+	})
+
+	t.Run("per invocation clients are closed", func(t *testing.T) {
+		var built []*greeter
+		def := newDef(true, &built)
+		require.NoError(t, def.Init(context.Background(), nil))
+		assert.Empty(t, built)
+
+		_, err := def.Check(context.Background(), invocation)
+		require.NoError(t, err)
+		res, err := def.Check(context.Background(), invocation)
+		require.NoError(t, err)
+		assert.Equal(t, "client2/hello bob", res.Data["reply"])
+		require.Len(t, built, 2)
+		assert.True(t, built[0].closed)
+		assert.True(t, built[1].closed)
+	})
+
+	t.Run("context client overrides factory", func(t *testing.T) {
+		var built []*greeter
+		def := newDef(false, &built)
+		ctx := plugin.WithClient(context.Background(), &greeter{prefix: "fake"})
+		res, err := def.Check(ctx, invocation)
+		require.NoError(t, err)
+		assert.Equal(t, "fake/hello bob", res.Data["reply"])
+		assert.Empty(t, built)
+	})
+
+	t.Run("factory error", func(t *testing.T) {
+		def := plugin.DefinePlugin(plugin.PluginDef{
+			Name: "greeter",
+			Client: plugin.NewClientFactory(func(ctx context.Context, cfg *GreetConfig) (*greeter, error) {
+				return nil, &sdkerrors.NetworkError{Operation: "dial", Target: "greeter"}
+			}),
+		})
+		require.NoError(t, plugin.RegisterService(def, &GreetService{}))
+
+		err := def.Init(context.Background(), nil)
+		var netErr *sdkerrors.NetworkError
+		require.ErrorAs(t, err, &netErr)
+
+		res, err := def.Check(context.Background(), invocation)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ResultStatusError, res.Status)
+		assert.Equal(t, "network", res.Error.Type)
+	})
+}
+
+type ContextService struct {
+	plugin.Service `name:"ctx" desc:"Reports the invocation context"`
+	InspectOp      plugin.Op[EchoRequest, EchoResponse] `desc:"Reports the request ID and deadline" method:"Inspect"`
+}
+
+func (s *ContextService) Inspect(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	if err := plugin.CheckCanceled(ctx); err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	return &EchoResponse{Reply: fmt.Sprintf("%s %v %s", plugin.RequestID(ctx), ok, deadline.UTC().Format(time.RFC3339))}, nil
+}
+
+func TestPluginDefinition_CheckAppliesHostContext(t *testing.T) {
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "ctx"})
+	require.NoError(t, plugin.RegisterService(def, &ContextService{}))
+
+	res, err := def.Check(context.Background(), []byte(`{"operation":"inspect_op","input":{"message":"hi"},
+		"context":{"request_id":"req-1","deadline":"2999-01-02T03:04:05Z"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "req-1 true 2999-01-02T03:04:05Z", res.Data["reply"])
+
+	res, err = def.Check(context.Background(), []byte(`{"operation":"inspect_op","input":{"message":"hi"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, " false 0001-01-01T00:00:00Z", res.Data["reply"])
+
+	res, err = def.Check(context.Background(), []byte(`{"operation":"inspect_op","input":{"message":"hi"},"context":{"canceled":true}}`))
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResultStatusError, res.Status)
+}
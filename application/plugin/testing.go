@@ -3,6 +3,7 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -23,7 +24,7 @@ import (
 func GenerateExampleTests(t *testing.T, plugin *PluginDefinition, mockClient any) {
 	t.Helper()
 
-	manifest := plugin.Manifest()
+	manifest := plugin.buildManifest()
 
 	for svcName, svc := range manifest.Services {
 		for _, op := range svc.Operations {
@@ -38,6 +39,54 @@ func GenerateExampleTests(t *testing.T, plugin *PluginDefinition, mockClient any
 	}
 }
 
+// ExampleResult is the outcome of running one registered example.
+type ExampleResult struct {
+	// Err is nil if the example passed.
+	Err       error
+	Service   string
+	Operation string
+	Example   string
+}
+
+// RunExamples executes every registered example against the plugin's
+// handlers, like GenerateExampleTests but without a *testing.T, so tools can
+// report the outcome themselves. Results are ordered by service, operation
+// and registration order.
+func RunExamples(ctx context.Context, plugin *PluginDefinition, client any) []ExampleResult {
+	manifest := plugin.buildManifest()
+
+	var results []ExampleResult
+	for _, svcName := range sortedKeys(manifest.Services) {
+		for _, op := range manifest.Services[svcName].Operations {
+			for _, ex := range op.Examples {
+				results = append(results, ExampleResult{
+					Service:   svcName,
+					Operation: op.Name,
+					Example:   ex.Name,
+					Err:       runExampleRecovered(ctx, plugin, svcName, op.Name, ex, client),
+				})
+			}
+		}
+	}
+	return results
+}
+
+// runExampleRecovered runs an example, reporting a handler panic as an error.
+func runExampleRecovered(
+	ctx context.Context,
+	plugin *PluginDefinition,
+	svcName, opName string,
+	ex entities.OperationExample,
+	client any,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return runExample(ctx, plugin, svcName, opName, ex, client)
+}
+
 // runExampleTest executes a single example test.
 func runExampleTest(
 	t *testing.T,
@@ -48,78 +97,103 @@ func runExampleTest(
 ) {
 	t.Helper()
 
+	if err := runExample(context.Background(), plugin, svcName, opName, ex, mockClient); err != nil {
+		t.Error(err)
+	}
+}
+
+// runExample executes a single example and returns an error describing any
+// mismatch with its expected outcome.
+func runExample(
+	ctx context.Context,
+	plugin *PluginDefinition,
+	svcName, opName string,
+	ex entities.OperationExample,
+	mockClient any,
+) error {
 	// Get handler
 	handler, ok := plugin.GetHandler(svcName, opName)
 	if !ok {
-		t.Fatalf("handler not found: %s/%s", svcName, opName)
+		return fmt.Errorf("handler not found: %s/%s", svcName, opName)
+	}
+
+	config, err := plugin.Config()
+	if err != nil {
+		return fmt.Errorf("plugin config: %w", err)
 	}
 
 	// Build request from example input
 	req := &Request{
 		Client: mockClient,
+		Config: config,
 		Raw:    ex.Input,
 	}
 
 	// Execute handler
-	result, err := handler(context.Background(), req)
+	result, err := handler(ctx, req)
 
 	// Check expected error case
 	if ex.ExpectedError != "" {
 		if err != nil {
 			if !strings.Contains(err.Error(), ex.ExpectedError) {
-				t.Errorf("expected error containing %q, got %q", ex.ExpectedError, err.Error())
+				return fmt.Errorf("expected error containing %q, got %q", ex.ExpectedError, err.Error())
 			}
-			return
+			return nil
 		}
 		// Error might be in Result.Error
 		if result != nil && result.Status == entities.ResultStatusError {
 			if result.Error != nil && strings.Contains(result.Error.Message, ex.ExpectedError) {
-				return // Expected error found
+				return nil // Expected error found
 			}
 		}
-		t.Errorf("expected error containing %q, got success", ex.ExpectedError)
-		return
+		return fmt.Errorf("expected error containing %q, got success", ex.ExpectedError)
 	}
 
 	// Check success case
 	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+		return fmt.Errorf("unexpected error: %w", err)
 	}
 	if result == nil {
-		t.Fatal("result is nil")
+		return errors.New("result is nil")
 	}
 	if result.Status == entities.ResultStatusError {
-		t.Fatalf("unexpected error result: %s", result.Error.Message)
+		msg := result.Message
+		if result.Error != nil {
+			msg = result.Error.Message
+		}
+		return fmt.Errorf("unexpected error result: %s", msg)
 	}
 
 	// If expected output provided, verify it matches
 	if len(ex.ExpectedOutput) > 0 {
 		var expected map[string]any
 		if err := json.Unmarshal(ex.ExpectedOutput, &expected); err != nil {
-			t.Fatalf("failed to parse expected output: %v", err)
+			return fmt.Errorf("failed to parse expected output: %w", err)
 		}
 
-		verifyOutput(t, expected, result.Data)
+		return verifyOutput(expected, result.Data)
 	}
+	return nil
 }
 
 // verifyOutput compares expected fields against actual data.
 // Only checks fields present in expected (allows extra fields in actual).
-func verifyOutput(t *testing.T, expected, actual map[string]any) {
-	t.Helper()
-
-	for key, expectedVal := range expected {
+func verifyOutput(expected, actual map[string]any) error {
+	var errs []error
+	for _, key := range sortedKeys(expected) {
+		expectedVal := expected[key]
 		actualVal, ok := actual[key]
 		if !ok {
-			t.Errorf("missing field %q in output", key)
+			errs = append(errs, fmt.Errorf("missing field %q in output", key))
 			continue
 		}
 
 		if !deepEqual(expectedVal, actualVal) {
-			t.Errorf("field %q: expected %v (%T), got %v (%T)",
-				key, expectedVal, expectedVal, actualVal, actualVal)
+			errs = append(errs, fmt.Errorf("field %q: expected %v (%T), got %v (%T)",
+				key, expectedVal, expectedVal, actualVal, actualVal))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 // deepEqual compares two values, handling JSON number conversions.
@@ -199,7 +273,7 @@ func GenerateExampleTestsWithConfig(
 ) {
 	t.Helper()
 
-	manifest := plugin.Manifest()
+	manifest := plugin.buildManifest()
 	skipSet := make(map[string]bool)
 	for _, name := range config.SkipExamples {
 		skipSet[name] = true
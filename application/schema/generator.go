@@ -12,9 +12,24 @@ import (
 // It uses the `invopop/jsonschema` library to reflect on the struct
 // and generate a standard JSON Schema (Draft 2020-12).
 func GenerateSchema(v interface{}) ([]byte, error) {
-	reflector := jsonschema.Reflector{
+	return generate(&jsonschema.Reflector{
 		ExpandedStruct: true, // Expand struct definitions inline
-	}
+	}, v)
+}
+
+// GenerateInputSchema is GenerateSchema for operation input. It is lenient so
+// that input accepted before validation was added still validates: only
+// fields tagged `jsonschema:"required"` are required, and unknown fields are
+// allowed and dropped when the input is decoded.
+func GenerateInputSchema(v interface{}) ([]byte, error) {
+	return generate(&jsonschema.Reflector{
+		ExpandedStruct:             true,
+		RequiredFromJSONSchemaTags: true,
+		AllowAdditionalProperties:  true,
+	}, v)
+}
+
+func generate(reflector *jsonschema.Reflector, v interface{}) ([]byte, error) {
 	schema := reflector.Reflect(v)
 
 	jsonBytes, err := json.MarshalIndent(schema, "", "  ")
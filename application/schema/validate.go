@@ -0,0 +1,564 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+)
+
+// Validator checks JSON documents against a schema produced by GenerateSchema.
+// It supports the subset of JSON Schema emitted by the reflector: type, enum,
+// const, required, properties, additionalProperties, items, $ref into $defs,
+// allOf/anyOf/oneOf, string/number/array bounds, pattern and date-time format.
+// A Validator is safe for concurrent use.
+type Validator struct {
+	root map[string]any
+	// patterns holds every pattern in the schema, compiled when the
+	// Validator is built so that validation never writes to it.
+	patterns map[string]compiledPattern
+}
+
+type compiledPattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// NewValidator parses a JSON schema document into a Validator.
+func NewValidator(schemaJSON []byte) (*Validator, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schemaJSON, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	v := &Validator{
+		root:     root,
+		patterns: make(map[string]compiledPattern),
+	}
+	v.compilePatterns(root)
+	return v, nil
+}
+
+// NewValidatorFor generates a schema for v and returns a Validator for it.
+func NewValidatorFor(v interface{}) (*Validator, error) {
+	schemaJSON, err := GenerateSchema(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidator(schemaJSON)
+}
+
+// Validate fills in declared defaults for absent object properties and checks
+// doc against the schema. Every violation is reported, not just the first.
+// doc must be the result of decoding JSON into an interface{} value, with or
+// without json.Decoder.UseNumber; objects are modified in place when defaults
+// are applied.
+func (v *Validator) Validate(doc any) entities.ValidationResult {
+	var errs []entities.ValidationError
+	v.validate("", v.root, doc, &errs)
+	return entities.ValidationResult{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+	}
+}
+
+// ValidateJSON decodes data, applies defaults and validates it.
+// Empty input is treated as an empty object. The returned bytes contain the
+// document with defaults applied and are only meaningful when the result is
+// valid. Numbers keep their exact text, so integers beyond float64 precision
+// reach the handler unchanged.
+func (v *Validator) ValidateJSON(data []byte) ([]byte, entities.ValidationResult, error) {
+	var doc any = map[string]any{}
+	if len(data) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, entities.ValidationResult{}, err
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			return nil, entities.ValidationResult{}, fmt.Errorf("unexpected data after top-level value")
+		}
+	}
+
+	result := v.Validate(doc)
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, result, err
+	}
+	return normalized, result, nil
+}
+
+func (v *Validator) validate(path string, schema any, value any, errs *[]entities.ValidationError) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.addError(errs, path, "value is not allowed")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(path, s, value, errs)
+	}
+}
+
+func (v *Validator) validateObjectSchema(path string, s map[string]any, value any, errs *[]entities.ValidationError) {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			v.addError(errs, path, err.Error())
+			return
+		}
+		v.validate(path, target, value, errs)
+	}
+
+	if !v.checkType(path, s, value, errs) {
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok && !containsValue(enum, value) {
+		v.addError(errs, path, fmt.Sprintf("must be one of %s", formatValues(enum)))
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, value) {
+		v.addError(errs, path, fmt.Sprintf("must be %s", formatValue(c)))
+	}
+
+	v.validateCombinators(path, s, value, errs)
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(path, s, val, errs)
+	case []any:
+		v.validateArray(path, s, val, errs)
+	case string:
+		v.validateString(path, s, val, errs)
+	case float64, json.Number:
+		if n, ok := numberValue(val); ok {
+			v.validateNumber(path, s, n, errs)
+		}
+	}
+}
+
+// checkType reports a type mismatch and returns false if further checks
+// against this schema would be meaningless.
+func (v *Validator) checkType(path string, s map[string]any, value any, errs *[]entities.ValidationError) bool {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, item := range t {
+			if str, ok := item.(string); ok {
+				types = append(types, str)
+			}
+		}
+	default:
+		return true
+	}
+
+	for _, t := range types {
+		if matchesType(t, value) {
+			return true
+		}
+	}
+	v.addError(errs, path, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value)))
+	return false
+}
+
+func (v *Validator) validateCombinators(path string, s map[string]any, value any, errs *[]entities.ValidationError) {
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(path, sub, value, errs)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok && v.countMatches(path, anyOf, value) == 0 {
+		v.addError(errs, path, "does not match any allowed schema")
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		if n := v.countMatches(path, oneOf, value); n != 1 {
+			v.addError(errs, path, fmt.Sprintf("must match exactly one schema, matched %d", n))
+		}
+	}
+}
+
+// countMatches returns how many of the schemas accept value without side effects.
+func (v *Validator) countMatches(path string, schemas []any, value any) int {
+	n := 0
+	for _, sub := range schemas {
+		var subErrs []entities.ValidationError
+		v.validate(path, sub, deepCopy(value), &subErrs)
+		if len(subErrs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *Validator) validateObject(path string, s map[string]any, obj map[string]any, errs *[]entities.ValidationError) {
+	props, _ := s["properties"].(map[string]any)
+
+	// Apply defaults before checking required so a defaulted field satisfies it.
+	for _, name := range sortedKeys(props) {
+		propSchema, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, present := obj[name]; present {
+			continue
+		}
+		if def, ok := v.defaultFor(propSchema); ok {
+			obj[name] = deepCopy(def)
+		}
+	}
+
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				v.addError(errs, joinPath(path, name), "is required")
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(obj) {
+		fieldPath := joinPath(path, name)
+		if propSchema, ok := props[name]; ok {
+			v.validate(fieldPath, propSchema, obj[name], errs)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addError(errs, fieldPath, "unknown field")
+			}
+		case map[string]any:
+			v.validate(fieldPath, additional, obj[name], errs)
+		}
+	}
+}
+
+func (v *Validator) validateArray(path string, s map[string]any, arr []any, errs *[]entities.ValidationError) {
+	if n, ok := number(s["minItems"]); ok && float64(len(arr)) < n {
+		v.addError(errs, path, fmt.Sprintf("must have at least %v items", n))
+	}
+	if n, ok := number(s["maxItems"]); ok && float64(len(arr)) > n {
+		v.addError(errs, path, fmt.Sprintf("must have at most %v items", n))
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.addError(errs, indexPath(path, j), "duplicate item")
+				}
+			}
+		}
+	}
+	if items, ok := s["items"]; ok {
+		for i, item := range arr {
+			v.validate(indexPath(path, i), items, item, errs)
+		}
+	}
+}
+
+func (v *Validator) validateString(path string, s map[string]any, str string, errs *[]entities.ValidationError) {
+	length := float64(len([]rune(str)))
+	if n, ok := number(s["minLength"]); ok && length < n {
+		v.addError(errs, path, fmt.Sprintf("must be at least %v characters", n))
+	}
+	if n, ok := number(s["maxLength"]); ok && length > n {
+		v.addError(errs, path, fmt.Sprintf("must be at most %v characters", n))
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.pattern(pattern)
+		if err != nil {
+			v.addError(errs, path, fmt.Sprintf("invalid pattern %q in schema: %v", pattern, err))
+		} else if !re.MatchString(str) {
+			v.addError(errs, path, fmt.Sprintf("must match pattern %q", pattern))
+		}
+	}
+	if format, _ := s["format"].(string); format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.addError(errs, path, "must be an RFC 3339 date-time")
+		}
+	}
+}
+
+// validateNumber checks n against the numeric bounds of s. Comparisons are
+// exact, so large integers are not rounded to float64 first.
+func (v *Validator) validateNumber(path string, s map[string]any, n *big.Rat, errs *[]entities.ValidationError) {
+	if min, ok := numberValue(s["minimum"]); ok && n.Cmp(min) < 0 {
+		v.addError(errs, path, fmt.Sprintf("must be >= %v", s["minimum"]))
+	}
+	if max, ok := numberValue(s["maximum"]); ok && n.Cmp(max) > 0 {
+		v.addError(errs, path, fmt.Sprintf("must be <= %v", s["maximum"]))
+	}
+	if min, ok := numberValue(s["exclusiveMinimum"]); ok && n.Cmp(min) <= 0 {
+		v.addError(errs, path, fmt.Sprintf("must be > %v", s["exclusiveMinimum"]))
+	}
+	if max, ok := numberValue(s["exclusiveMaximum"]); ok && n.Cmp(max) >= 0 {
+		v.addError(errs, path, fmt.Sprintf("must be < %v", s["exclusiveMaximum"]))
+	}
+	if m, ok := numberValue(s["multipleOf"]); ok && m.Sign() != 0 {
+		if !new(big.Rat).Quo(n, m).IsInt() {
+			v.addError(errs, path, fmt.Sprintf("must be a multiple of %v", s["multipleOf"]))
+		}
+	}
+}
+
+// defaultFor returns the default declared by a property schema, following $ref.
+func (v *Validator) defaultFor(s map[string]any) (any, bool) {
+	if def, ok := s["default"]; ok {
+		return def, true
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		if target, err := v.resolveRef(ref); err == nil {
+			if ts, ok := target.(map[string]any); ok {
+				def, ok := ts["default"]
+				return def, ok
+			}
+		}
+	}
+	return nil, false
+}
+
+// resolveRef resolves a local JSON pointer reference such as "#/$defs/Inner".
+func (v *Validator) resolveRef(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// compilePatterns compiles the pattern of node and of every schema nested in
+// it. Literal values under const, enum, default and examples are skipped.
+func (v *Validator) compilePatterns(node any) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			switch key {
+			case "const", "enum", "default", "examples":
+				continue
+			case "pattern":
+				if pattern, ok := child.(string); ok {
+					if _, done := v.patterns[pattern]; !done {
+						re, err := regexp.Compile(pattern)
+						v.patterns[pattern] = compiledPattern{re: re, err: err}
+					}
+					continue
+				}
+			}
+			v.compilePatterns(child)
+		}
+	case []any:
+		for _, child := range n {
+			v.compilePatterns(child)
+		}
+	}
+}
+
+// pattern returns the compiled form of a pattern found by compilePatterns.
+func (v *Validator) pattern(pattern string) (*regexp.Regexp, error) {
+	if p, ok := v.patterns[pattern]; ok {
+		return p.re, p.err
+	}
+	return regexp.Compile(pattern)
+}
+
+func (v *Validator) addError(errs *[]entities.ValidationError, path, message string) {
+	*errs = append(*errs, entities.ValidationError{Field: path, Message: message})
+}
+
+func matchesType(t string, value any) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := numberValue(value)
+		return ok
+	case "integer":
+		n, ok := numberValue(value)
+		return ok && n.IsInt()
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	default:
+		return true
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch n := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64, json.Number:
+		if n, ok := numberValue(n); ok && n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+// numberValue returns the exact value of a decoded JSON number, which is a
+// float64 or, when decoded with UseNumber, a json.Number. A float64 is taken
+// at its shortest decimal form, so a schema's 0.1 is exactly one tenth.
+func numberValue(v any) (*big.Rat, bool) {
+	var text string
+	switch n := v.(type) {
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, false
+		}
+		text = strconv.FormatFloat(n, 'g', -1, 64)
+	case json.Number:
+		text = string(n)
+	default:
+		return nil, false
+	}
+	return new(big.Rat).SetString(text)
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if jsonEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares decoded JSON values, treating numbers as equal when
+// their values are, whatever their Go representation.
+func jsonEqual(a, b any) bool {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		return ok && x.Cmp(y) == 0
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			if bv, ok := b[k]; !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// deepCopy copies decoded JSON values so defaults and trial validations do not alias.
+func deepCopy(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []any:
+		s := make([]any, len(val))
+		for i, item := range val {
+			s[i] = deepCopy(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,144 @@
+//go:build !wasip1
+
+package schema
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validateInner struct {
+	Host string `json:"host" jsonschema:"minLength=1"`
+	Port int    `json:"port,omitempty" jsonschema:"minimum=1,maximum=65535"`
+}
+
+type validateInput struct {
+	URL     string          `json:"url" jsonschema:"required"`
+	Method  string          `json:"method,omitempty" jsonschema:"enum=GET,enum=POST,default=GET"`
+	Status  int             `json:"status,omitempty" jsonschema:"default=200"`
+	Tags    []string        `json:"tags,omitempty"`
+	Servers []validateInner `json:"servers,omitempty"`
+}
+
+func fields(t *testing.T, v *Validator, doc string) map[string]string {
+	t.Helper()
+	_, res, err := v.ValidateJSON([]byte(doc))
+	require.NoError(t, err)
+	out := make(map[string]string)
+	for _, e := range res.Errors {
+		out[e.Field] = e.Message
+	}
+	assert.Equal(t, len(res.Errors) == 0, res.Valid)
+	return out
+}
+
+func TestValidator_AppliesDefaults(t *testing.T) {
+	v, err := NewValidatorFor(&validateInput{})
+	require.NoError(t, err)
+
+	normalized, res, err := v.ValidateJSON([]byte(`{"url":"https://example.com"}`))
+	require.NoError(t, err)
+	require.True(t, res.Valid, "%v", res.Errors)
+
+	var decoded validateInput
+	require.NoError(t, json.Unmarshal(normalized, &decoded))
+	assert.Equal(t, "GET", decoded.Method)
+	assert.Equal(t, 200, decoded.Status)
+}
+
+func TestValidator_ReportsEveryViolation(t *testing.T) {
+	v, err := NewValidatorFor(&validateInput{})
+	require.NoError(t, err)
+
+	errs := fields(t, v, `{
+		"method": "DELETE",
+		"status": 1.5,
+		"tags": ["ok", 3],
+		"servers": [{"host": "a", "port": 70000}, {"host": ""}],
+		"extra": true
+	}`)
+
+	assert.Equal(t, "is required", errs["url"])
+	assert.Contains(t, errs["method"], `must be one of ["GET", "POST"]`)
+	assert.Contains(t, errs["status"], "expected integer")
+	assert.Contains(t, errs["tags[1]"], "expected string")
+	assert.Contains(t, errs["servers[0].port"], "must be <= 65535")
+	assert.Contains(t, errs["servers[1].host"], "at least 1")
+	assert.Equal(t, "unknown field", errs["extra"])
+}
+
+func TestValidator_EmptyInput(t *testing.T) {
+	v, err := NewValidatorFor(&validateInput{})
+	require.NoError(t, err)
+
+	errs := fields(t, v, ``)
+	assert.Contains(t, errs, "url")
+}
+
+func TestValidator_WrongRootType(t *testing.T) {
+	v, err := NewValidatorFor(&validateInput{})
+	require.NoError(t, err)
+
+	errs := fields(t, v, `[1,2]`)
+	assert.Equal(t, "expected object, got array", errs[""])
+}
+
+type validateNumbers struct {
+	ID    int64   `json:"id"`
+	Max   uint64  `json:"max,omitempty"`
+	Ratio float64 `json:"ratio,omitempty" jsonschema:"multipleOf=0.1,maximum=1"`
+	Level int     `json:"level,omitempty" jsonschema:"enum=1,enum=2"`
+}
+
+func TestValidator_KeepsExactNumbers(t *testing.T) {
+	v, err := NewValidatorFor(&validateNumbers{})
+	require.NoError(t, err)
+
+	normalized, res, err := v.ValidateJSON([]byte(`{"id":9007199254740993,"max":18446744073709551615,"ratio":0.3,"level":2}`))
+	require.NoError(t, err)
+	require.True(t, res.Valid, "%v", res.Errors)
+
+	var decoded validateNumbers
+	require.NoError(t, json.Unmarshal(normalized, &decoded))
+	assert.Equal(t, int64(9007199254740993), decoded.ID)
+	assert.Equal(t, uint64(18446744073709551615), decoded.Max)
+	assert.Equal(t, 0.3, decoded.Ratio)
+
+	errs := fields(t, v, `{"id":9007199254740993.5,"ratio":1.5,"level":3}`)
+	assert.Contains(t, errs["id"], "expected integer")
+	assert.Contains(t, errs["ratio"], "must be <= 1")
+	assert.Contains(t, errs["level"], "must be one of [1, 2]")
+}
+
+func TestValidator_RejectsTrailingData(t *testing.T) {
+	v, err := NewValidatorFor(&validateNumbers{})
+	require.NoError(t, err)
+
+	_, _, err = v.ValidateJSON([]byte(`{"id":1} {"id":2}`))
+	assert.Error(t, err)
+}
+
+type validatePatterns struct {
+	Name string `json:"name" jsonschema:"pattern=^[a-z]+$"`
+}
+
+func TestValidator_ConcurrentUse(t *testing.T) {
+	v, err := NewValidatorFor(&validatePatterns{})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, res, err := v.ValidateJSON([]byte(`{"name":"Upper"}`))
+			assert.NoError(t, err)
+			assert.False(t, res.Valid)
+		}()
+	}
+	wg.Wait()
+}
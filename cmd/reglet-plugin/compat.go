@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/compat"
+)
+
+// runCompat compares two manifest files. It exits 1 when the new manifest has
+// breaking changes its version does not account for, or with -strict, when it
+// has any breaking change at all.
+func runCompat(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	strict := fs.Bool("strict", false, "fail on any breaking change, even with a major version bump")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: reglet-plugin compat [-json] [-strict] OLD_MANIFEST NEW_MANIFEST")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsageErr
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitUsageErr
+	}
+
+	oldM, err := compat.LoadManifest(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+		return exitFailure
+	}
+	newM, err := compat.LoadManifest(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+		return exitFailure
+	}
+
+	report := compat.Compare(oldM, newM)
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+			return exitFailure
+		}
+	} else {
+		printCompatReport(stdout, report)
+	}
+
+	if *strict && report.HasBreaking() {
+		return exitFailure
+	}
+	if report.SuggestedVersion != "" && !report.VersionOK {
+		return exitFailure
+	}
+	return exitOK
+}
+
+func printCompatReport(w io.Writer, r *compat.Report) {
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "No changes.")
+	}
+	for _, kind := range []compat.ChangeKind{compat.Breaking, compat.Additive, compat.Cosmetic} {
+		changes := r.ByKind(kind)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s changes:\n", kind)
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %s: %s\n", c.Path, c.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "\nRequired bump: %s\n", r.RequiredBump)
+	if r.SuggestedVersion == "" {
+		fmt.Fprintf(w, "Version %q is not a semantic version; no suggestion.\n", r.OldVersion)
+		return
+	}
+	status := "ok"
+	if !r.VersionOK {
+		status = "too low"
+	}
+	fmt.Fprintf(w, "Version: %s -> %s (suggested >= %s, %s)\n", r.OldVersion, r.NewVersion, r.SuggestedVersion, status)
+}
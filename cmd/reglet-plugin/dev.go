@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// devFlags select the plugin a development command runs against. They are
+// consumed by reglet-plugin; all other arguments are passed to devtool.
+type devFlags struct {
+	pkg     string
+	varName string
+	client  string
+}
+
+func init() {
+	for _, name := range []string{"manifest", "invoke", "examples", "risk", "docs"} {
+		commands = append(commands, command{name: name, summary: devSummaries[name], run: devCommand(name)})
+	}
+}
+
+var devSummaries = map[string]string{
+	"manifest": "print a plugin's manifest as JSON or YAML",
+	"invoke":   "run SERVICE/OPERATION with JSON input",
+	"examples": "run a plugin's registered examples",
+	"risk":     "report capability risk",
+	"docs":     "render Markdown or HTML documentation",
+}
+
+const devUsage = `
+Plugin selection flags (all development commands):
+  -pkg PATH      plugin package (import path or directory, default ".")
+  -var NAME      exported *plugin.PluginDefinition variable (default "Plugin")
+  -client EXPR   exported variable or call in the package providing the client
+                 passed to handlers, e.g. "DevClient" or "NewFakeResolver()"
+`
+
+// devCommand builds the plugin package natively with a generated main that
+// calls devtool.Main, then runs it with the remaining arguments.
+func devCommand(name string) func(args []string, stdout, stderr io.Writer) int {
+	return func(args []string, stdout, stderr io.Writer) int {
+		flags, rest, err := parseDevFlags(args)
+		if err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin %s: %v\n%s", name, err, devUsage)
+			return exitUsageErr
+		}
+
+		importPath, err := resolveImportPath(flags.pkg)
+		if err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+			return exitFailure
+		}
+
+		dir, err := os.MkdirTemp("", "reglet-plugin-")
+		if err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+			return exitFailure
+		}
+		defer os.RemoveAll(dir)
+
+		mainFile := filepath.Join(dir, "main.go")
+		if err := writeDevMain(mainFile, importPath, flags); err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+			return exitFailure
+		}
+
+		// go build resolves imports against the module in the working directory.
+		binary := filepath.Join(dir, "devtool")
+		build := exec.Command("go", "build", "-o", binary, mainFile)
+		build.Stdout = stderr
+		build.Stderr = stderr
+		if err := build.Run(); err != nil {
+			fmt.Fprintf(stderr, "reglet-plugin: failed to build %s: %v\n", importPath, err)
+			return exitFailure
+		}
+
+		cmd := exec.Command(binary, append([]string{name}, rest...)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return exitErr.ExitCode()
+			}
+			fmt.Fprintf(stderr, "reglet-plugin: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+}
+
+// parseDevFlags extracts -pkg, -var and -client (in "-f v" or "-f=v" form)
+// and returns the remaining arguments unchanged.
+func parseDevFlags(args []string) (devFlags, []string, error) {
+	flags := devFlags{pkg: ".", varName: "Plugin"}
+	targets := map[string]*string{"pkg": &flags.pkg, "var": &flags.varName, "client": &flags.client}
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") || name == "" {
+			rest = append(rest, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(name, "=")
+		target, ok := targets[name]
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return flags, nil, fmt.Errorf("flag -%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		*target = value
+	}
+	return flags, rest, nil
+}
+
+func resolveImportPath(pkg string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve package %s: %s", pkg, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+var devMainTemplate = template.Must(template.New("main").Parse(`// Code generated by reglet-plugin. DO NOT EDIT.
+
+package main
+
+import (
+	"github.com/reglet-dev/reglet-plugin-sdk/application/devtool"
+
+	target {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	devtool.Main(target.{{.Var}}{{if .Client}}, devtool.WithClient(target.{{.Client}}){{end}})
+}
+`))
+
+func writeDevMain(path, importPath string, flags devFlags) error {
+	var buf bytes.Buffer
+	err := devMainTemplate.Execute(&buf, map[string]string{
+		"ImportPath": importPath,
+		"Var":        flags.varName,
+		"Client":     flags.client,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
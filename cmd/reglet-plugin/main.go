@@ -0,0 +1,69 @@
+// Command reglet-plugin provides development tooling for Reglet plugins.
+//
+// Usage:
+//
+//	reglet-plugin compat [-json] [-strict] OLD_MANIFEST NEW_MANIFEST
+//	reglet-plugin manifest [-pkg PATH] [-format json|yaml]
+//	reglet-plugin invoke [-pkg PATH] [-client EXPR] [-input FILE] [-set key=value]... SERVICE/OPERATION
+//	reglet-plugin examples [-pkg PATH] [-client EXPR]
+//	reglet-plugin risk [-pkg PATH] [-ops] [-policy FILE]
+//	reglet-plugin docs [-pkg PATH] [-format markdown|html] [-o FILE] [-policy FILE]
+//
+// The development commands (manifest, invoke, examples, risk, docs) build the plugin
+// package natively with a generated main that calls devtool.Main, so they must
+// be run from within the plugin's module. The package must export its
+// *plugin.PluginDefinition, by default as Plugin.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes shared by all subcommands.
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsageErr = 2
+)
+
+// command is a reglet-plugin subcommand.
+type command struct {
+	run     func(args []string, stdout, stderr io.Writer) int
+	name    string
+	summary string
+}
+
+var commands = []command{
+	{name: "compat", summary: "compare two manifests and suggest a version bump", run: runCompat},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		return exitUsageErr
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "reglet-plugin: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitUsageErr
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: reglet-plugin <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, devUsage)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDevFlags(t *testing.T) {
+	flags, rest, err := parseDevFlags([]string{"-pkg", "./core", "-set", "a=1", "-client=DevClient", "dns/resolve"})
+	require.NoError(t, err)
+	assert.Equal(t, devFlags{pkg: "./core", varName: "Plugin", client: "DevClient"}, flags)
+	assert.Equal(t, []string{"-set", "a=1", "dns/resolve"}, rest)
+
+	_, _, err = parseDevFlags([]string{"-var"})
+	assert.Error(t, err)
+}
+
+func TestWriteDevMain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, writeDevMain(path, "example.com/my-plugin/core", devFlags{varName: "Plugin", client: "NewFakes()"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `target "example.com/my-plugin/core"`)
+	assert.Contains(t, string(data), "devtool.Main(target.Plugin, devtool.WithClient(target.NewFakes()))")
+}
+
+func TestRunCompat(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	require.NoError(t, os.WriteFile(oldPath, []byte(`{"name":"p","version":"1.0.0","services":{"s":{"name":"s","operations":[{"name":"a"},{"name":"b"}]}}}`), 0o600))
+	require.NoError(t, os.WriteFile(newPath, []byte(`{"name":"p","version":"1.1.0","services":{"s":{"name":"s","operations":[{"name":"a"}]}}}`), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"compat", oldPath, newPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stdout.String(), "services.s.operations.b: operation removed")
+	assert.Contains(t, stdout.String(), "suggested >= 2.0.0, too low")
+
+	stdout.Reset()
+	code = run([]string{"compat", oldPath, oldPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout.String(), "No changes.")
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitUsageErr, run([]string{"bogus"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "bogus"`)
+}
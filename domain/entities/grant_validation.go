@@ -0,0 +1,328 @@
+package entities
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// shellMetacharacters are rejected in exec commands: a command names one
+// executable, and "*" is the only supported wildcard.
+const shellMetacharacters = ";|&$`<>(){}[]?!'\"\\ \t\n"
+
+// ValidateGrantSet checks every rule in g and returns the problems found,
+// with field paths such as "network.rules[0].ports[1]". A nil GrantSet is valid.
+func ValidateGrantSet(g *GrantSet) ValidationResult {
+	var v grantValidator
+	v.grantSet(g)
+	return v.result()
+}
+
+// ValidateCapabilityRequest checks that req.Kind is known and that req.Rule is
+// a well-formed rule or runtime request of that kind. Field paths start with
+// "kind" or "rule".
+func ValidateCapabilityRequest(req *CapabilityRequest) ValidationResult {
+	var v grantValidator
+	if req == nil {
+		v.add("", "capability request is required")
+		return v.result()
+	}
+
+	switch rule := req.Rule.(type) {
+	case NetworkRule:
+		v.kind(req.Kind, "network")
+		v.networkRule("rule", rule)
+	case *NetworkRule:
+		v.kind(req.Kind, "network")
+		if v.notNil("rule", rule != nil) {
+			v.networkRule("rule", *rule)
+		}
+	case NetworkRequest:
+		v.kind(req.Kind, "network")
+		v.host("rule.host", rule.Host)
+		if rule.Port < 1 || rule.Port > 65535 {
+			v.add("rule.port", fmt.Sprintf("port %d is out of range 1-65535", rule.Port))
+		}
+	case FileSystemRule:
+		v.kind(req.Kind, "fs")
+		v.fsRule("rule", rule)
+	case *FileSystemRule:
+		v.kind(req.Kind, "fs")
+		if v.notNil("rule", rule != nil) {
+			v.fsRule("rule", *rule)
+		}
+	case FileSystemRequest:
+		v.kind(req.Kind, "fs")
+		if rule.Operation != "read" && rule.Operation != "write" {
+			v.add("rule.operation", fmt.Sprintf("operation %q must be read or write", rule.Operation))
+		}
+		v.fsPath("rule.path", rule.Path)
+	case EnvironmentRequest:
+		v.kind(req.Kind, "env")
+		v.envVar("rule.variable", rule.Variable)
+	case ExecCapabilityRequest:
+		v.kind(req.Kind, "exec")
+		v.command("rule.command", rule.Command)
+	case KeyValueRule:
+		v.kind(req.Kind, "kv")
+		v.kvRule("rule", rule)
+	case *KeyValueRule:
+		v.kind(req.Kind, "kv")
+		if v.notNil("rule", rule != nil) {
+			v.kvRule("rule", *rule)
+		}
+	case KeyValueRequest:
+		v.kind(req.Kind, "kv")
+		if rule.Operation != "read" && rule.Operation != "write" {
+			v.add("rule.operation", fmt.Sprintf("operation %q must be read or write", rule.Operation))
+		}
+		v.key("rule.key", rule.Key)
+	case nil:
+		v.add("rule", "rule is required")
+	default:
+		v.add("rule", fmt.Sprintf("unsupported rule type %T", req.Rule))
+	}
+	return v.result()
+}
+
+// grantValidator collects validation errors with their field paths.
+type grantValidator struct {
+	errs []ValidationError
+}
+
+func (v *grantValidator) add(field, message string) {
+	v.errs = append(v.errs, ValidationError{Field: field, Message: message})
+}
+
+func (v *grantValidator) result() ValidationResult {
+	return ValidationResult{Errors: v.errs, Valid: len(v.errs) == 0}
+}
+
+func (v *grantValidator) kind(got, want string) {
+	if got != want {
+		v.add("kind", fmt.Sprintf("kind %q does not match a %s rule", got, want))
+	}
+}
+
+func (v *grantValidator) notNil(field string, ok bool) bool {
+	if !ok {
+		v.add(field, "rule is required")
+	}
+	return ok
+}
+
+func (v *grantValidator) grantSet(g *GrantSet) {
+	if g == nil {
+		return
+	}
+	if g.Network != nil {
+		rules := "network.rules"
+		if len(g.Network.Rules) == 0 {
+			v.add(rules, "must contain at least one rule")
+		}
+		for i, rule := range g.Network.Rules {
+			v.networkRule(indexField(rules, i), rule)
+		}
+	}
+	if g.FS != nil {
+		rules := "fs.rules"
+		if len(g.FS.Rules) == 0 {
+			v.add(rules, "must contain at least one rule")
+		}
+		for i, rule := range g.FS.Rules {
+			v.fsRule(indexField(rules, i), rule)
+		}
+	}
+	if g.Env != nil {
+		vars := "env.vars"
+		if len(g.Env.Variables) == 0 {
+			v.add(vars, "must contain at least one variable")
+		}
+		for i, name := range g.Env.Variables {
+			v.envVar(indexField(vars, i), name)
+		}
+	}
+	if g.Exec != nil {
+		cmds := "exec.commands"
+		if len(g.Exec.Commands) == 0 {
+			v.add(cmds, "must contain at least one command")
+		}
+		for i, cmd := range g.Exec.Commands {
+			v.command(indexField(cmds, i), cmd)
+		}
+	}
+	if g.KV != nil {
+		rules := "kv.rules"
+		if len(g.KV.Rules) == 0 {
+			v.add(rules, "must contain at least one rule")
+		}
+		for i, rule := range g.KV.Rules {
+			v.kvRule(indexField(rules, i), rule)
+		}
+	}
+}
+
+func (v *grantValidator) networkRule(field string, rule NetworkRule) {
+	if len(rule.Hosts) == 0 {
+		v.add(field+".hosts", "must contain at least one host")
+	}
+	for i, host := range rule.Hosts {
+		v.host(indexField(field+".hosts", i), host)
+	}
+	if len(rule.Ports) == 0 {
+		v.add(field+".ports", "must contain at least one port")
+	}
+	for i, port := range rule.Ports {
+		v.port(indexField(field+".ports", i), port)
+	}
+}
+
+// host accepts "*", a hostname or IP address, or "*." followed by a domain.
+func (v *grantValidator) host(field, host string) {
+	switch {
+	case host == "":
+		v.add(field, "host must not be empty")
+		return
+	case host == "*":
+		return
+	case strings.Contains(host, "://"):
+		v.add(field, fmt.Sprintf("host %q must not include a scheme", host))
+		return
+	case strings.ContainsAny(host, "/ \t\n"):
+		v.add(field, fmt.Sprintf("host %q is not a hostname or IP address", host))
+		return
+	}
+
+	name := strings.TrimPrefix(host, "*.")
+	if strings.Contains(name, "*") {
+		v.add(field, fmt.Sprintf("host %q may only use \"*\" alone or as a leading \"*.\" label", host))
+		return
+	}
+	if strings.Count(name, ":") == 1 {
+		v.add(field, fmt.Sprintf("host %q must not include a port; use ports", host))
+		return
+	}
+	if strings.Contains(name, "..") || strings.HasPrefix(name, ".") {
+		v.add(field, fmt.Sprintf("host %q has an empty label", host))
+	}
+}
+
+// port accepts "*", a port from 1 to 65535, or an ascending range "lo-hi".
+func (v *grantValidator) port(field, port string) {
+	if port == "*" {
+		return
+	}
+	lo, hi, isRange := strings.Cut(port, "-")
+	loN, loErr := parsePort(lo)
+	if !isRange {
+		if loErr != nil {
+			v.add(field, fmt.Sprintf("port %q: %v", port, loErr))
+		}
+		return
+	}
+	hiN, hiErr := parsePort(hi)
+	switch {
+	case loErr != nil:
+		v.add(field, fmt.Sprintf("port range %q: start %v", port, loErr))
+	case hiErr != nil:
+		v.add(field, fmt.Sprintf("port range %q: end %v", port, hiErr))
+	case loN > hiN:
+		v.add(field, fmt.Sprintf("port range %q: start is greater than end", port))
+	}
+}
+
+func parsePort(s string) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("is missing")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || strings.HasPrefix(s, "+") {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < 1 || n > 65535 {
+		return 0, fmt.Errorf("%d is out of range 1-65535", n)
+	}
+	return n, nil
+}
+
+func (v *grantValidator) fsRule(field string, rule FileSystemRule) {
+	if len(rule.Read) == 0 && len(rule.Write) == 0 {
+		v.add(field, "must list at least one read or write path")
+	}
+	for i, p := range rule.Read {
+		v.fsPath(indexField(field+".read", i), p)
+	}
+	for i, p := range rule.Write {
+		v.fsPath(indexField(field+".write", i), p)
+	}
+}
+
+// fsPath accepts an absolute path.Match pattern, optionally ending in "/**".
+func (v *grantValidator) fsPath(field, p string) {
+	if p == "" {
+		v.add(field, "path must not be empty")
+		return
+	}
+	if !path.IsAbs(p) {
+		v.add(field, fmt.Sprintf("path %q must be absolute", p))
+		return
+	}
+	glob := strings.TrimSuffix(p, "/**")
+	if strings.Contains(glob, "**") {
+		v.add(field, fmt.Sprintf("path %q may only use \"**\" as the last element", p))
+		return
+	}
+	if _, err := path.Match(glob, ""); err != nil {
+		v.add(field, fmt.Sprintf("path %q is not a valid glob: %v", p, err))
+	}
+}
+
+// envVar accepts letters, digits, "_" and "*", not starting with a digit.
+func (v *grantValidator) envVar(field, name string) {
+	if name == "" {
+		v.add(field, "variable must not be empty")
+		return
+	}
+	for i, r := range name {
+		ok := r == '_' || r == '*' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || i > 0 && r >= '0' && r <= '9'
+		if !ok {
+			v.add(field, fmt.Sprintf("variable %q may only contain letters, digits, \"_\" and \"*\", and must not start with a digit", name))
+			return
+		}
+	}
+}
+
+func (v *grantValidator) command(field, cmd string) {
+	if cmd == "" {
+		v.add(field, "command must not be empty")
+		return
+	}
+	if i := strings.IndexAny(cmd, shellMetacharacters); i >= 0 {
+		v.add(field, fmt.Sprintf("command %q contains shell metacharacter %q; list one executable without arguments", cmd, cmd[i]))
+	}
+}
+
+func (v *grantValidator) kvRule(field string, rule KeyValueRule) {
+	switch rule.Operation {
+	case "read", "write", "read-write":
+	default:
+		v.add(field+".op", fmt.Sprintf("operation %q must be read, write or read-write", rule.Operation))
+	}
+	if len(rule.Keys) == 0 {
+		v.add(field+".keys", "must contain at least one key")
+	}
+	for i, key := range rule.Keys {
+		v.key(indexField(field+".keys", i), key)
+	}
+}
+
+func (v *grantValidator) key(field, key string) {
+	if key == "" {
+		v.add(field, "key must not be empty")
+	}
+}
+
+func indexField(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
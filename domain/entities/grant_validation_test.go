@@ -0,0 +1,140 @@
+package entities_test
+
+import (
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGrantSet_Valid(t *testing.T) {
+	g := &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{"*"}, Ports: []string{"53"}},
+			{Hosts: []string{"api.example.com", "*.example.org", "10.0.0.1", "2001:db8::1"}, Ports: []string{"443", "8000-9000", "*"}},
+		}},
+		FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{
+			{Read: []string{"/etc/hosts", "/etc/sysctl.d/*.conf", "/etc/ssh/**"}, Write: []string{"/tmp/out-[0-9].json"}},
+		}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"HOME", "AWS_*"}},
+		Exec: &entities.ExecCapability{Commands: []string{"systemctl", "/usr/bin/*"}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "read-write", Keys: []string{"certs/*"}},
+		}},
+	}
+
+	res := entities.ValidateGrantSet(g)
+	assert.True(t, res.Valid, "%v", res.Errors)
+	assert.Empty(t, res.Errors)
+	assert.True(t, entities.ValidateGrantSet(nil).Valid)
+	assert.True(t, entities.ValidateGrantSet(&entities.GrantSet{}).Valid)
+}
+
+func TestValidateGrantSet_Invalid(t *testing.T) {
+	g := &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{}, Ports: []string{"80-", "99999", "http", "9000-8000", "0"}},
+			{Hosts: []string{"", "https://example.com", "api.*.com", "example.com:443"}, Ports: []string{"443"}},
+		}},
+		FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{
+			{},
+			{Read: []string{"etc/hosts", "/etc/[ssh"}, Write: []string{"/var/**/log"}},
+		}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"1PATH", "MY-VAR"}},
+		Exec: &entities.ExecCapability{Commands: []string{"sh -c", "ls;rm", "$(id)", ""}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "delete", Keys: nil},
+		}},
+	}
+
+	res := entities.ValidateGrantSet(g)
+	assert.False(t, res.Valid)
+
+	var fields []string
+	for _, e := range res.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"network.rules[0].hosts",
+		"network.rules[0].ports[0]",
+		"network.rules[0].ports[1]",
+		"network.rules[0].ports[2]",
+		"network.rules[0].ports[3]",
+		"network.rules[0].ports[4]",
+		"network.rules[1].hosts[0]",
+		"network.rules[1].hosts[1]",
+		"network.rules[1].hosts[2]",
+		"network.rules[1].hosts[3]",
+		"fs.rules[0]",
+		"fs.rules[1].read[0]",
+		"fs.rules[1].read[1]",
+		"fs.rules[1].write[0]",
+		"env.vars[0]",
+		"env.vars[1]",
+		"exec.commands[0]",
+		"exec.commands[1]",
+		"exec.commands[2]",
+		"exec.commands[3]",
+		"kv.rules[0].op",
+		"kv.rules[0].keys",
+	}, fields)
+	assert.Equal(t, `port range "80-": end is missing`, res.Errors[1].Message)
+	assert.Equal(t, `port "99999": 99999 is out of range 1-65535`, res.Errors[2].Message)
+	assert.Equal(t, `command "sh -c" contains shell metacharacter ' '; list one executable without arguments`, res.Errors[16].Message)
+}
+
+func TestValidateGrantSet_EmptyCapabilities(t *testing.T) {
+	res := entities.ValidateGrantSet(&entities.GrantSet{
+		Network: &entities.NetworkCapability{},
+		Exec:    &entities.ExecCapability{},
+	})
+	assert.False(t, res.Valid)
+	assert.Equal(t, []entities.ValidationError{
+		{Field: "network.rules", Message: "must contain at least one rule"},
+		{Field: "exec.commands", Message: "must contain at least one command"},
+	}, res.Errors)
+}
+
+func TestValidateCapabilityRequest(t *testing.T) {
+	valid := []entities.CapabilityRequest{
+		{Kind: "network", Rule: entities.NetworkRule{Hosts: []string{"example.com"}, Ports: []string{"443"}}},
+		{Kind: "network", Rule: entities.NetworkRequest{Host: "example.com", Port: 443}},
+		{Kind: "fs", Rule: &entities.FileSystemRule{Read: []string{"/etc/hosts"}}},
+		{Kind: "fs", Rule: entities.FileSystemRequest{Operation: "read", Path: "/etc/hosts"}},
+		{Kind: "env", Rule: entities.EnvironmentRequest{Variable: "HOME"}},
+		{Kind: "exec", Rule: entities.ExecCapabilityRequest{Command: "/usr/bin/systemctl"}},
+		{Kind: "kv", Rule: entities.KeyValueRule{Operation: "read", Keys: []string{"a"}}},
+		{Kind: "kv", Rule: entities.KeyValueRequest{Operation: "write", Key: "a"}},
+	}
+	for _, req := range valid {
+		res := entities.ValidateCapabilityRequest(&req)
+		assert.True(t, res.Valid, "%+v: %v", req, res.Errors)
+	}
+
+	tests := []struct {
+		req  entities.CapabilityRequest
+		want []entities.ValidationError
+	}{
+		{
+			req:  entities.CapabilityRequest{Kind: "network", Rule: entities.NetworkRequest{Host: "example.com", Port: 70000}},
+			want: []entities.ValidationError{{Field: "rule.port", Message: "port 70000 is out of range 1-65535"}},
+		},
+		{
+			req:  entities.CapabilityRequest{Kind: "exec", Rule: entities.FileSystemRequest{Operation: "read", Path: "tmp"}},
+			want: []entities.ValidationError{{Field: "kind", Message: `kind "exec" does not match a fs rule`}, {Field: "rule.path", Message: `path "tmp" must be absolute`}},
+		},
+		{
+			req:  entities.CapabilityRequest{Kind: "exec", Rule: "bash"},
+			want: []entities.ValidationError{{Field: "rule", Message: "unsupported rule type string"}},
+		},
+		{
+			req:  entities.CapabilityRequest{Kind: "fs", Rule: (*entities.FileSystemRule)(nil)},
+			want: []entities.ValidationError{{Field: "rule", Message: "rule is required"}},
+		},
+	}
+	for _, tt := range tests {
+		res := entities.ValidateCapabilityRequest(&tt.req)
+		assert.False(t, res.Valid)
+		assert.Equal(t, tt.want, res.Errors)
+	}
+}
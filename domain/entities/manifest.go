@@ -1,15 +1,128 @@
 package entities
 
-import abi "github.com/reglet-dev/reglet-abi"
+import (
+	"encoding/json"
+
+	abi "github.com/reglet-dev/reglet-abi"
+)
 
 // Manifest contains complete plugin metadata for introspection.
-type Manifest = abi.Manifest
+// It is JSON-compatible with abi.Manifest: fields the SDK adds on top of the
+// ABI are omitted when empty, so hosts built against reglet-abi decode it unchanged.
+// It is no longer an alias of abi.Manifest; Go code that exchanges abi.Manifest
+// values converts with ToABI and ManifestFromABI.
+type Manifest struct {
+	// Registered services and operations
+	Services map[string]ServiceManifest `json:"services" yaml:"services"`
+
+	// Identity
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description" yaml:"description"`
+
+	// Compatibility
+	SDKVersion     string `json:"sdk_version" yaml:"sdk_version"`
+	MinHostVersion string `json:"min_host_version,omitempty" yaml:"min_host_version,omitempty"`
+
+	// Config schema (JSON Schema)
+	ConfigSchema json.RawMessage `json:"config_schema" yaml:"config_schema"`
+
+	// Capabilities is the union of the plugin-wide grants and every operation's grants.
+	Capabilities GrantSet `json:"capabilities" yaml:"capabilities"`
+}
 
 // ServiceManifest describes a service and its operations.
-type ServiceManifest = abi.ServiceManifest
+type ServiceManifest struct {
+	Name        string              `json:"name" yaml:"name"`
+	Description string              `json:"description" yaml:"description"`
+	Operations  []OperationManifest `json:"operations" yaml:"operations"`
+}
 
 // OperationManifest describes a single operation.
-type OperationManifest = abi.OperationManifest
+type OperationManifest struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+
+	// Input fields this operation requires (subset of plugin config)
+	InputFields []string `json:"input_fields,omitempty" yaml:"input_fields,omitempty"`
+
+	// JSON Schema for the operation input, including nested objects, types,
+	// required fields, enums, defaults and descriptions
+	InputSchema json.RawMessage `json:"input_schema,omitempty" yaml:"input_schema,omitempty"`
+
+	// JSON Schema for Result.Data structure
+	OutputSchema json.RawMessage `json:"output_schema,omitempty" yaml:"output_schema,omitempty"`
+
+	// Examples for documentation and testing
+	Examples []OperationExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+
+	// Capabilities declared by this operation. Nil means the operation
+	// declares nothing beyond the plugin-wide grants.
+	Capabilities *GrantSet `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+}
 
 // OperationExample provides a sample input/output pair.
 type OperationExample = abi.OperationExample
+
+// ToABI converts m to an abi.Manifest, dropping the per-operation input
+// schemas and capabilities, which the ABI type cannot hold. The top-level
+// Capabilities already include every operation's grants.
+func (m *Manifest) ToABI() *abi.Manifest {
+	out := &abi.Manifest{
+		Name:           m.Name,
+		Version:        m.Version,
+		Description:    m.Description,
+		SDKVersion:     m.SDKVersion,
+		MinHostVersion: m.MinHostVersion,
+		ConfigSchema:   m.ConfigSchema,
+		Capabilities:   m.Capabilities,
+	}
+	if m.Services != nil {
+		out.Services = make(map[string]abi.ServiceManifest, len(m.Services))
+	}
+	for name, svc := range m.Services {
+		abiSvc := abi.ServiceManifest{Name: svc.Name, Description: svc.Description}
+		for _, op := range svc.Operations {
+			abiSvc.Operations = append(abiSvc.Operations, abi.OperationManifest{
+				Name:         op.Name,
+				Description:  op.Description,
+				InputFields:  op.InputFields,
+				OutputSchema: op.OutputSchema,
+				Examples:     op.Examples,
+			})
+		}
+		out.Services[name] = abiSvc
+	}
+	return out
+}
+
+// ManifestFromABI converts an abi.Manifest to a Manifest. Operations carry no
+// input schemas or capabilities of their own.
+func ManifestFromABI(m *abi.Manifest) *Manifest {
+	out := &Manifest{
+		Name:           m.Name,
+		Version:        m.Version,
+		Description:    m.Description,
+		SDKVersion:     m.SDKVersion,
+		MinHostVersion: m.MinHostVersion,
+		ConfigSchema:   m.ConfigSchema,
+		Capabilities:   m.Capabilities,
+	}
+	if m.Services != nil {
+		out.Services = make(map[string]ServiceManifest, len(m.Services))
+	}
+	for name, svc := range m.Services {
+		sdkSvc := ServiceManifest{Name: svc.Name, Description: svc.Description}
+		for _, op := range svc.Operations {
+			sdkSvc.Operations = append(sdkSvc.Operations, OperationManifest{
+				Name:         op.Name,
+				Description:  op.Description,
+				InputFields:  op.InputFields,
+				OutputSchema: op.OutputSchema,
+				Examples:     op.Examples,
+			})
+		}
+		out.Services[name] = sdkSvc
+	}
+	return out
+}
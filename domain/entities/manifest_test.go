@@ -0,0 +1,49 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	abi "github.com/reglet-dev/reglet-abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest_ABIConversion(t *testing.T) {
+	exec := &entities.GrantSet{Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}}}
+	m := &entities.Manifest{
+		Name:         "svc",
+		Version:      "1.0.0",
+		SDKVersion:   "0.1.0",
+		ConfigSchema: json.RawMessage(`{"type":"object"}`),
+		Capabilities: *exec,
+		Services: map[string]entities.ServiceManifest{
+			"unit": {Name: "unit", Operations: []entities.OperationManifest{{
+				Name:         "restart",
+				InputSchema:  json.RawMessage(`{"type":"object"}`),
+				OutputSchema: json.RawMessage(`{"type":"object"}`),
+				Capabilities: exec,
+			}}},
+		},
+	}
+
+	a := m.ToABI()
+	assert.Equal(t, "svc", a.Name)
+	assert.Equal(t, *exec, a.Capabilities)
+	require.Len(t, a.Services["unit"].Operations, 1)
+	assert.Equal(t, abi.OperationManifest{Name: "restart", OutputSchema: json.RawMessage(`{"type":"object"}`)}, a.Services["unit"].Operations[0])
+
+	back := entities.ManifestFromABI(a)
+	op := back.Services["unit"].Operations[0]
+	assert.Nil(t, op.InputSchema)
+	assert.Nil(t, op.Capabilities)
+	assert.Equal(t, m.Capabilities, back.Capabilities)
+
+	// Both encode to JSON the other side decodes.
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	var decoded abi.Manifest
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, a, &decoded)
+}
@@ -1,9 +1,5 @@
 package entities
 
-import (
-	"fmt"
-)
-
 // RiskLevel represents the security risk level of a capability.
 type RiskLevel int
 
@@ -42,6 +38,8 @@ type RiskAnalyzer interface {
 type RiskReport struct {
 	Level       RiskLevel
 	RiskFactors []RiskFactor
+	// Score is the sum of the factor scores.
+	Score int
 }
 
 // RiskFactor describes a specific risky capability.
@@ -50,85 +48,78 @@ type RiskFactor struct {
 	Description string
 	// Rule is a human-readable representation of the specific rule causing this risk
 	Rule string
+	// RuleID is the ID of the policy rule that produced this factor.
+	RuleID string
+	Score  int
 }
 
-// SimpleRiskAnalyzer implements basic heuristic risk analysis.
+// SimpleRiskAnalyzer analyzes grants with DefaultRiskPolicy.
 type SimpleRiskAnalyzer struct{}
 
+// defaultAnalyzer is the PolicyRiskAnalyzer for DefaultRiskPolicy.
+var defaultAnalyzer, _ = NewPolicyRiskAnalyzer(DefaultRiskPolicy())
+
 func NewSimpleRiskAnalyzer() RiskAnalyzer {
 	return &SimpleRiskAnalyzer{}
 }
 
 func (a *SimpleRiskAnalyzer) Analyze(grants *GrantSet) RiskReport {
-	report := RiskReport{
-		Level: RiskNone,
-	}
+	return defaultAnalyzer.Analyze(grants)
+}
 
-	if grants == nil {
-		return report
+// AnalyzeOperations scores the capabilities declared by each operation in the
+// manifest separately, so a host can grant and assess access per operation.
+// Reports are keyed by "service/operation"; operations without their own
+// capabilities are omitted.
+func AnalyzeOperations(a RiskAnalyzer, m *Manifest) map[string]RiskReport {
+	reports := make(map[string]RiskReport)
+	if m == nil {
+		return reports
 	}
-
-	// Helper to add a factor
-	addFactor := func(level RiskLevel, desc, rule string) {
-		if level > RiskNone {
-			report.RiskFactors = append(report.RiskFactors, RiskFactor{
-				Level:       level,
-				Description: desc,
-				Rule:        rule,
-			})
-			if level > report.Level {
-				report.Level = level
+	for svcName, svc := range m.Services {
+		for _, op := range svc.Operations {
+			if op.Capabilities == nil {
+				continue
 			}
+			reports[svcName+"/"+op.Name] = a.Analyze(op.Capabilities)
 		}
 	}
+	return reports
+}
 
-	// 1. Analyze Network
-	if grants.Network != nil {
-		for _, rule := range grants.Network.Rules {
-			ruleStr := fmt.Sprintf("Network: %s:%s", rule.Hosts, rule.Ports)
-
-			// Critical: Wildcard host
-			isWildcardHost := false
-			for _, h := range rule.Hosts {
-				if h == "*" || h == "0.0.0.0" {
-					isWildcardHost = true
-					break
-				}
-			}
-
-			if isWildcardHost {
-				addFactor(RiskCritical, "Unrestricted network access", ruleStr)
-			} else {
-				addFactor(RiskMedium, "Outbound network access", ruleStr)
-			}
-		}
+// AnalyzeHTTPRequest scores the TLS settings of an HTTP request, which a
+// GrantSet cannot express: disabling certificate verification is critical and
+// allowing TLS versions older than 1.2 is high. Hosts can call it before
+// executing a request to log or refuse risky ones.
+func AnalyzeHTTPRequest(req *HTTPRequest) RiskReport {
+	var report RiskReport
+	if req == nil || req.TLS == nil {
+		return report
 	}
 
-	// 2. Analyze FS
-	if grants.FS != nil {
-		for _, rule := range grants.FS.Rules {
-			if len(rule.Write) > 0 {
-				ruleStr := fmt.Sprintf("FS Write: %v", rule.Write)
-				addFactor(RiskHigh, "Filesystem write access", ruleStr)
-			}
-			if len(rule.Read) > 0 {
-				ruleStr := fmt.Sprintf("FS Read: %v", rule.Read)
-				addFactor(RiskMedium, "Filesystem read access", ruleStr)
-			}
+	add := func(id string, level RiskLevel, desc, rule string) {
+		score := defaultScore(level)
+		report.RiskFactors = append(report.RiskFactors, RiskFactor{
+			Level:       level,
+			Description: desc,
+			Rule:        rule,
+			RuleID:      id,
+			Score:       score,
+		})
+		report.Score += score
+		if level > report.Level {
+			report.Level = level
 		}
 	}
 
-	// 3. Analyze Exec
-	if grants.Exec != nil && len(grants.Exec.Commands) > 0 {
-		ruleStr := fmt.Sprintf("Exec: %v", grants.Exec.Commands)
-		addFactor(RiskCritical, "Arbitrary command execution", ruleStr)
+	if req.TLS.InsecureSkipVerify {
+		add("tls-insecure-skip-verify", RiskCritical, "TLS certificate verification disabled",
+			"TLS insecure_skip_verify: "+req.URL)
 	}
-
-	// 4. Analyze Env
-	if grants.Env != nil && len(grants.Env.Variables) > 0 {
-		ruleStr := fmt.Sprintf("Env: %v", grants.Env.Variables)
-		addFactor(RiskLow, "Environment variable access", ruleStr)
+	switch req.TLS.MinVersion {
+	case "SSL 3.0", "TLS 1.0", "TLS 1.1":
+		add("tls-legacy-version", RiskHigh, "Legacy TLS version allowed",
+			"TLS min_version "+req.TLS.MinVersion+": "+req.URL)
 	}
-
 	return report
 }
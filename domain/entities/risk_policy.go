@@ -0,0 +1,397 @@
+package entities
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Capability kinds a RiskRule can apply to.
+const (
+	RiskKindNetwork = "network"
+	RiskKindFS      = "fs"
+	RiskKindEnv     = "env"
+	RiskKindExec    = "exec"
+	RiskKindKV      = "kv"
+)
+
+// RiskPolicy is an ordered list of rules used by PolicyRiskAnalyzer.
+type RiskPolicy struct {
+	Rules []RiskRule `json:"rules"`
+}
+
+// RiskRule assigns a risk level to the granted items it matches.
+//
+// Rules are evaluated in order and the first rule of the item's kind that
+// matches applies, so specific rules go before general ones. A matching rule
+// with level "none" marks the item as riskless.
+type RiskRule struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`  // network, fs, env, exec or kv
+	Level       string    `json:"level"` // none, low, medium, high or critical
+	Description string    `json:"description,omitempty"`
+	Match       RiskMatch `json:"match,omitempty"`
+	// Score overrides the default score for Level.
+	Score int `json:"score,omitempty"`
+}
+
+// RiskMatch selects the granted items a RiskRule applies to. Only the fields
+// for the rule's kind are used, and an empty field matches everything.
+//
+// Patterns are matched against the granted values as written: "*" matches any
+// sequence of characters and "\*" matches a literal "*", so "\*" selects hosts
+// granted as "*". Command patterns also match the command's base name, so
+// "bash" matches "/bin/bash". Ports are numbers, ranges such as "8000-9000"
+// or "*", and match any granted port range that overlaps them.
+type RiskMatch struct {
+	Hosts      []string `json:"hosts,omitempty"`
+	Ports      []string `json:"ports,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	Access     string   `json:"access,omitempty"` // fs: read or write
+	Commands   []string `json:"commands,omitempty"`
+	Variables  []string `json:"vars,omitempty"`
+	Keys       []string `json:"keys,omitempty"`
+	Operations []string `json:"ops,omitempty"` // kv: read, write or read-write
+}
+
+// DefaultRiskPolicy returns the policy used by SimpleRiskAnalyzer: wildcard
+// hosts and exec are critical, filesystem writes high, other network access
+// and filesystem reads medium, environment access low and key-value access
+// unscored.
+func DefaultRiskPolicy() *RiskPolicy {
+	return &RiskPolicy{Rules: []RiskRule{
+		{
+			ID: "network-unrestricted", Kind: RiskKindNetwork, Level: "critical",
+			Description: "Unrestricted network access",
+			Match:       RiskMatch{Hosts: []string{`\*`, "0.0.0.0"}},
+		},
+		{ID: "network-outbound", Kind: RiskKindNetwork, Level: "medium", Description: "Outbound network access"},
+		{
+			ID: "fs-write", Kind: RiskKindFS, Level: "high",
+			Description: "Filesystem write access",
+			Match:       RiskMatch{Access: "write"},
+		},
+		{
+			ID: "fs-read", Kind: RiskKindFS, Level: "medium",
+			Description: "Filesystem read access",
+			Match:       RiskMatch{Access: "read"},
+		},
+		{ID: "exec-any", Kind: RiskKindExec, Level: "critical", Description: "Arbitrary command execution"},
+		{ID: "env-any", Kind: RiskKindEnv, Level: "low", Description: "Environment variable access"},
+	}}
+}
+
+// Validate reports the first invalid rule in the policy.
+func (p *RiskPolicy) Validate() error {
+	seen := make(map[string]bool, len(p.Rules))
+	for i, rule := range p.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("risk policy: rule %d: missing id", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("risk policy: rule %q: duplicate id", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		switch rule.Kind {
+		case RiskKindNetwork, RiskKindFS, RiskKindEnv, RiskKindExec, RiskKindKV:
+		default:
+			return fmt.Errorf("risk policy: rule %q: unknown kind %q", rule.ID, rule.Kind)
+		}
+		if _, err := ParseRiskLevel(rule.Level); err != nil {
+			return fmt.Errorf("risk policy: rule %q: %w", rule.ID, err)
+		}
+		if rule.Score < 0 {
+			return fmt.Errorf("risk policy: rule %q: negative score", rule.ID)
+		}
+		if a := rule.Match.Access; a != "" && a != "read" && a != "write" {
+			return fmt.Errorf("risk policy: rule %q: unknown access %q", rule.ID, a)
+		}
+		for _, op := range rule.Match.Operations {
+			if op != "read" && op != "write" && op != "read-write" {
+				return fmt.Errorf("risk policy: rule %q: unknown operation %q", rule.ID, op)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseRiskLevel parses a level name as printed by RiskLevel.String, ignoring case.
+func ParseRiskLevel(s string) (RiskLevel, error) {
+	for level := RiskNone; level <= RiskCritical; level++ {
+		if strings.EqualFold(s, level.String()) {
+			return level, nil
+		}
+	}
+	return RiskNone, fmt.Errorf("unknown risk level %q", s)
+}
+
+// defaultScore is the score of a factor whose rule sets none.
+func defaultScore(level RiskLevel) int {
+	switch level {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 4
+	case RiskHigh:
+		return 7
+	case RiskCritical:
+		return 10
+	default:
+		return 0
+	}
+}
+
+// PolicyRiskAnalyzer scores grants with the rules of a RiskPolicy.
+type PolicyRiskAnalyzer struct {
+	rules  []RiskRule
+	levels []RiskLevel
+}
+
+// NewPolicyRiskAnalyzer validates policy and returns an analyzer for it.
+func NewPolicyRiskAnalyzer(policy *RiskPolicy) (*PolicyRiskAnalyzer, error) {
+	if policy == nil {
+		policy = &RiskPolicy{}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	a := &PolicyRiskAnalyzer{
+		rules:  append([]RiskRule(nil), policy.Rules...),
+		levels: make([]RiskLevel, len(policy.Rules)),
+	}
+	for i, rule := range policy.Rules {
+		a.levels[i], _ = ParseRiskLevel(rule.Level)
+	}
+	return a, nil
+}
+
+// Analyze reports a factor for every group of granted items matched by the
+// same rule. Network rules are scored as a whole; filesystem paths, commands,
+// variables and keys are scored one by one and grouped per granted rule.
+// The report level is the highest factor level and its score the sum of the
+// factor scores.
+func (a *PolicyRiskAnalyzer) Analyze(grants *GrantSet) RiskReport {
+	report := RiskReport{Level: RiskNone}
+	if grants == nil {
+		return report
+	}
+
+	// add records a factor for each rule that matched some of items, in the
+	// order the items were granted.
+	add := func(items []string, match func(rule *RiskRule, item string) bool, format func(items []string) string, kind string) {
+		var order []int
+		groups := make(map[int][]string)
+		for _, item := range items {
+			i := a.first(kind, func(rule *RiskRule) bool { return match(rule, item) })
+			if i < 0 {
+				continue
+			}
+			if _, ok := groups[i]; !ok {
+				order = append(order, i)
+			}
+			groups[i] = append(groups[i], item)
+		}
+		for _, i := range order {
+			a.addFactor(&report, i, format(groups[i]))
+		}
+	}
+
+	if grants.Network != nil {
+		for _, rule := range grants.Network.Rules {
+			i := a.first(RiskKindNetwork, func(r *RiskRule) bool { return matchNetwork(&r.Match, rule) })
+			if i >= 0 {
+				a.addFactor(&report, i, fmt.Sprintf("Network: %s:%s", rule.Hosts, rule.Ports))
+			}
+		}
+	}
+
+	if grants.FS != nil {
+		for _, rule := range grants.FS.Rules {
+			for _, access := range []string{"write", "read"} {
+				paths, label := rule.Write, "FS Write"
+				if access == "read" {
+					paths, label = rule.Read, "FS Read"
+				}
+				add(paths, func(r *RiskRule, p string) bool {
+					return (r.Match.Access == "" || r.Match.Access == access) && matchAnyPattern(r.Match.Paths, p)
+				}, func(items []string) string {
+					return fmt.Sprintf("%s: %v", label, items)
+				}, RiskKindFS)
+			}
+		}
+	}
+
+	if grants.Exec != nil {
+		add(grants.Exec.Commands, func(r *RiskRule, cmd string) bool {
+			return matchAnyPattern(r.Match.Commands, cmd) || matchAnyPattern(r.Match.Commands, path.Base(cmd))
+		}, func(items []string) string {
+			return fmt.Sprintf("Exec: %v", items)
+		}, RiskKindExec)
+	}
+
+	if grants.Env != nil {
+		add(grants.Env.Variables, func(r *RiskRule, name string) bool {
+			return matchAnyPattern(r.Match.Variables, name)
+		}, func(items []string) string {
+			return fmt.Sprintf("Env: %v", items)
+		}, RiskKindEnv)
+	}
+
+	if grants.KV != nil {
+		for _, rule := range grants.KV.Rules {
+			add(rule.Keys, func(r *RiskRule, key string) bool {
+				return matchOperation(r.Match.Operations, rule.Operation) && matchAnyPattern(r.Match.Keys, key)
+			}, func(items []string) string {
+				return fmt.Sprintf("KV %s: %v", rule.Operation, items)
+			}, RiskKindKV)
+		}
+	}
+
+	return report
+}
+
+// first returns the index of the first rule of kind accepted by match, or -1.
+func (a *PolicyRiskAnalyzer) first(kind string, match func(rule *RiskRule) bool) int {
+	for i := range a.rules {
+		if a.rules[i].Kind == kind && match(&a.rules[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// addFactor records the factor for rule i, skipping rules with level none.
+func (a *PolicyRiskAnalyzer) addFactor(report *RiskReport, i int, ruleStr string) {
+	rule, level := &a.rules[i], a.levels[i]
+	if level == RiskNone {
+		return
+	}
+
+	score := rule.Score
+	if score == 0 {
+		score = defaultScore(level)
+	}
+	desc := rule.Description
+	if desc == "" {
+		desc = rule.ID
+	}
+
+	report.RiskFactors = append(report.RiskFactors, RiskFactor{
+		Level:       level,
+		Description: desc,
+		Rule:        ruleStr,
+		RuleID:      rule.ID,
+		Score:       score,
+	})
+	report.Score += score
+	if level > report.Level {
+		report.Level = level
+	}
+}
+
+// matchNetwork reports whether some granted host and some granted port of
+// rule match m.
+func matchNetwork(m *RiskMatch, rule NetworkRule) bool {
+	if len(m.Hosts) > 0 && !anyValue(rule.Hosts, func(h string) bool { return matchAnyPattern(m.Hosts, h) }) {
+		return false
+	}
+	if len(m.Ports) > 0 && !anyValue(rule.Ports, func(p string) bool { return matchAnyPort(m.Ports, p) }) {
+		return false
+	}
+	return true
+}
+
+// matchOperation reports whether the granted key-value operation matches any
+// of ops. A "read-write" grant matches rules for either operation.
+func matchOperation(ops []string, granted string) bool {
+	if len(ops) == 0 {
+		return true
+	}
+	for _, op := range ops {
+		if op == granted || granted == "read-write" {
+			return true
+		}
+	}
+	return false
+}
+
+// anyValue reports whether match accepts any of values.
+func anyValue(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyPattern reports whether s matches any of patterns, or patterns is empty.
+func matchAnyPattern(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	return anyValue(patterns, func(p string) bool { return matchPattern(p, s) })
+}
+
+// matchPattern matches s against pattern, where "*" matches any sequence of
+// characters and a backslash makes the next character literal.
+func matchPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchAnyPort reports whether the granted port range overlaps any of patterns.
+func matchAnyPort(patterns []string, granted string) bool {
+	glo, ghi, gok := parsePortRange(granted)
+	return anyValue(patterns, func(p string) bool {
+		lo, hi, ok := parsePortRange(p)
+		if !ok || !gok {
+			return p == granted
+		}
+		return lo <= ghi && glo <= hi
+	})
+}
+
+// parsePortRange parses a port, range or "*" into an inclusive range.
+func parsePortRange(s string) (lo, hi int, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return 0, 65535, true
+	}
+	first, last, isRange := strings.Cut(s, "-")
+	lo, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, false
+	}
+	hi = lo
+	if isRange {
+		if hi, err = strconv.Atoi(last); err != nil {
+			return 0, 0, false
+		}
+	}
+	return lo, hi, lo <= hi
+}
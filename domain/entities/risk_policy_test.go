@@ -0,0 +1,189 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securityPolicy = `{
+  "rules": [
+    {"id": "fs-shadow", "kind": "fs", "level": "critical", "description": "Reads password hashes", "match": {"access": "read", "paths": ["/etc/shadow", "/etc/gshadow"]}},
+    {"id": "fs-tmp-write", "kind": "fs", "level": "low", "match": {"access": "write", "paths": ["/tmp/*"]}},
+    {"id": "fs-write", "kind": "fs", "level": "high", "match": {"access": "write"}},
+    {"id": "fs-read", "kind": "fs", "level": "medium"},
+    {"id": "exec-shell", "kind": "exec", "level": "critical", "description": "Shell execution", "match": {"commands": ["bash", "sh"]}},
+    {"id": "exec-systemctl", "kind": "exec", "level": "medium", "match": {"commands": ["systemctl"]}},
+    {"id": "network-remote-admin", "kind": "network", "level": "high", "score": 8, "description": "Remote administration ports", "match": {"ports": ["22", "3389"]}},
+    {"id": "network-any", "kind": "network", "level": "medium"},
+    {"id": "kv-shared-write", "kind": "kv", "level": "medium", "match": {"ops": ["write"], "keys": ["shared/*"]}},
+    {"id": "kv-any", "kind": "kv", "level": "none"}
+  ]
+}`
+
+// parsePolicy decodes and validates a JSON policy.
+func parsePolicy(t *testing.T, data string) *entities.RiskPolicy {
+	t.Helper()
+	var policy entities.RiskPolicy
+	require.NoError(t, json.Unmarshal([]byte(data), &policy))
+	require.NoError(t, policy.Validate())
+	return &policy
+}
+
+func TestRiskPolicy_Validate(t *testing.T) {
+	invalid := map[string]entities.RiskRule{
+		"missing id":    {Kind: "env", Level: "low"},
+		"unknown kind":  {ID: "a", Kind: "disk", Level: "low"},
+		"unknown level": {ID: "a", Kind: "env", Level: "severe"},
+		"bad access":    {ID: "a", Kind: "fs", Level: "low", Match: entities.RiskMatch{Access: "execute"}},
+		"bad operation": {ID: "a", Kind: "kv", Level: "low", Match: entities.RiskMatch{Operations: []string{"delete"}}},
+		"negative":      {ID: "a", Kind: "env", Level: "low", Score: -1},
+	}
+	for name, rule := range invalid {
+		policy := entities.RiskPolicy{Rules: []entities.RiskRule{rule}}
+		assert.Error(t, policy.Validate(), name)
+	}
+
+	duplicate := entities.RiskPolicy{Rules: []entities.RiskRule{
+		{ID: "a", Kind: "env", Level: "low"},
+		{ID: "a", Kind: "exec", Level: "low"},
+	}}
+	assert.Error(t, duplicate.Validate(), "duplicate id")
+	assert.NoError(t, entities.DefaultRiskPolicy().Validate())
+}
+
+func TestPolicyRiskAnalyzer(t *testing.T) {
+	policy := parsePolicy(t, securityPolicy)
+	analyzer, err := entities.NewPolicyRiskAnalyzer(policy)
+	require.NoError(t, err)
+
+	factors := func(report entities.RiskReport) map[string]entities.RiskFactor {
+		byID := make(map[string]entities.RiskFactor)
+		for _, f := range report.RiskFactors {
+			byID[f.RuleID] = f
+		}
+		return byID
+	}
+
+	t.Run("filesystem", func(t *testing.T) {
+		report := analyzer.Analyze(&entities.GrantSet{
+			FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{
+				{Read: []string{"/etc/shadow", "/etc/hosts"}, Write: []string{"/tmp/out.json"}},
+			}},
+		})
+		assert.Equal(t, entities.RiskCritical, report.Level)
+		byID := factors(report)
+		require.Len(t, byID, 3)
+		assert.Equal(t, "FS Read: [/etc/shadow]", byID["fs-shadow"].Rule)
+		assert.Equal(t, "Reads password hashes", byID["fs-shadow"].Description)
+		assert.Equal(t, "FS Read: [/etc/hosts]", byID["fs-read"].Rule)
+		assert.Equal(t, entities.RiskLow, byID["fs-tmp-write"].Level)
+		assert.Equal(t, "fs-tmp-write", byID["fs-tmp-write"].Description, "falls back to the rule ID")
+		assert.Equal(t, 10+4+1, report.Score)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		report := analyzer.Analyze(&entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+		})
+		assert.Equal(t, entities.RiskMedium, report.Level)
+		assert.Equal(t, "exec-systemctl", report.RiskFactors[0].RuleID)
+
+		report = analyzer.Analyze(&entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl", "/bin/bash", "sh"}},
+		})
+		assert.Equal(t, entities.RiskCritical, report.Level)
+		assert.Equal(t, "Exec: [/bin/bash sh]", factors(report)["exec-shell"].Rule)
+
+		report = analyzer.Analyze(&entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/ls"}},
+		})
+		assert.Equal(t, entities.RiskNone, report.Level, "no rule matches")
+		assert.Empty(t, report.RiskFactors)
+	})
+
+	t.Run("network ports", func(t *testing.T) {
+		report := analyzer.Analyze(&entities.GrantSet{
+			Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+				{Hosts: []string{"bastion.example.com"}, Ports: []string{"22"}},
+				{Hosts: []string{"win.example.com"}, Ports: []string{"3000-4000"}},
+				{Hosts: []string{"api.example.com"}, Ports: []string{"443"}},
+			}},
+		})
+		require.Len(t, report.RiskFactors, 3)
+		assert.Equal(t, "network-remote-admin", report.RiskFactors[0].RuleID)
+		assert.Equal(t, 8, report.RiskFactors[0].Score)
+		assert.Equal(t, "network-remote-admin", report.RiskFactors[1].RuleID, "range overlapping 3389")
+		assert.Equal(t, "network-any", report.RiskFactors[2].RuleID)
+		assert.Equal(t, entities.RiskHigh, report.Level)
+		assert.Equal(t, 8+8+4, report.Score)
+	})
+
+	t.Run("key-value", func(t *testing.T) {
+		report := analyzer.Analyze(&entities.GrantSet{
+			KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+				{Operation: "read-write", Keys: []string{"shared/config", "cache/*"}},
+				{Operation: "read", Keys: []string{"shared/other"}},
+			}},
+		})
+		require.Len(t, report.RiskFactors, 1, "rules with level none add no factor")
+		assert.Equal(t, "KV read-write: [shared/config]", report.RiskFactors[0].Rule)
+		assert.Equal(t, entities.RiskMedium, report.Level)
+	})
+}
+
+func TestPolicyRiskAnalyzer_LiteralWildcard(t *testing.T) {
+	policy := &entities.RiskPolicy{Rules: []entities.RiskRule{
+		{ID: "wildcard", Kind: entities.RiskKindNetwork, Level: "critical", Match: entities.RiskMatch{Hosts: []string{`\*`}}},
+		{ID: "subdomains", Kind: entities.RiskKindNetwork, Level: "high", Match: entities.RiskMatch{Hosts: []string{`\*.*`}}},
+		{ID: "other", Kind: entities.RiskKindNetwork, Level: "low"},
+	}}
+	analyzer, err := entities.NewPolicyRiskAnalyzer(policy)
+	require.NoError(t, err)
+
+	for host, want := range map[string]string{"*": "wildcard", "*.example.com": "subdomains", "api.example.com": "other"} {
+		report := analyzer.Analyze(&entities.GrantSet{
+			Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{host}, Ports: []string{"443"}}}},
+		})
+		require.Len(t, report.RiskFactors, 1)
+		assert.Equal(t, want, report.RiskFactors[0].RuleID, host)
+	}
+}
+
+func TestDefaultRiskPolicy_MatchesSimpleAnalyzer(t *testing.T) {
+	grants := &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{"0.0.0.0"}, Ports: []string{"80"}},
+			{Hosts: []string{"api.example.com"}, Ports: []string{"443"}},
+		}},
+		FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{
+			{Read: []string{"/etc/hosts"}, Write: []string{"/tmp/a", "/var/b"}},
+		}},
+		Exec: &entities.ExecCapability{Commands: []string{"ls", "cat"}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"HOME"}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "write", Keys: []string{"*"}},
+		}},
+	}
+
+	analyzer, err := entities.NewPolicyRiskAnalyzer(entities.DefaultRiskPolicy())
+	require.NoError(t, err)
+	report := analyzer.Analyze(grants)
+	assert.Equal(t, entities.NewSimpleRiskAnalyzer().Analyze(grants), report)
+
+	var rules []string
+	for _, f := range report.RiskFactors {
+		rules = append(rules, f.Level.String()+" "+f.Rule)
+	}
+	assert.Equal(t, []string{
+		"CRITICAL Network: [0.0.0.0]:[80]",
+		"MEDIUM Network: [api.example.com]:[443]",
+		"HIGH FS Write: [/tmp/a /var/b]",
+		"MEDIUM FS Read: [/etc/hosts]",
+		"CRITICAL Exec: [ls cat]",
+		"LOW Env: [HOME]",
+	}, rules, "same factors as the fixed heuristics; key-value grants are not scored")
+}
@@ -170,3 +170,49 @@ func TestRisk_String(t *testing.T) {
 	assert.Equal(t, "CRITICAL", entities.RiskCritical.String())
 	assert.Equal(t, "NONE", entities.RiskNone.String())
 }
+
+func TestAnalyzeOperations(t *testing.T) {
+	manifest := &entities.Manifest{
+		Services: map[string]entities.ServiceManifest{
+			"systemd": {
+				Name: "systemd",
+				Operations: []entities.OperationManifest{
+					{Name: "status"},
+					{
+						Name: "restart",
+						Capabilities: &entities.GrantSet{
+							Exec: &entities.ExecCapability{Commands: []string{"systemctl"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	reports := entities.AnalyzeOperations(entities.NewSimpleRiskAnalyzer(), manifest)
+
+	assert.Len(t, reports, 1)
+	assert.Equal(t, entities.RiskCritical, reports["systemd/restart"].Level)
+}
+
+func TestAnalyzeHTTPRequest(t *testing.T) {
+	report := entities.AnalyzeHTTPRequest(&entities.HTTPRequest{
+		URL: "https://internal.example.com",
+		TLS: &entities.HTTPTLSConfig{MinVersion: "TLS 1.0", InsecureSkipVerify: true},
+	})
+	assert.Equal(t, entities.RiskCritical, report.Level)
+	assert.Equal(t, 10+7, report.Score)
+	assert.Len(t, report.RiskFactors, 2)
+	assert.Equal(t, "tls-insecure-skip-verify", report.RiskFactors[0].RuleID)
+	assert.Equal(t, "TLS insecure_skip_verify: https://internal.example.com", report.RiskFactors[0].Rule)
+	assert.Equal(t, "tls-legacy-version", report.RiskFactors[1].RuleID)
+
+	report = entities.AnalyzeHTTPRequest(&entities.HTTPRequest{
+		URL: "https://example.com",
+		TLS: &entities.HTTPTLSConfig{MinVersion: "TLS 1.3", RootCAs: "PEM"},
+	})
+	assert.Equal(t, entities.RiskNone, report.Level)
+	assert.Empty(t, report.RiskFactors)
+
+	assert.Empty(t, entities.AnalyzeHTTPRequest(&entities.HTTPRequest{URL: "https://example.com"}).RiskFactors)
+}
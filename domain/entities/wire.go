@@ -2,7 +2,10 @@
 // These types serve dual purpose: domain entities AND JSON wire format DTOs.
 package entities
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ContextWire is the JSON wire format for context.Context propagation.
 type ContextWire struct {
@@ -10,6 +13,20 @@ type ContextWire struct {
 	RequestID string     `json:"request_id,omitempty"`
 	TimeoutMs int64      `json:"timeout_ms,omitempty"`
 	Canceled  bool       `json:"canceled,omitempty"`
+	// Cancelable is set by hosts that implement the reglet_host
+	// context_canceled import, allowing the plugin to poll it during the
+	// invocation.
+	Cancelable bool `json:"cancelable,omitempty"`
+}
+
+// InvocationRequest is the JSON wire format the host sends to _observe to
+// invoke a registered service operation. Context carries the host's
+// deadline, cancellation and request ID for the invocation.
+type InvocationRequest struct {
+	Service   string          `json:"service,omitempty"`
+	Operation string          `json:"operation,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	Context   ContextWire     `json:"context"`
 }
 
 // DNSRequest is the JSON wire format for a DNS lookup request.
@@ -40,16 +57,89 @@ type HTTPRequest struct {
 	URL     string              `json:"url"`
 	Body    string              `json:"body,omitempty"`
 	Context ContextWire         `json:"context"`
+	// MaxRedirects is the maximum number of redirects the host follows; 0
+	// disables following. Absent leaves the limit to the host.
+	MaxRedirects *int           `json:"max_redirects,omitempty"`
+	TLS          *HTTPTLSConfig `json:"tls,omitempty"`
+	// StreamBody asks the host to keep the response body and return a
+	// BodyHandle for reading it in chunks instead of inlining it in Body.
+	StreamBody bool `json:"stream_body,omitempty"`
+}
+
+// HTTPTLSConfig is the JSON wire format for the TLS settings of an HTTP request.
+// Certificates and keys are PEM-encoded.
+type HTTPTLSConfig struct {
+	// MinVersion is a TLS version name as reported in responses, e.g. "TLS 1.2".
+	MinVersion         string `json:"min_version,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	RootCAs            string `json:"root_cas,omitempty"`
+	ClientCert         string `json:"client_cert,omitempty"`
+	ClientKey          string `json:"client_key,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
 }
 
-// HTTPResponse is the JSON wire format for an HTTP response.
+// HTTPResponse is the JSON wire format for an HTTP response. Redirects lists
+// every redirect the host followed, in order; the response itself answers
+// the last hop's Location.
 type HTTPResponse struct {
 	Headers       map[string][]string `json:"headers,omitempty"`
 	Error         *ErrorDetail        `json:"error,omitempty"`
 	Body          string              `json:"body,omitempty"`
 	Proto         string              `json:"proto,omitempty"`
+	Redirects     []HTTPRedirect      `json:"redirects,omitempty"`
+	Timings       *HTTPTimings        `json:"timings,omitempty"`
 	StatusCode    int                 `json:"status_code"`
 	BodyTruncated bool                `json:"body_truncated,omitempty"`
+	// BodyHandle identifies a streamed body to read with http_body_read and
+	// release with http_body_close. Zero means the body, if any, is in Body.
+	BodyHandle uint64 `json:"body_handle,omitempty"`
+}
+
+// HTTPBodyReadRequest is the JSON wire format for reading the next chunk of
+// a streamed response body.
+type HTTPBodyReadRequest struct {
+	Handle   uint64 `json:"handle"`
+	MaxBytes int    `json:"max_bytes"`
+}
+
+// HTTPBodyReadResponse is the JSON wire format for a chunk of a streamed
+// response body. Data is base64-encoded and at most MaxBytes long once
+// decoded; EOF reports that no data follows it.
+type HTTPBodyReadResponse struct {
+	Error *ErrorDetail `json:"error,omitempty"`
+	Data  string       `json:"data,omitempty"`
+	EOF   bool         `json:"eof,omitempty"`
+}
+
+// HTTPBodyCloseRequest is the JSON wire format for releasing a streamed
+// response body.
+type HTTPBodyCloseRequest struct {
+	Handle uint64 `json:"handle"`
+}
+
+// HTTPBodyCloseResponse is the JSON wire format for the result of releasing
+// a streamed response body.
+type HTTPBodyCloseResponse struct {
+	Error *ErrorDetail `json:"error,omitempty"`
+}
+
+// HTTPRedirect is the JSON wire format for one redirect hop.
+type HTTPRedirect struct {
+	URL        string `json:"url"`
+	Location   string `json:"location,omitempty"`
+	StatusCode int    `json:"status_code"`
+}
+
+// HTTPTimings is the JSON wire format for the phases of an HTTP request as
+// measured by the host. Phases that did not happen, such as DNS for an IP
+// address or TLS for plain HTTP, are zero. With redirects, DNS, connect and
+// TLS are summed over all connections and first byte is for the final request.
+type HTTPTimings struct {
+	DNSMs       int64 `json:"dns_ms"`
+	ConnectMs   int64 `json:"connect_ms"`
+	TLSMs       int64 `json:"tls_ms"`
+	FirstByteMs int64 `json:"first_byte_ms"`
+	TotalMs     int64 `json:"total_ms"`
 }
 
 // TCPRequest is the JSON wire format for a TCP connection request.
@@ -119,3 +209,47 @@ type ExecResponse struct {
 	DurationMs int64        `json:"duration_ms,omitempty"`
 	IsTimeout  bool         `json:"is_timeout,omitempty"`
 }
+
+// KVRequest is the JSON wire format for a key-value store request.
+// Op is one of "get", "set", "delete", "list" or "cas".
+type KVRequest struct {
+	Op       string      `json:"op"`
+	Key      string      `json:"key,omitempty"`
+	Prefix   string      `json:"prefix,omitempty"`
+	Value    []byte      `json:"value,omitempty"`
+	Context  ContextWire `json:"context"`
+	TTLMs    int64       `json:"ttl_ms,omitempty"`
+	Revision uint64      `json:"revision,omitempty"`
+}
+
+// KVResponse is the JSON wire format for a key-value store response.
+type KVResponse struct {
+	Entry   *KVEntry     `json:"entry,omitempty"`
+	Error   *ErrorDetail `json:"error,omitempty"`
+	Keys    []string     `json:"keys,omitempty"`
+	Swapped bool         `json:"swapped,omitempty"`
+}
+
+// KVEntry is the JSON wire format for a stored key-value pair.
+type KVEntry struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Key       string     `json:"key"`
+	Value     []byte     `json:"value,omitempty"`
+	Revision  uint64     `json:"revision"`
+}
+
+// EnvRequest is the JSON wire format for an environment variable request.
+// Op is "lookup" (one variable) or "list" (all variables visible to the plugin).
+type EnvRequest struct {
+	Op      string      `json:"op"`
+	Name    string      `json:"name,omitempty"`
+	Context ContextWire `json:"context"`
+}
+
+// EnvResponse is the JSON wire format for an environment variable response.
+type EnvResponse struct {
+	Variables map[string]string `json:"variables,omitempty"`
+	Error     *ErrorDetail      `json:"error,omitempty"`
+	Value     string            `json:"value,omitempty"`
+	Found     bool              `json:"found,omitempty"`
+}
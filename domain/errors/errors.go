@@ -5,6 +5,8 @@ package errors
 import (
 	stdErrors "errors"
 	"fmt"
+	"io/fs"
+	"strings"
 	"time"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
@@ -95,8 +97,9 @@ func (e *TimeoutError) ToErrorDetail() *entities.ErrorDetail {
 
 // CapabilityError represents a capability check failure.
 type CapabilityError struct {
-	Required string // Required capability (e.g., "network:outbound", "exec")
-	Pattern  string // Optional: specific pattern that was denied
+	Missing  *entities.GrantSet // Optional: the grants that were not declared
+	Required string             // Required capability (e.g., "network:outbound", "exec")
+	Pattern  string             // Optional: specific pattern that was denied
 }
 
 func (e *CapabilityError) Error() string {
@@ -108,20 +111,36 @@ func (e *CapabilityError) Error() string {
 
 // ToErrorDetail implements DetailedError.
 func (e *CapabilityError) ToErrorDetail() *entities.ErrorDetail {
-	return &entities.ErrorDetail{Message: e.Error(), Type: "capability", Code: e.Required}
+	detail := &entities.ErrorDetail{Message: e.Error(), Type: "capability", Code: e.Required}
+	if e.Missing != nil {
+		detail.Details = map[string]any{"missing": e.Missing}
+	}
+	return detail
 }
 
 // ConfigError represents a configuration validation error.
+// Violations lists every offending field when the config was checked against a schema.
 type ConfigError struct {
-	Err   error
-	Field string
+	Err        error
+	Field      string
+	Violations []entities.ValidationError
 }
 
 func (e *ConfigError) Error() string {
+	var msg string
 	if e.Field != "" {
-		return fmt.Sprintf("config validation failed for field '%s': %v", e.Field, e.Err)
+		msg = fmt.Sprintf("config validation failed for field '%s': %v", e.Field, e.Err)
+	} else {
+		msg = fmt.Sprintf("config validation failed: %v", e.Err)
 	}
-	return fmt.Sprintf("config validation failed: %v", e.Err)
+	if len(e.Violations) > 0 {
+		parts := make([]string, len(e.Violations))
+		for i, v := range e.Violations {
+			parts[i] = fmt.Sprintf("%s: %s", v.Field, v.Message)
+		}
+		msg = fmt.Sprintf("%s (%s)", msg, strings.Join(parts, "; "))
+	}
+	return msg
 }
 
 func (e *ConfigError) Unwrap() error {
@@ -130,7 +149,23 @@ func (e *ConfigError) Unwrap() error {
 
 // ToErrorDetail implements DetailedError.
 func (e *ConfigError) ToErrorDetail() *entities.ErrorDetail {
-	return &entities.ErrorDetail{Message: e.Error(), Type: "config", Code: e.Field}
+	detail := &entities.ErrorDetail{Message: e.Error(), Type: "config", Code: e.Field}
+	if len(e.Violations) > 0 {
+		violations := make([]map[string]any, len(e.Violations))
+		for i, v := range e.Violations {
+			violations[i] = map[string]any{"field": v.Field, "message": v.Message}
+		}
+		detail.Details = map[string]any{"violations": violations}
+	}
+	return detail
+}
+
+// NewValidationConfigError builds a ConfigError from a failed ValidationResult.
+func NewValidationConfigError(result entities.ValidationResult) *ConfigError {
+	return &ConfigError{
+		Err:        fmt.Errorf("%d field(s) failed schema validation", len(result.Errors)),
+		Violations: result.Errors,
+	}
 }
 
 // ExecError represents a command execution error.
@@ -265,6 +300,31 @@ func (e *TCPError) ToErrorDetail() *entities.ErrorDetail {
 	return detail
 }
 
+// FileSystemError represents a filesystem operation failure.
+type FileSystemError struct {
+	Err       error
+	Operation string // "read", "stat", "readdir" or "glob"
+	Path      string
+}
+
+func (e *FileSystemError) Error() string {
+	return fmt.Sprintf("fs %s %s failed: %v", e.Operation, e.Path, e.Err)
+}
+
+func (e *FileSystemError) Unwrap() error {
+	return e.Err
+}
+
+// ToErrorDetail implements DetailedError.
+func (e *FileSystemError) ToErrorDetail() *entities.ErrorDetail {
+	return &entities.ErrorDetail{
+		Message:    e.Error(),
+		Type:       "filesystem",
+		Code:       "fs_" + e.Operation,
+		IsNotFound: stdErrors.Is(e.Err, fs.ErrNotExist),
+	}
+}
+
 // SchemaError represents a schema generation or validation error.
 type SchemaError struct {
 	Err  error
@@ -323,3 +383,54 @@ func (e *WireFormatError) Unwrap() error {
 func (e *WireFormatError) ToErrorDetail() *entities.ErrorDetail {
 	return &entities.ErrorDetail{Message: e.Error(), Type: "internal", Code: "wire_format"}
 }
+
+// UnknownServiceError represents an invocation of a service that is not registered.
+type UnknownServiceError struct {
+	Service   string
+	Available []string
+}
+
+func (e *UnknownServiceError) Error() string {
+	if len(e.Available) > 0 {
+		return fmt.Sprintf("unknown service %q (available: %s)", e.Service, strings.Join(e.Available, ", "))
+	}
+	return fmt.Sprintf("unknown service %q", e.Service)
+}
+
+// ToErrorDetail implements DetailedError.
+func (e *UnknownServiceError) ToErrorDetail() *entities.ErrorDetail {
+	return &entities.ErrorDetail{
+		Message:    e.Error(),
+		Type:       "config",
+		Code:       "unknown_service",
+		IsNotFound: true,
+		Details:    map[string]any{"service": e.Service, "available": e.Available},
+	}
+}
+
+// UnknownOperationError represents an invocation of an operation that is not
+// registered on an existing service.
+type UnknownOperationError struct {
+	Service   string
+	Operation string
+	Available []string
+}
+
+func (e *UnknownOperationError) Error() string {
+	if len(e.Available) > 0 {
+		return fmt.Sprintf("unknown operation %q on service %q (available: %s)",
+			e.Operation, e.Service, strings.Join(e.Available, ", "))
+	}
+	return fmt.Sprintf("unknown operation %q on service %q", e.Operation, e.Service)
+}
+
+// ToErrorDetail implements DetailedError.
+func (e *UnknownOperationError) ToErrorDetail() *entities.ErrorDetail {
+	return &entities.ErrorDetail{
+		Message:    e.Error(),
+		Type:       "config",
+		Code:       "unknown_operation",
+		IsNotFound: true,
+		Details:    map[string]any{"service": e.Service, "operation": e.Operation, "available": e.Available},
+	}
+}
@@ -3,9 +3,11 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"testing"
 	"time"
 
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,6 +83,19 @@ func TestCapabilityError_NoPattern(t *testing.T) {
 	}
 
 	assert.Equal(t, "missing capability: exec", err.Error())
+	assert.Nil(t, err.ToErrorDetail().Details)
+}
+
+func TestCapabilityError_Missing(t *testing.T) {
+	missing := &entities.GrantSet{
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+	}
+	err := &CapabilityError{Required: "exec", Pattern: "/usr/bin/systemctl", Missing: missing}
+
+	detail := err.ToErrorDetail()
+	assert.Equal(t, "capability", detail.Type)
+	assert.Equal(t, "exec", detail.Code)
+	assert.Equal(t, missing, detail.Details["missing"])
 }
 
 func TestConfigError(t *testing.T) {
@@ -251,6 +266,25 @@ func TestTCPError_Timeout(t *testing.T) {
 	assert.True(t, err.Timeout())
 }
 
+func TestFileSystemError(t *testing.T) {
+	err := &FileSystemError{
+		Operation: "read",
+		Path:      "/etc/ssh/sshd_config",
+		Err:       fs.ErrNotExist,
+	}
+
+	assert.Equal(t, "fs read /etc/ssh/sshd_config failed: file does not exist", err.Error())
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	detail := err.ToErrorDetail()
+	assert.Equal(t, "filesystem", detail.Type)
+	assert.Equal(t, "fs_read", detail.Code)
+	assert.True(t, detail.IsNotFound)
+
+	err.Err = fmt.Errorf("permission denied")
+	assert.False(t, err.ToErrorDetail().IsNotFound)
+}
+
 func TestSchemaError(t *testing.T) {
 	baseErr := fmt.Errorf("unsupported type")
 	err := &SchemaError{
@@ -332,3 +366,43 @@ func TestErrorUnwrapping(t *testing.T) {
 		})
 	}
 }
+
+func TestUnknownServiceError(t *testing.T) {
+	err := &UnknownServiceError{Service: "ftp", Available: []string{"dns", "http"}}
+
+	assert.Equal(t, `unknown service "ftp" (available: dns, http)`, err.Error())
+
+	detail := ToErrorDetail(err)
+	assert.Equal(t, "config", detail.Type)
+	assert.Equal(t, "unknown_service", detail.Code)
+	assert.True(t, detail.IsNotFound)
+}
+
+func TestUnknownOperationError(t *testing.T) {
+	err := &UnknownOperationError{Service: "dns", Operation: "transfer"}
+
+	assert.Equal(t, `unknown operation "transfer" on service "dns"`, err.Error())
+
+	detail := ToErrorDetail(err)
+	assert.Equal(t, "config", detail.Type)
+	assert.Equal(t, "unknown_operation", detail.Code)
+	assert.True(t, detail.IsNotFound)
+}
+
+func TestConfigError_Violations(t *testing.T) {
+	err := NewValidationConfigError(entities.ValidationResult{
+		Errors: []entities.ValidationError{
+			{Field: "url", Message: "is required"},
+			{Field: "servers[0].port", Message: "must be <= 65535"},
+		},
+	})
+
+	assert.Contains(t, err.Error(), "url: is required; servers[0].port: must be <= 65535")
+
+	detail := err.ToErrorDetail()
+	assert.Equal(t, "config", detail.Type)
+	violations, ok := detail.Details["violations"].([]map[string]any)
+	require.True(t, ok)
+	assert.Len(t, violations, 2)
+	assert.Equal(t, "servers[0].port", violations[1]["field"])
+}
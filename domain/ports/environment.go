@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+)
+
+// Environment defines the interface for reading host environment variables,
+// such as proxy settings or a cloud region.
+// Infrastructure adapters implement this to provide environment access.
+type Environment interface {
+	// Lookup returns the value of the named variable and whether it is set.
+	Lookup(ctx context.Context, name string) (string, bool, error)
+	// List returns the variables visible to the plugin, keyed by name.
+	List(ctx context.Context) (map[string]string, error)
+}
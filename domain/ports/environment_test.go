@@ -0,0 +1,60 @@
+package ports
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockEnvironment is a mock implementation of Environment for testing.
+type MockEnvironment struct {
+	LookupFunc func(ctx context.Context, name string) (string, bool, error)
+	ListFunc   func(ctx context.Context) (map[string]string, error)
+}
+
+func (m *MockEnvironment) Lookup(ctx context.Context, name string) (string, bool, error) {
+	if m.LookupFunc != nil {
+		return m.LookupFunc(ctx, name)
+	}
+	return "", false, nil
+}
+
+func (m *MockEnvironment) List(ctx context.Context) (map[string]string, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx)
+	}
+	return map[string]string{}, nil
+}
+
+// Compile-time interface check
+var _ Environment = (*MockEnvironment)(nil)
+
+func TestMockEnvironment_ImplementsInterface(t *testing.T) {
+	var env Environment = &MockEnvironment{}
+	require.NotNil(t, env)
+}
+
+func TestMockEnvironment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default behavior", func(t *testing.T) {
+		mock := &MockEnvironment{}
+		_, ok, err := mock.Lookup(ctx, "AWS_REGION")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("custom behavior", func(t *testing.T) {
+		mock := &MockEnvironment{
+			LookupFunc: func(ctx context.Context, name string) (string, bool, error) {
+				return "eu-west-1", name == "AWS_REGION", nil
+			},
+		}
+		value, ok, err := mock.Lookup(ctx, "AWS_REGION")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "eu-west-1", value)
+	})
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"io/fs"
+	"time"
+)
+
+// FileSystem defines the interface for read-only access to host files.
+// Infrastructure adapters implement this to provide filesystem functionality.
+type FileSystem interface {
+	// ReadFile returns the contents of the file at path. Files larger than
+	// maxBytes are rejected; a maxBytes of 0 or less means no limit.
+	ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error)
+	// Stat returns information about the file or directory at path.
+	Stat(ctx context.Context, path string) (*FileInfo, error)
+	// ReadDir returns the entries of the directory at path, sorted by name.
+	ReadDir(ctx context.Context, path string) ([]FileInfo, error)
+	// Glob returns the paths matching pattern, using path.Match syntax.
+	Glob(ctx context.Context, pattern string) ([]string, error)
+}
+
+// FileInfo describes a file or directory.
+type FileInfo struct {
+	ModTime time.Time
+	Name    string
+	Path    string
+	Size    int64
+	Mode    fs.FileMode
+	IsDir   bool
+}
@@ -0,0 +1,81 @@
+package ports
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockFileSystem is a mock implementation of FileSystem for testing.
+type MockFileSystem struct {
+	ReadFileFunc func(ctx context.Context, path string, maxBytes int64) ([]byte, error)
+	StatFunc     func(ctx context.Context, path string) (*FileInfo, error)
+	ReadDirFunc  func(ctx context.Context, path string) ([]FileInfo, error)
+	GlobFunc     func(ctx context.Context, pattern string) ([]string, error)
+}
+
+func (m *MockFileSystem) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	if m.ReadFileFunc != nil {
+		return m.ReadFileFunc(ctx, path, maxBytes)
+	}
+	return []byte("default content"), nil
+}
+
+func (m *MockFileSystem) Stat(ctx context.Context, path string) (*FileInfo, error) {
+	if m.StatFunc != nil {
+		return m.StatFunc(ctx, path)
+	}
+	return &FileInfo{Name: "file", Path: path, Size: 15}, nil
+}
+
+func (m *MockFileSystem) ReadDir(ctx context.Context, path string) ([]FileInfo, error) {
+	if m.ReadDirFunc != nil {
+		return m.ReadDirFunc(ctx, path)
+	}
+	return nil, nil
+}
+
+func (m *MockFileSystem) Glob(ctx context.Context, pattern string) ([]string, error) {
+	if m.GlobFunc != nil {
+		return m.GlobFunc(ctx, pattern)
+	}
+	return nil, nil
+}
+
+// Compile-time interface check
+var _ FileSystem = (*MockFileSystem)(nil)
+
+func TestMockFileSystem_ImplementsInterface(t *testing.T) {
+	var fsys FileSystem = &MockFileSystem{}
+	require.NotNil(t, fsys)
+}
+
+func TestMockFileSystem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default behavior", func(t *testing.T) {
+		mock := &MockFileSystem{}
+
+		data, err := mock.ReadFile(ctx, "/etc/hosts", 1024)
+		require.NoError(t, err)
+		assert.Equal(t, "default content", string(data))
+
+		info, err := mock.Stat(ctx, "/etc/hosts")
+		require.NoError(t, err)
+		assert.Equal(t, "/etc/hosts", info.Path)
+	})
+
+	t.Run("custom behavior", func(t *testing.T) {
+		mock := &MockFileSystem{
+			GlobFunc: func(ctx context.Context, pattern string) ([]string, error) {
+				return []string{"/etc/sysctl.d/10-a.conf"}, nil
+			},
+		}
+
+		matches, err := mock.Glob(ctx, "/etc/sysctl.d/*.conf")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/etc/sysctl.d/10-a.conf"}, matches)
+	})
+}
@@ -2,6 +2,9 @@ package ports
 
 import (
 	"context"
+	"io"
+	"net/url"
+	"time"
 )
 
 // HTTPClient defines the interface for HTTP operations.
@@ -19,17 +22,89 @@ type HTTPClient interface {
 
 // HTTPRequest represents an HTTP request.
 type HTTPRequest struct {
-	Method  string
-	URL     string
+	Method string
+	URL    string
+	// Headers holds single-valued headers.
 	Headers map[string]string
-	Body    []byte
-	Timeout int // milliseconds
+	// HeaderValues holds headers that may repeat, such as several Accept
+	// values. Names are case-insensitive, and values are sent after any value
+	// for the same name in Headers.
+	HeaderValues map[string][]string
+	// Query holds parameters appended to the query string of URL.
+	Query url.Values
+	Body  []byte
+	// BodyReader supplies the body when Body is empty. The host call carries
+	// the body in a single message, so it is read to the end before sending.
+	BodyReader io.Reader
+	Timeout    int // milliseconds
+	// MaxRedirects is the maximum number of redirects to follow. Zero
+	// disables following redirects; nil leaves the limit to the client.
+	MaxRedirects *int
+	// TLS configures the TLS connection for https URLs. Nil leaves it to the client.
+	TLS *TLSConfig
+	// StreamBody returns the response body as HTTPResponse.BodyReader instead
+	// of Body, so that bodies larger than the client's buffer limit can be
+	// read in chunks. A client that cannot stream returns its buffered body
+	// as BodyReader instead. The caller must close BodyReader.
+	StreamBody bool
+	// AllowTruncatedBody returns a buffered body cut at the client's size
+	// limit, with HTTPResponse.BodyTruncated set, instead of an error.
+	AllowTruncatedBody bool
+}
+
+// TLSConfig holds the TLS settings of an HTTP request. PEM fields hold the
+// PEM-encoded data itself, not a file path.
+type TLSConfig struct {
+	// ServerName overrides the name used for SNI and certificate verification.
+	ServerName string
+	// RootCAs is a PEM bundle of CA certificates trusted instead of the system roots.
+	RootCAs []byte
+	// ClientCert and ClientKey are the PEM-encoded client certificate chain
+	// and private key for mutual TLS. Both or neither must be set.
+	ClientCert []byte
+	ClientKey  []byte
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS12. Zero uses
+	// the host's default.
+	MinVersion uint16
+	// InsecureSkipVerify disables certificate verification. Hosts and risk
+	// analysis treat it as a critical risk; prefer RootCAs for private CAs.
+	InsecureSkipVerify bool
 }
 
 // HTTPResponse represents an HTTP response.
 type HTTPResponse struct {
-	Headers    map[string][]string
-	Proto      string
-	Body       []byte
+	Headers map[string][]string
+	Proto   string
+	Body    []byte
+	// BodyReader streams the body when the request set StreamBody; Body is
+	// then empty. It is nil otherwise.
+	BodyReader io.ReadCloser
+	// BodyTruncated reports that Body was cut at the client's size limit,
+	// which only happens when the request set AllowTruncatedBody.
+	BodyTruncated bool
+	// Redirects lists the redirects followed before this response, in order.
+	Redirects []HTTPRedirect
+	// Timings is the per-phase timing of the request, or nil if the client
+	// does not measure it.
+	Timings    *HTTPTimings
 	StatusCode int
 }
+
+// HTTPRedirect is one redirect hop: the URL requested, the redirect status
+// it answered with, and the Location it pointed to.
+type HTTPRedirect struct {
+	URL        string
+	Location   string
+	StatusCode int
+}
+
+// HTTPTimings breaks down the duration of an HTTP request by phase. Phases
+// that did not happen are zero.
+type HTTPTimings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// FirstByte is the time from sending the request to the first response byte.
+	FirstByte time.Duration
+	Total     time.Duration
+}
@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// KeyValueStore defines the interface for the host's persistent key-value store.
+// Plugins use it to remember state between runs, such as a last-seen
+// certificate fingerprint or a previous DNS answer set.
+// Infrastructure adapters implement this to provide key-value functionality.
+type KeyValueStore interface {
+	// Get returns the entry for key, or nil if the key does not exist or has expired.
+	Get(ctx context.Context, key string) (*KeyValueEntry, error)
+	// Set stores value under key. A ttl of zero keeps the entry until it is deleted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) (*KeyValueEntry, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys starting with prefix, in sorted order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// CompareAndSwap stores value under key only if the key's current revision
+	// equals revision; a revision of 0 means the key must not exist.
+	// It returns the stored entry and true on success, or the current entry
+	// (nil if absent) and false if the revision did not match.
+	CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*KeyValueEntry, bool, error)
+}
+
+// KeyValueEntry represents a stored key-value pair.
+type KeyValueEntry struct {
+	ExpiresAt *time.Time // nil if the entry does not expire
+	Key       string
+	Value     []byte
+	Revision  uint64 // incremented on every write
+}
@@ -0,0 +1,95 @@
+package ports
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockKeyValueStore is a mock implementation of KeyValueStore for testing.
+type MockKeyValueStore struct {
+	GetFunc            func(ctx context.Context, key string) (*KeyValueEntry, error)
+	SetFunc            func(ctx context.Context, key string, value []byte, ttl time.Duration) (*KeyValueEntry, error)
+	DeleteFunc         func(ctx context.Context, key string) error
+	ListFunc           func(ctx context.Context, prefix string) ([]string, error)
+	CompareAndSwapFunc func(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*KeyValueEntry, bool, error)
+}
+
+func (m *MockKeyValueStore) Get(ctx context.Context, key string) (*KeyValueEntry, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, key)
+	}
+	return nil, nil
+}
+
+func (m *MockKeyValueStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (*KeyValueEntry, error) {
+	if m.SetFunc != nil {
+		return m.SetFunc(ctx, key, value, ttl)
+	}
+	return &KeyValueEntry{Key: key, Value: value, Revision: 1}, nil
+}
+
+func (m *MockKeyValueStore) Delete(ctx context.Context, key string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(ctx, key)
+	}
+	return nil
+}
+
+func (m *MockKeyValueStore) List(ctx context.Context, prefix string) ([]string, error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(ctx, prefix)
+	}
+	return nil, nil
+}
+
+func (m *MockKeyValueStore) CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*KeyValueEntry, bool, error) {
+	if m.CompareAndSwapFunc != nil {
+		return m.CompareAndSwapFunc(ctx, key, revision, value, ttl)
+	}
+	return &KeyValueEntry{Key: key, Value: value, Revision: revision + 1}, true, nil
+}
+
+// Compile-time interface check
+var _ KeyValueStore = (*MockKeyValueStore)(nil)
+
+func TestMockKeyValueStore_ImplementsInterface(t *testing.T) {
+	var store KeyValueStore = &MockKeyValueStore{}
+	require.NotNil(t, store)
+}
+
+func TestMockKeyValueStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default behavior", func(t *testing.T) {
+		mock := &MockKeyValueStore{}
+
+		entry, err := mock.Get(ctx, "missing")
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+
+		entry, err = mock.Set(ctx, "k", []byte("v"), 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), entry.Revision)
+
+		entry, swapped, err := mock.CompareAndSwap(ctx, "k", 1, []byte("v2"), time.Minute)
+		require.NoError(t, err)
+		assert.True(t, swapped)
+		assert.Equal(t, uint64(2), entry.Revision)
+	})
+
+	t.Run("custom behavior", func(t *testing.T) {
+		mock := &MockKeyValueStore{
+			ListFunc: func(ctx context.Context, prefix string) ([]string, error) {
+				return []string{prefix + "a", prefix + "b"}, nil
+			},
+		}
+
+		keys, err := mock.List(ctx, "certs/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"certs/a", "certs/b"}, keys)
+	})
+}
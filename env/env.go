@@ -0,0 +1,226 @@
+// Package env provides access to host environment variables for Reglet WASM
+// plugins, governed by the plugin's declared Environment capability.
+package env
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/infrastructure/wasm"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+)
+
+// envConfig holds the configuration for environment access.
+// This struct is unexported to enforce the functional options pattern.
+type envConfig struct {
+	env ports.Environment
+}
+
+// defaultEnvConfig returns the default configuration.
+func defaultEnvConfig() envConfig {
+	return envConfig{
+		env: wasm.NewEnvAdapter(),
+	}
+}
+
+// Option is a functional option for configuring environment access.
+// Use With* functions to create options.
+type Option func(*envConfig)
+
+// WithEnvironment sets the environment to read from.
+// This is useful for injecting fakes during testing.
+func WithEnvironment(e ports.Environment) Option {
+	return func(c *envConfig) {
+		if e != nil {
+			c.env = e
+		}
+	}
+}
+
+// applyOptions applies functional options and returns the configuration.
+func applyOptions(opts ...Option) envConfig {
+	cfg := defaultEnvConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Lookup returns the value of the named host variable and whether it is set.
+// Requires the variable to match a declared Environment pattern.
+//
+// Example:
+//
+//	region, ok, err := env.Lookup(ctx, "AWS_REGION")
+func Lookup(ctx context.Context, name string, opts ...Option) (string, bool, error) {
+	if err := capability.CheckEnvironment(ctx, name); err != nil {
+		return "", false, err
+	}
+	cfg := applyOptions(opts...)
+	return cfg.env.Lookup(ctx, name)
+}
+
+// List returns the host variables visible to the plugin, keyed by name.
+// Variables not matching a declared Environment pattern are omitted.
+// Each variable is checked like a Lookup, so a capability recorder sees
+// every variable returned.
+func List(ctx context.Context, opts ...Option) (map[string]string, error) {
+	cfg := applyOptions(opts...)
+	vars, err := cfg.env.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if capability.CheckEnvironment(ctx, name) == nil {
+			visible[name] = value
+		}
+	}
+	return visible, nil
+}
+
+// reference matches an environment reference such as ${env:AWS_REGION}.
+var reference = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces every ${env:NAME} reference in s with the value of NAME.
+// Unset variables are reported as a *errors.ConfigError.
+//
+// Example:
+//
+//	proxy, err := env.Expand(ctx, "http://${env:PROXY_HOST}:3128")
+func Expand(ctx context.Context, s string, opts ...Option) (string, error) {
+	if !strings.Contains(s, "${env:") {
+		return s, nil
+	}
+
+	var expandErr error
+	expanded := reference.ReplaceAllStringFunc(s, func(ref string) string {
+		if expandErr != nil {
+			return ref
+		}
+		name := reference.FindStringSubmatch(ref)[1]
+		value, ok, err := Lookup(ctx, name, opts...)
+		switch {
+		case err != nil:
+			expandErr = err
+		case !ok:
+			expandErr = &errors.ConfigError{Field: name, Err: fmt.Errorf("environment variable %s is not set", name)}
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// Bind expands ${env:NAME} references in every string field of the struct
+// pointed to by target, including nested structs, pointers, slices and maps.
+// It is typically called on the decoded plugin config, e.g. in a client factory.
+// Errors name the offending field by its JSON path.
+//
+// Example:
+//
+//	type Config struct {
+//	    Region string `json:"region"` // e.g. "${env:AWS_REGION}"
+//	}
+//
+//	Client: plugin.NewClientFactory(func(ctx context.Context, cfg *Config) (*Client, error) {
+//	    if err := env.Bind(ctx, cfg); err != nil {
+//	        return nil, err
+//	    }
+//	    return NewClient(cfg.Region)
+//	}),
+func Bind(ctx context.Context, target any, opts ...Option) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("env: Bind target must be a non-nil pointer, got %T", target)
+	}
+	return bindValue(ctx, v.Elem(), "", opts)
+}
+
+// bindValue expands references in v, which must be settable.
+func bindValue(ctx context.Context, v reflect.Value, path string, opts []Option) error {
+	switch v.Kind() {
+	case reflect.String:
+		expanded, err := Expand(ctx, v.String(), opts...)
+		if err != nil {
+			return fieldError(path, err)
+		}
+		v.SetString(expanded)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			// Values inside interfaces are not settable; bind a copy and store it back.
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			if err := bindValue(ctx, elem, path, opts); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+		return bindValue(ctx, v.Elem(), path, opts)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := bindValue(ctx, v.Field(i), joinPath(path, jsonName(field)), opts); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := bindValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i), opts); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := bindValue(ctx, elem, joinPath(path, fmt.Sprint(iter.Key().Interface())), opts); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// fieldError attaches the config field path to an unset-variable error.
+func fieldError(path string, err error) error {
+	if cfgErr, ok := err.(*errors.ConfigError); ok && path != "" {
+		return &errors.ConfigError{Field: path, Err: cfgErr.Err}
+	}
+	return err
+}
+
+// jsonName returns the JSON name of a struct field.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+// joinPath appends a field name to a dotted path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,152 @@
+package env
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeEnv() *plugintest.FakeEnvironment {
+	return &plugintest.FakeEnvironment{Vars: map[string]string{
+		"AWS_REGION":  "eu-west-1",
+		"HTTPS_PROXY": "http://proxy:3128",
+		"HOME":        "/root",
+	}}
+}
+
+func declared() context.Context {
+	return capability.WithDeclared(context.Background(), &entities.GrantSet{
+		Env: &entities.EnvironmentCapability{Variables: []string{"AWS_*", "HTTPS_PROXY"}},
+	})
+}
+
+func TestLookupAndList(t *testing.T) {
+	fake := newFakeEnv()
+
+	value, ok, err := Lookup(context.Background(), "HOME", WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "/root", value)
+
+	ctx := declared()
+	value, ok, err = Lookup(ctx, "AWS_REGION", WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "eu-west-1", value)
+
+	_, ok, err = Lookup(ctx, "AWS_PROFILE", WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = Lookup(ctx, "HOME", WithEnvironment(fake))
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "HOME", capErr.Pattern)
+
+	vars, err := List(ctx, WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"AWS_REGION": "eu-west-1", "HTTPS_PROXY": "http://proxy:3128"}, vars)
+}
+
+func TestExpand(t *testing.T) {
+	ctx := declared()
+	fake := newFakeEnv()
+
+	s, err := Expand(ctx, "region=${env:AWS_REGION} proxy=${env:HTTPS_PROXY}", WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.Equal(t, "region=eu-west-1 proxy=http://proxy:3128", s)
+
+	s, err = Expand(ctx, "no references", WithEnvironment(fake))
+	require.NoError(t, err)
+	assert.Equal(t, "no references", s)
+
+	_, err = Expand(ctx, "${env:AWS_PROFILE}", WithEnvironment(fake))
+	var cfgErr *errors.ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Contains(t, cfgErr.Error(), "AWS_PROFILE is not set")
+
+	_, err = Expand(ctx, "${env:HOME}", WithEnvironment(fake))
+	var capErr *errors.CapabilityError
+	assert.ErrorAs(t, err, &capErr)
+}
+
+type endpoint struct {
+	URL string `json:"url"`
+}
+
+type bindConfig struct {
+	Region    string            `json:"region"`
+	Proxy     *string           `json:"proxy,omitempty"`
+	Endpoints []endpoint        `json:"endpoints"`
+	Tags      map[string]string `json:"tags"`
+	Extra     any               `json:"extra"`
+	Retries   int               `json:"retries"`
+	internal  string
+}
+
+func TestBind(t *testing.T) {
+	ctx := declared()
+	fake := newFakeEnv()
+	proxy := "${env:HTTPS_PROXY}"
+
+	cfg := &bindConfig{
+		Region:    "${env:AWS_REGION}",
+		Proxy:     &proxy,
+		Endpoints: []endpoint{{URL: "https://ec2.${env:AWS_REGION}.amazonaws.com"}},
+		Tags:      map[string]string{"region": "${env:AWS_REGION}"},
+		Extra:     "${env:AWS_REGION}",
+		Retries:   3,
+		internal:  "${env:HOME}",
+	}
+	require.NoError(t, Bind(ctx, cfg, WithEnvironment(fake)))
+	assert.Equal(t, "eu-west-1", cfg.Region)
+	assert.Equal(t, "http://proxy:3128", *cfg.Proxy)
+	assert.Equal(t, "https://ec2.eu-west-1.amazonaws.com", cfg.Endpoints[0].URL)
+	assert.Equal(t, "eu-west-1", cfg.Tags["region"])
+	assert.Equal(t, "eu-west-1", cfg.Extra)
+	assert.Equal(t, "${env:HOME}", cfg.internal, "unexported fields are untouched")
+
+	cfg = &bindConfig{Endpoints: []endpoint{{URL: "${env:AWS_ENDPOINT}"}}}
+	err := Bind(ctx, cfg, WithEnvironment(fake))
+	var cfgErr *errors.ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "endpoints[0].url", cfgErr.Field)
+
+	assert.Error(t, Bind(ctx, bindConfig{}, WithEnvironment(fake)), "target must be a pointer")
+}
+
+type regionConfig struct {
+	Region string `json:"region"`
+}
+
+func TestBind_InClientFactory(t *testing.T) {
+	fake := newFakeEnv()
+	var region string
+	def := plugin.DefinePlugin(plugin.PluginDef{
+		Name: "aws",
+		Capabilities: entities.GrantSet{
+			Env: &entities.EnvironmentCapability{Variables: []string{"AWS_REGION"}},
+		},
+		Client: plugin.NewClientFactory(func(ctx context.Context, cfg *regionConfig) (*regionConfig, error) {
+			if err := Bind(ctx, cfg, WithEnvironment(fake)); err != nil {
+				return nil, err
+			}
+			region = cfg.Region
+			return cfg, nil
+		}),
+	})
+
+	require.NoError(t, def.Init(context.Background(), []byte(`{"region":"${env:AWS_REGION}"}`)))
+	assert.Equal(t, "eu-west-1", region)
+
+	err := def.Init(context.Background(), []byte(`{"region":"${env:HOME}"}`))
+	var capErr *errors.CapabilityError
+	assert.ErrorAs(t, err, &capErr, "Init is checked against the declared capabilities")
+}
@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -408,3 +410,52 @@ func TestCommandDurationTracking(t *testing.T) {
 		})
 	}
 }
+
+type unitInput struct {
+	Command string `json:"command"`
+}
+
+type unitOutput struct {
+	Stdout string `json:"stdout"`
+}
+
+type unitService struct {
+	plugin.Service `name:"unit" desc:"Unit status"`
+	Status         plugin.Op[unitInput, unitOutput] `desc:"Run a status command" method:"StatusHandler"`
+}
+
+func (s *unitService) StatusHandler(ctx context.Context, in *unitInput) (*unitOutput, error) {
+	resp, err := Run(ctx, CommandRequest{Command: in.Command}, WithRunner(plugin.GetClient[*plugintest.FakeCommandRunner](ctx)))
+	if err != nil {
+		return nil, err
+	}
+	return &unitOutput{Stdout: resp.Stdout}, nil
+}
+
+func TestRun_PluginDeclaredCapabilities(t *testing.T) {
+	def := plugin.DefinePlugin(plugin.PluginDef{
+		Name: "unit",
+		Capabilities: entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/*"}},
+		},
+	})
+	require.NoError(t, plugin.RegisterService(def, &unitService{}))
+	runner := &plugintest.FakeCommandRunner{
+		Results: map[string]*ports.CommandResult{
+			"/usr/bin/systemctl": {Stdout: "active\n"},
+			"/bin/sh":            {Stdout: "should not run\n"},
+		},
+	}
+	ctx := plugin.WithClient(context.Background(), runner)
+
+	res, err := def.Check(ctx, []byte(`{"input":{"command":"/usr/bin/systemctl"}}`))
+	require.NoError(t, err)
+	plugintest.AssertSuccess(t, res)
+
+	res, err = def.Check(ctx, []byte(`{"input":{"command":"/bin/sh"}}`))
+	require.NoError(t, err)
+	require.Equal(t, entities.ResultStatusError, res.Status)
+	assert.Equal(t, "capability", res.Error.Type)
+	assert.Equal(t, "exec", res.Error.Code)
+	assert.Len(t, runner.Requests, 1, "the undeclared command never reaches the runner")
+}
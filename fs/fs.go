@@ -0,0 +1,157 @@
+// Package fs provides read-only access to host files for Reglet WASM
+// plugins, such as sshd_config or sysctl settings in configuration audits.
+package fs
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/infrastructure/wasm"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+)
+
+// Re-export types from ports for API compatibility
+type FileInfo = ports.FileInfo
+
+// DefaultMaxSize is the largest file ReadFile returns unless WithMaxSize is used.
+const DefaultMaxSize = 10 << 20 // 10 MiB
+
+// fsConfig holds the configuration for filesystem operations.
+// This struct is unexported to enforce the functional options pattern.
+type fsConfig struct {
+	fsys    ports.FileSystem
+	maxSize int64 // Largest file ReadFile accepts (default: DefaultMaxSize)
+}
+
+// defaultFSConfig returns secure defaults for filesystem access.
+func defaultFSConfig() fsConfig {
+	return fsConfig{
+		fsys:    wasm.NewFSAdapter(),
+		maxSize: DefaultMaxSize,
+	}
+}
+
+// Option is a functional option for configuring filesystem operations.
+// Use With* functions to create options.
+type Option func(*fsConfig)
+
+// WithFileSystem sets the filesystem to use.
+// This is useful for injecting fakes during testing.
+func WithFileSystem(f ports.FileSystem) Option {
+	return func(c *fsConfig) {
+		if f != nil {
+			c.fsys = f
+		}
+	}
+}
+
+// WithMaxSize sets the largest file, in bytes, that ReadFile returns.
+// A zero or negative size is ignored (uses default).
+func WithMaxSize(n int64) Option {
+	return func(c *fsConfig) {
+		if n > 0 {
+			c.maxSize = n
+		}
+	}
+}
+
+// applyOptions applies functional options and returns the configuration.
+func applyOptions(opts ...Option) fsConfig {
+	cfg := defaultFSConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// ReadFile returns the contents of the file at path.
+// Requires an FS rule whose Read patterns cover path.
+//
+// Options:
+//   - WithMaxSize(n): Reject files larger than n bytes (default: 10 MiB)
+//   - WithFileSystem(f): Inject custom filesystem (for testing)
+//
+// Example:
+//
+//	data, err := fs.ReadFile(ctx, "/etc/ssh/sshd_config")
+func ReadFile(ctx context.Context, path string, opts ...Option) ([]byte, error) {
+	if err := capability.CheckFileSystem(ctx, capability.FSRead, path); err != nil {
+		return nil, err
+	}
+	cfg := applyOptions(opts...)
+	return cfg.fsys.ReadFile(ctx, path, cfg.maxSize)
+}
+
+// Stat returns information about the file or directory at path.
+// Requires an FS rule whose Read patterns cover path.
+func Stat(ctx context.Context, path string, opts ...Option) (*FileInfo, error) {
+	if err := capability.CheckFileSystem(ctx, capability.FSRead, path); err != nil {
+		return nil, err
+	}
+	cfg := applyOptions(opts...)
+	return cfg.fsys.Stat(ctx, path)
+}
+
+// ReadDir returns the entries of the directory at path, sorted by name.
+// Requires an FS rule whose Read patterns cover path; entries not covered
+// by a Read pattern are omitted. Each entry is checked like a ReadFile, so a
+// capability recorder sees every entry returned.
+func ReadDir(ctx context.Context, path string, opts ...Option) ([]FileInfo, error) {
+	if err := capability.CheckFileSystem(ctx, capability.FSRead, path); err != nil {
+		return nil, err
+	}
+	cfg := applyOptions(opts...)
+	entries, err := cfg.fsys.ReadDir(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if capability.CheckFileSystem(ctx, capability.FSRead, entry.Path) == nil {
+			readable = append(readable, entry)
+		}
+	}
+	return readable, nil
+}
+
+// Glob returns the paths matching pattern, using path.Match syntax.
+// Like ReadDir, it requires an FS rule whose Read patterns cover the directory
+// being searched: the longest leading part of pattern without wildcards, such
+// as "/etc/sysctl.d" for "/etc/sysctl.d/*.conf". Paths not covered by an FS
+// Read pattern are omitted. Each path is checked like a ReadFile, so a
+// capability recorder sees the directory and every path returned.
+//
+// Example:
+//
+//	confs, err := fs.Glob(ctx, "/etc/sysctl.d/*.conf")
+func Glob(ctx context.Context, pattern string, opts ...Option) ([]string, error) {
+	if err := capability.CheckFileSystem(ctx, capability.FSRead, globDir(pattern)); err != nil {
+		return nil, err
+	}
+	cfg := applyOptions(opts...)
+	matches, err := cfg.fsys.Glob(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if capability.CheckFileSystem(ctx, capability.FSRead, match) == nil {
+			readable = append(readable, match)
+		}
+	}
+	return readable, nil
+}
+
+// globDir returns the directory searched by pattern: its longest leading
+// path without wildcard characters.
+func globDir(pattern string) string {
+	dir := path.Dir(path.Clean(pattern))
+	for strings.ContainsAny(dir, `*?[\`) {
+		dir = path.Dir(dir)
+	}
+	return dir
+}
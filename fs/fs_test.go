@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeFS() *plugintest.FakeFileSystem {
+	return &plugintest.FakeFileSystem{FS: fstest.MapFS{
+		"etc/ssh/sshd_config":       {Data: []byte("PermitRootLogin no\n")},
+		"etc/sysctl.d/10-net.conf":  {Data: []byte("net.ipv4.ip_forward = 0\n")},
+		"etc/sysctl.d/20-kern.conf": {Data: []byte("kernel.kptr_restrict = 1\n")},
+		"etc/shadow":                {Data: []byte("root:*:19000::::::\n")},
+	}}
+}
+
+func TestReadFile(t *testing.T) {
+	ctx := context.Background()
+	fsys := newFakeFS()
+
+	data, err := ReadFile(ctx, "/etc/ssh/sshd_config", WithFileSystem(fsys))
+	require.NoError(t, err)
+	assert.Equal(t, "PermitRootLogin no\n", string(data))
+
+	_, err = ReadFile(ctx, "/etc/ssh/sshd_config", WithFileSystem(fsys), WithMaxSize(4))
+	var fsErr *errors.FileSystemError
+	require.ErrorAs(t, err, &fsErr)
+	assert.Contains(t, fsErr.Error(), "size limit")
+
+	_, err = ReadFile(ctx, "/etc/missing", WithFileSystem(fsys))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.True(t, errors.ToErrorDetail(err).IsNotFound)
+}
+
+func TestStatReadDirGlob(t *testing.T) {
+	ctx := context.Background()
+	fsys := newFakeFS()
+
+	info, err := Stat(ctx, "/etc/sysctl.d", WithFileSystem(fsys))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir)
+	assert.Equal(t, "/etc/sysctl.d", info.Path)
+
+	entries, err := ReadDir(ctx, "/etc/sysctl.d", WithFileSystem(fsys))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/etc/sysctl.d/10-net.conf", entries[0].Path)
+	assert.EqualValues(t, 24, entries[0].Size)
+
+	matches, err := Glob(ctx, "/etc/sysctl.d/*.conf", WithFileSystem(fsys))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/etc/sysctl.d/10-net.conf", "/etc/sysctl.d/20-kern.conf"}, matches)
+}
+
+func TestDeclaredRules(t *testing.T) {
+	fsys := newFakeFS()
+	ctx := capability.WithDeclared(context.Background(), &entities.GrantSet{
+		FS: &entities.FileSystemCapability{
+			Rules: []entities.FileSystemRule{
+				{Read: []string{"/etc/ssh/**", "/etc/sysctl.d/10-*.conf", "/etc"}},
+			},
+		},
+	})
+
+	_, err := ReadFile(ctx, "/etc/ssh/sshd_config", WithFileSystem(fsys))
+	assert.NoError(t, err)
+
+	_, err = ReadFile(ctx, "/etc/shadow", WithFileSystem(fsys))
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "fs:read", capErr.Required)
+	assert.Equal(t, "/etc/shadow", capErr.Pattern)
+
+	_, err = Stat(ctx, "/etc/ssh/../shadow", WithFileSystem(fsys))
+	assert.ErrorAs(t, err, &capErr)
+	_, err = ReadDir(ctx, "/etc/sysctl.d", WithFileSystem(fsys))
+	assert.ErrorAs(t, err, &capErr)
+
+	entries, err := ReadDir(ctx, "/etc", WithFileSystem(fsys))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only readable entries are listed")
+	assert.Equal(t, "/etc/ssh", entries[0].Path)
+
+	_, err = Glob(ctx, "/etc/sysctl.d/*.conf", WithFileSystem(fsys))
+	assert.ErrorAs(t, err, &capErr, "the searched directory must be readable")
+	assert.Equal(t, "/etc/sysctl.d", capErr.Pattern)
+
+	ctx = capability.WithDeclared(context.Background(), &entities.GrantSet{
+		FS: &entities.FileSystemCapability{
+			Rules: []entities.FileSystemRule{{Read: []string{"/etc/sysctl.d", "/etc/sysctl.d/10-*.conf"}}},
+		},
+	})
+	matches, err := Glob(ctx, "/etc/sysctl.d/*.conf", WithFileSystem(fsys))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/etc/sysctl.d/10-net.conf"}, matches)
+}
+
+func TestGlob_RecordsDirectory(t *testing.T) {
+	var recorded []string
+	ctx := capability.WithRecorder(context.Background(), func(_ context.Context, requested *entities.GrantSet) {
+		recorded = append(recorded, requested.FS.Rules[0].Read...)
+	})
+
+	matches, err := Glob(ctx, "/var/log/*/*.log", WithFileSystem(newFakeFS()))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.Equal(t, []string{"/var/log"}, recorded)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
 	_ "github.com/reglet-dev/reglet-plugin-sdk/log" // Initialize WASM logging handler
 )
 
@@ -104,17 +106,17 @@ func (r *DNSAdapter) LookupNS(ctx context.Context, domain string) ([]string, err
 
 // Lookup performs the actual DNS query via the host function.
 func (r *DNSAdapter) Lookup(ctx context.Context, hostname, recordType string) (*entities.DNSResponse, error) {
+	if err := capability.CheckDNS(ctx, hostname, r.Nameserver); err != nil {
+		return nil, err
+	}
+
 	request := entities.DNSRequest{
-		Context:    entities.ContextWire{}, // Zero value for now
+		Context:    wasmcontext.ContextToWire(ctx),
 		Hostname:   hostname,
 		Type:       recordType,
 		Nameserver: r.Nameserver,
 	}
 
-	if d, ok := ctx.Deadline(); ok {
-		_ = d
-	}
-
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("sdk: failed to marshal DNS request: %w", err)
@@ -0,0 +1,82 @@
+//go:build wasip1
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
+)
+
+// Compile-time interface compliance check
+var _ ports.Environment = (*EnvAdapter)(nil)
+
+// EnvAdapter implements ports.Environment for the WASM environment.
+// Variables are read from the host, which only exposes granted variables.
+type EnvAdapter struct{}
+
+// NewEnvAdapter creates a new EnvAdapter.
+func NewEnvAdapter() *EnvAdapter {
+	return &EnvAdapter{}
+}
+
+// Lookup returns the value of the named host variable.
+func (a *EnvAdapter) Lookup(ctx context.Context, name string) (string, bool, error) {
+	res, err := a.call(ctx, entities.EnvRequest{Op: "lookup", Name: name})
+	if err != nil {
+		return "", false, err
+	}
+	return res.Value, res.Found, nil
+}
+
+// List returns the host variables visible to the plugin.
+func (a *EnvAdapter) List(ctx context.Context) (map[string]string, error) {
+	res, err := a.call(ctx, entities.EnvRequest{Op: "list"})
+	if err != nil {
+		return nil, err
+	}
+	if res.Variables == nil {
+		return map[string]string{}, nil
+	}
+	return res.Variables, nil
+}
+
+// call sends a request to the host and decodes the response.
+func (a *EnvAdapter) call(ctx context.Context, req entities.EnvRequest) (*entities.EnvResponse, error) {
+	// 1. Prepare wire request with context
+	req.Context = wasmcontext.ContextToWire(ctx)
+
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// 2. Send to host
+	reqPacked := abi.PtrFromBytes(reqData)
+	defer abi.DeallocatePacked(reqPacked)
+
+	resPacked := host_env_lookup(reqPacked)
+
+	// 3. Read response
+	resBytes := abi.BytesFromPtr(resPacked)
+	if resBytes == nil {
+		return nil, fmt.Errorf("host returned null response")
+	}
+	defer abi.DeallocatePacked(resPacked) // Free host-allocated response memory
+
+	var wireRes entities.EnvResponse
+	if err := json.Unmarshal(resBytes, &wireRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// 4. Handle errors
+	if wireRes.Error != nil {
+		return nil, wireRes.Error
+	}
+	return &wireRes, nil
+}
@@ -0,0 +1,32 @@
+//go:build !wasip1
+
+package wasm
+
+import (
+	"context"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// Compile-time interface compliance check
+var _ ports.Environment = (*EnvAdapter)(nil)
+
+// EnvAdapter stub for native builds.
+type EnvAdapter struct{}
+
+// NewEnvAdapter creates a new EnvAdapter stub.
+func NewEnvAdapter() *EnvAdapter {
+	return &EnvAdapter{}
+}
+
+const envStubPanic = "WASM Env adapter not available in native build. Use env.WithEnvironment() to inject a fake."
+
+// Lookup panics because the WASM environment is not available natively.
+func (a *EnvAdapter) Lookup(ctx context.Context, name string) (string, bool, error) {
+	panic(envStubPanic)
+}
+
+// List panics because the WASM environment is not available natively.
+func (a *EnvAdapter) List(ctx context.Context) (map[string]string, error) {
+	panic(envStubPanic)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
 	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
 )
 
@@ -26,6 +27,10 @@ func NewExecAdapter() *ExecAdapter {
 
 // Run executes a command on the host system.
 func (a *ExecAdapter) Run(ctx context.Context, req ports.CommandRequest) (*ports.CommandResult, error) {
+	if err := capability.CheckExec(ctx, req.Command); err != nil {
+		return nil, err
+	}
+
 	// 1. Prepare wire request with context
 	wireReq := entities.ExecRequest{
 		Context: wasmcontext.ContextToWire(ctx),
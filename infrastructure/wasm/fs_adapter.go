@@ -0,0 +1,117 @@
+//go:build wasip1
+
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// Compile-time interface compliance check
+var _ ports.FileSystem = (*FSAdapter)(nil)
+
+// FSAdapter implements ports.FileSystem for the WASM environment.
+// Files are read through the WASI preopened directories the host mounts
+// for the plugin's FileSystem grants.
+type FSAdapter struct{}
+
+// NewFSAdapter creates a new FSAdapter.
+func NewFSAdapter() *FSAdapter {
+	return &FSAdapter{}
+}
+
+// ReadFile returns the contents of the file at path, up to maxBytes.
+func (a *FSAdapter) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "read", Path: path, Err: err}
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if maxBytes > 0 {
+		// Read one byte past the limit to detect oversized files.
+		r = io.LimitReader(f, maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "read", Path: path, Err: err}
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, &errors.FileSystemError{
+			Operation: "read",
+			Path:      path,
+			Err:       fmt.Errorf("file exceeds size limit of %d bytes", maxBytes),
+		}
+	}
+	return data, nil
+}
+
+// Stat returns information about the file or directory at path.
+func (a *FSAdapter) Stat(ctx context.Context, path string) (*ports.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "stat", Path: path, Err: err}
+	}
+	fi := toFileInfo(path, info)
+	return &fi, nil
+}
+
+// ReadDir returns the entries of the directory at path, sorted by name.
+func (a *FSAdapter) ReadDir(ctx context.Context, path string) ([]ports.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "readdir", Path: path, Err: err}
+	}
+
+	infos := make([]ports.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, &errors.FileSystemError{Operation: "readdir", Path: path, Err: err}
+		}
+		infos = append(infos, toFileInfo(filepath.Join(path, entry.Name()), info))
+	}
+	return infos, nil
+}
+
+// Glob returns the paths matching pattern.
+func (a *FSAdapter) Glob(ctx context.Context, pattern string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "glob", Path: pattern, Err: err}
+	}
+	return matches, nil
+}
+
+// toFileInfo converts an fs.FileInfo to the port type.
+func toFileInfo(path string, info fs.FileInfo) ports.FileInfo {
+	return ports.FileInfo{
+		ModTime: info.ModTime(),
+		Name:    info.Name(),
+		Path:    path,
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		IsDir:   info.IsDir(),
+	}
+}
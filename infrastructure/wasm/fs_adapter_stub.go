@@ -0,0 +1,42 @@
+//go:build !wasip1
+
+package wasm
+
+import (
+	"context"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// Compile-time interface compliance check
+var _ ports.FileSystem = (*FSAdapter)(nil)
+
+// FSAdapter stub for native builds.
+type FSAdapter struct{}
+
+// NewFSAdapter creates a new FSAdapter stub.
+func NewFSAdapter() *FSAdapter {
+	return &FSAdapter{}
+}
+
+const fsStubPanic = "WASM FS adapter not available in native build. Use fs.WithFileSystem() to inject a fake."
+
+// ReadFile panics because the WASM filesystem is not available natively.
+func (a *FSAdapter) ReadFile(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	panic(fsStubPanic)
+}
+
+// Stat panics because the WASM filesystem is not available natively.
+func (a *FSAdapter) Stat(ctx context.Context, path string) (*ports.FileInfo, error) {
+	panic(fsStubPanic)
+}
+
+// ReadDir panics because the WASM filesystem is not available natively.
+func (a *FSAdapter) ReadDir(ctx context.Context, path string) ([]ports.FileInfo, error) {
+	panic(fsStubPanic)
+}
+
+// Glob panics because the WASM filesystem is not available natively.
+func (a *FSAdapter) Glob(ctx context.Context, pattern string) ([]string, error) {
+	panic(fsStubPanic)
+}
@@ -8,6 +8,17 @@ package wasm
 //go:wasmimport reglet_host http_request
 func host_http_request(requestPacked uint64) uint64
 
+// Define the host function signature for reading a chunk of a streamed HTTP
+// response body.
+//
+//go:wasmimport reglet_host http_body_read
+func host_http_body_read(requestPacked uint64) uint64
+
+// Define the host function signature for releasing a streamed HTTP response body.
+//
+//go:wasmimport reglet_host http_body_close
+func host_http_body_close(requestPacked uint64) uint64
+
 // Define the host function signature for DNS lookups.
 //
 //go:wasmimport reglet_host dns_lookup
@@ -27,3 +38,13 @@ func host_smtp_connect(requestPacked uint64) uint64
 //
 //go:wasmimport reglet_host exec_command
 func host_exec_command(reqPacked uint64) uint64
+
+// Define the host function signature for key-value store operations.
+//
+//go:wasmimport reglet_host kv_operation
+func host_kv_operation(reqPacked uint64) uint64
+
+// Define the host function signature for environment variable lookups.
+//
+//go:wasmimport reglet_host env_lookup
+func host_env_lookup(reqPacked uint64) uint64
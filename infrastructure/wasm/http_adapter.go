@@ -4,27 +4,27 @@ package wasm
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
-	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
 	_ "github.com/reglet-dev/reglet-plugin-sdk/log"
 )
 
-// MaxHTTPBodySize definition if needed, or import from somewhere.
-const MaxHTTPBodySize = 10 * 1024 * 1024 // 10 MB
-
 // Compile-time interface compliance check
 var _ ports.HTTPClient = (*HTTPAdapter)(nil)
 
 // HTTPAdapter implements ports.HTTPClient for the WASM environment.
 type HTTPAdapter struct {
+	// DefaultMaxRedirects applies to requests that do not set MaxRedirects.
+	// Nil leaves the limit to the host.
+	DefaultMaxRedirects *int
+	// DefaultTLS applies to requests that do not set TLS.
+	DefaultTLS *ports.TLSConfig
+	// bodyHost reads streamed bodies; it is nil unless the adapter was
+	// created by NewStreamingHTTPAdapter.
+	bodyHost       httpBodyHost
 	DefaultTimeout time.Duration
 }
 
@@ -38,67 +38,42 @@ func NewHTTPAdapter(defaultTimeout time.Duration) *HTTPAdapter {
 	}
 }
 
-// Do executes an HTTP request.
+// NewStreamingHTTPAdapter creates an HTTP adapter that reads the response
+// bodies of StreamBody requests from the host in chunks. Plugins that use it
+// import the reglet_host http_body_read and http_body_close functions, which
+// not every host provides. The adapter from NewHTTPAdapter imports neither:
+// it has the host buffer every body and exposes it as BodyReader.
+func NewStreamingHTTPAdapter(defaultTimeout time.Duration) *HTTPAdapter {
+	c := NewHTTPAdapter(defaultTimeout)
+	c.bodyHost = wasmHTTPBodyHost{}
+	return c
+}
+
+// Do executes an HTTP request. MaxRedirects and TLS left unset in req are
+// taken from the adapter's defaults.
 func (c *HTTPAdapter) Do(ctx context.Context, req ports.HTTPRequest) (*ports.HTTPResponse, error) {
-	// Prepare wire request
-	wireCtx := wasmcontext.ContextToWire(ctx)
+	return doHTTP(ctx, req, c.DefaultMaxRedirects, c.DefaultTLS, wasmHTTPHost{}, c.bodyHost)
+}
 
-	headers := make(map[string][]string)
-	for k, v := range req.Headers {
-		headers[k] = []string{v}
-	}
+// wasmHTTPHost implements httpHost with the reglet_host http_request function.
+type wasmHTTPHost struct{}
 
-	rawBody := ""
-	if len(req.Body) > 0 {
-		rawBody = base64.StdEncoding.EncodeToString(req.Body)
-	}
+func (wasmHTTPHost) request(req []byte) []byte { return callHost(host_http_request, req) }
 
-	wireReq := entities.HTTPRequest{
-		Context: wireCtx,
-		Method:  req.Method,
-		URL:     req.URL,
-		Headers: headers, // map[string][]string
-		Body:    rawBody,
-	}
+// wasmHTTPBodyHost implements httpBodyHost with the reglet_host body
+// functions. Only NewStreamingHTTPAdapter refers to it, so the functions are
+// linked into a plugin only if it streams.
+type wasmHTTPBodyHost struct{}
 
-	// Marshal and call host
-	reqBytes, err := json.Marshal(wireReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
+func (wasmHTTPBodyHost) readBody(req []byte) []byte  { return callHost(host_http_body_read, req) }
+func (wasmHTTPBodyHost) closeBody(req []byte) []byte { return callHost(host_http_body_close, req) }
 
-	respPacked := host_http_request(abi.PtrFromBytes(reqBytes))
+// callHost passes request to the host function fn and returns its answer.
+func callHost(fn func(uint64) uint64, request []byte) []byte {
+	respPacked := fn(abi.PtrFromBytes(request))
 	respBytes := abi.BytesFromPtr(respPacked)
 	abi.DeallocatePacked(respPacked)
-
-	var wireResp entities.HTTPResponse
-	if err := json.Unmarshal(respBytes, &wireResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if wireResp.Error != nil {
-		return nil, wireResp.Error
-	}
-
-	if wireResp.BodyTruncated {
-		return nil, fmt.Errorf("response body too large")
-	}
-
-	// Decode body
-	var body []byte
-	if wireResp.Body != "" {
-		body, err = base64.StdEncoding.DecodeString(wireResp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode response body: %w", err)
-		}
-	}
-
-	return &ports.HTTPResponse{
-		StatusCode: wireResp.StatusCode,
-		Headers:    wireResp.Headers,
-		Body:       body,
-		Proto:      wireResp.Proto,
-	}, nil
+	return respBytes
 }
 
 // Get performs a GET request.
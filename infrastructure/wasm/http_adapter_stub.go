@@ -10,12 +10,20 @@ import (
 )
 
 // HTTPAdapter stub for native builds.
-type HTTPAdapter struct{}
+type HTTPAdapter struct {
+	DefaultMaxRedirects *int
+	DefaultTLS          *ports.TLSConfig
+	DefaultTimeout      time.Duration
+}
 
 func NewHTTPAdapter(defaultTimeout time.Duration) *HTTPAdapter {
 	return &HTTPAdapter{}
 }
 
+func NewStreamingHTTPAdapter(defaultTimeout time.Duration) *HTTPAdapter {
+	return &HTTPAdapter{}
+}
+
 func (c *HTTPAdapter) Do(ctx context.Context, req ports.HTTPRequest) (*ports.HTTPResponse, error) {
 	panic("WASM HTTP adapter not available in native build")
 }
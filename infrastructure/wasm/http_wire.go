@@ -0,0 +1,292 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/httpreq"
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
+)
+
+// MaxHTTPBodySize is the largest request body sent to the host.
+const MaxHTTPBodySize = httpreq.MaxBodySize
+
+// httpBodyChunkSize is the most data requested from the host per read of a
+// streamed response body.
+const httpBodyChunkSize = 256 * 1024 // 256 KB
+
+// httpHost passes encoded HTTP messages to the host function and returns the
+// host's encoded answer.
+type httpHost interface {
+	// request sends an entities.HTTPRequest and returns an entities.HTTPResponse.
+	request(req []byte) []byte
+}
+
+// httpBodyHost reads streamed response bodies from the host. It is separate
+// from httpHost so that plugins which never stream do not import the body
+// host functions.
+type httpBodyHost interface {
+	// readBody sends an entities.HTTPBodyReadRequest and returns an
+	// entities.HTTPBodyReadResponse.
+	readBody(req []byte) []byte
+	// closeBody sends an entities.HTTPBodyCloseRequest and returns an
+	// entities.HTTPBodyCloseResponse.
+	closeBody(req []byte) []byte
+}
+
+// doHTTP executes req through host. MaxRedirects and TLS left unset in req
+// are taken from maxRedirects and tlsConfig. Streamed bodies are read through
+// bodyHost; if it is nil, StreamBody requests ask the host for the whole body
+// and expose it as BodyReader.
+func doHTTP(ctx context.Context, req ports.HTTPRequest, maxRedirects *int, tlsConfig *ports.TLSConfig, host httpHost, bodyHost httpBodyHost) (*ports.HTTPResponse, error) {
+	if err := capability.CheckURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if req.MaxRedirects == nil {
+		req.MaxRedirects = maxRedirects
+	}
+	if req.TLS == nil {
+		req.TLS = tlsConfig
+	}
+
+	wireReq, err := encodeHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if bodyHost == nil {
+		wireReq.StreamBody = false
+	}
+
+	// Marshal and call host
+	reqBytes, err := json.Marshal(wireReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var wireResp entities.HTTPResponse
+	if err := json.Unmarshal(host.request(reqBytes), &wireResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return decodeHTTPResponse(&wireResp, &req, bodyHost)
+}
+
+func encodeHTTPRequest(ctx context.Context, req ports.HTTPRequest) (*entities.HTTPRequest, error) {
+	url, err := httpreq.URL(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := httpreq.Body(&req, MaxHTTPBodySize)
+	if err != nil {
+		return nil, err
+	}
+	rawBody := ""
+	if len(body) > 0 {
+		rawBody = base64.StdEncoding.EncodeToString(body)
+	}
+
+	if req.MaxRedirects != nil && *req.MaxRedirects < 0 {
+		return nil, fmt.Errorf("invalid max redirects %d", *req.MaxRedirects)
+	}
+	wireTLS, err := encodeTLSConfig(req.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.HTTPRequest{
+		Context:      wasmcontext.ContextToWire(ctx),
+		Method:       req.Method,
+		URL:          url,
+		Headers:      httpreq.Header(&req),
+		Body:         rawBody,
+		MaxRedirects: req.MaxRedirects,
+		TLS:          wireTLS,
+		StreamBody:   req.StreamBody,
+	}, nil
+}
+
+// encodeTLSConfig converts cfg to its wire format, rejecting settings the host
+// could not apply so that the plugin gets a clear error before any request.
+func encodeTLSConfig(cfg *ports.TLSConfig) (*entities.HTTPTLSConfig, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	wire := &entities.HTTPTLSConfig{
+		ServerName:         cfg.ServerName,
+		RootCAs:            string(cfg.RootCAs),
+		ClientCert:         string(cfg.ClientCert),
+		ClientKey:          string(cfg.ClientKey),
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	switch cfg.MinVersion {
+	case 0:
+	case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+		wire.MinVersion = tls.VersionName(cfg.MinVersion)
+	default:
+		return nil, fmt.Errorf("invalid TLS minimum version 0x%04x", cfg.MinVersion)
+	}
+
+	if len(cfg.RootCAs) > 0 && !x509.NewCertPool().AppendCertsFromPEM(cfg.RootCAs) {
+		return nil, fmt.Errorf("TLS root CAs contain no PEM certificates")
+	}
+	if len(cfg.ClientCert) > 0 || len(cfg.ClientKey) > 0 {
+		if _, err := tls.X509KeyPair(cfg.ClientCert, cfg.ClientKey); err != nil {
+			return nil, fmt.Errorf("invalid TLS client certificate: %w", err)
+		}
+	}
+	return wire, nil
+}
+
+func decodeHTTPResponse(wireResp *entities.HTTPResponse, req *ports.HTTPRequest, bodyHost httpBodyHost) (*ports.HTTPResponse, error) {
+	if wireResp.Error != nil {
+		return nil, wireResp.Error
+	}
+
+	if wireResp.BodyTruncated && !req.AllowTruncatedBody {
+		if req.StreamBody && bodyHost == nil {
+			return nil, fmt.Errorf("response body too large: the client cannot stream bodies, use NewStreamingHTTPAdapter or set AllowTruncatedBody")
+		}
+		return nil, fmt.Errorf("response body too large: stream it with StreamBody or set AllowTruncatedBody")
+	}
+	if wireResp.BodyHandle != 0 && bodyHost == nil {
+		return nil, fmt.Errorf("host streamed a response body the client did not ask to stream")
+	}
+
+	// Decode body
+	var body []byte
+	if wireResp.Body != "" {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(wireResp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response body: %w", err)
+		}
+	}
+
+	resp := &ports.HTTPResponse{
+		StatusCode:    wireResp.StatusCode,
+		Headers:       wireResp.Headers,
+		Body:          body,
+		BodyTruncated: wireResp.BodyTruncated,
+		Proto:         wireResp.Proto,
+	}
+	switch {
+	case wireResp.BodyHandle != 0:
+		resp.Body = nil
+		resp.BodyReader = &hostBodyReader{host: bodyHost, handle: wireResp.BodyHandle}
+	case req.StreamBody:
+		// The host answered inline, as hosts without streaming support do,
+		// or the client cannot stream and asked for the whole body.
+		resp.Body = nil
+		resp.BodyReader = io.NopCloser(bytes.NewReader(body))
+	}
+	for _, hop := range wireResp.Redirects {
+		resp.Redirects = append(resp.Redirects, ports.HTTPRedirect{
+			URL:        hop.URL,
+			Location:   hop.Location,
+			StatusCode: hop.StatusCode,
+		})
+	}
+	if t := wireResp.Timings; t != nil {
+		resp.Timings = &ports.HTTPTimings{
+			DNS:          time.Duration(t.DNSMs) * time.Millisecond,
+			Connect:      time.Duration(t.ConnectMs) * time.Millisecond,
+			TLSHandshake: time.Duration(t.TLSMs) * time.Millisecond,
+			FirstByte:    time.Duration(t.FirstByteMs) * time.Millisecond,
+			Total:        time.Duration(t.TotalMs) * time.Millisecond,
+		}
+	}
+	return resp, nil
+}
+
+// errBodyClosed matches the error net/http returns for the same mistake.
+var errBodyClosed = errors.New("http: read on closed response body")
+
+// hostBodyReader reads a streamed response body from the host, one chunk of
+// at most httpBodyChunkSize bytes per host call.
+type hostBodyReader struct {
+	host   httpBodyHost
+	err    error
+	buf    []byte
+	handle uint64
+	eof    bool
+	closed bool
+}
+
+func (r *hostBodyReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errBodyClosed
+	}
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.eof {
+			return 0, io.EOF
+		}
+		r.err = r.fill()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fill reads the next chunk from the host into buf.
+func (r *hostBodyReader) fill() error {
+	reqBytes, err := json.Marshal(entities.HTTPBodyReadRequest{Handle: r.handle, MaxBytes: httpBodyChunkSize})
+	if err != nil {
+		return fmt.Errorf("failed to marshal body read request: %w", err)
+	}
+	var chunk entities.HTTPBodyReadResponse
+	if err := json.Unmarshal(r.host.readBody(reqBytes), &chunk); err != nil {
+		return fmt.Errorf("failed to unmarshal body read response: %w", err)
+	}
+	if chunk.Error != nil {
+		return chunk.Error
+	}
+	data, err := base64.StdEncoding.DecodeString(chunk.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode response body chunk: %w", err)
+	}
+	switch {
+	case len(data) > httpBodyChunkSize:
+		return fmt.Errorf("host returned a %d byte body chunk, more than the %d requested", len(data), httpBodyChunkSize)
+	case len(data) == 0 && !chunk.EOF:
+		return io.ErrNoProgress
+	}
+	r.buf, r.eof = data, chunk.EOF
+	return nil
+}
+
+// Close releases the body on the host. Reads after Close fail.
+func (r *hostBodyReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed, r.buf = true, nil
+
+	reqBytes, err := json.Marshal(entities.HTTPBodyCloseRequest{Handle: r.handle})
+	if err != nil {
+		return fmt.Errorf("failed to marshal body close request: %w", err)
+	}
+	var resp entities.HTTPBodyCloseResponse
+	if err := json.Unmarshal(r.host.closeBody(reqBytes), &resp); err != nil {
+		return fmt.Errorf("failed to unmarshal body close response: %w", err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
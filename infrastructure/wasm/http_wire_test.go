@@ -0,0 +1,359 @@
+package wasm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHost records the wire requests it receives and answers with resp.
+// Streamed bodies are served from bodies by handle, in chunks of at most
+// MaxBytes.
+type fakeHost struct {
+	t        *testing.T
+	bodies   map[uint64][]byte
+	requests []entities.HTTPRequest
+	reads    []entities.HTTPBodyReadRequest
+	closed   []uint64
+	resp     entities.HTTPResponse
+}
+
+func (h *fakeHost) answer(v any) []byte {
+	resp, err := json.Marshal(v)
+	require.NoError(h.t, err)
+	return resp
+}
+
+func (h *fakeHost) request(request []byte) []byte {
+	var req entities.HTTPRequest
+	require.NoError(h.t, json.Unmarshal(request, &req))
+	h.requests = append(h.requests, req)
+	return h.answer(h.resp)
+}
+
+func (h *fakeHost) readBody(request []byte) []byte {
+	var req entities.HTTPBodyReadRequest
+	require.NoError(h.t, json.Unmarshal(request, &req))
+	h.reads = append(h.reads, req)
+	body, ok := h.bodies[req.Handle]
+	if !ok {
+		return h.answer(entities.HTTPBodyReadResponse{Error: &entities.ErrorDetail{Type: "internal", Message: "unknown body handle"}})
+	}
+	n := min(req.MaxBytes, len(body))
+	h.bodies[req.Handle] = body[n:]
+	return h.answer(entities.HTTPBodyReadResponse{
+		Data: base64.StdEncoding.EncodeToString(body[:n]),
+		EOF:  n == len(body),
+	})
+}
+
+func (h *fakeHost) closeBody(request []byte) []byte {
+	var req entities.HTTPBodyCloseRequest
+	require.NoError(h.t, json.Unmarshal(request, &req))
+	h.closed = append(h.closed, req.Handle)
+	delete(h.bodies, req.Handle)
+	return h.answer(entities.HTTPBodyCloseResponse{})
+}
+
+func selfSignedPEM(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "plugin"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestDoHTTP_RedirectsAndTLS(t *testing.T) {
+	certPEM, keyPEM := selfSignedPEM(t)
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 302, Proto: "HTTP/1.1", Body: "bW92ZWQ="}}
+
+	noRedirects := 0
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{
+		Method:       "GET",
+		URL:          "https://internal.example.com/health",
+		MaxRedirects: &noRedirects,
+		TLS: &ports.TLSConfig{
+			ServerName: "internal",
+			RootCAs:    certPEM,
+			ClientCert: certPEM,
+			ClientKey:  keyPEM,
+			MinVersion: tls.VersionTLS13,
+		},
+	}, nil, nil, host, host)
+	require.NoError(t, err)
+	assert.Equal(t, 302, resp.StatusCode)
+	assert.Equal(t, []byte("moved"), resp.Body)
+
+	require.Len(t, host.requests, 1)
+	req := host.requests[0]
+	require.NotNil(t, req.MaxRedirects)
+	assert.Equal(t, 0, *req.MaxRedirects)
+	assert.Equal(t, &entities.HTTPTLSConfig{
+		MinVersion: "TLS 1.3",
+		ServerName: "internal",
+		RootCAs:    string(certPEM),
+		ClientCert: string(certPEM),
+		ClientKey:  string(keyPEM),
+	}, req.TLS)
+}
+
+func TestDoHTTP_Defaults(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 200}}
+	defaultRedirects := 5
+	defaultTLS := &ports.TLSConfig{InsecureSkipVerify: true}
+
+	_, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com"},
+		&defaultRedirects, defaultTLS, host, host)
+	require.NoError(t, err)
+
+	one := 1
+	_, err = doHTTP(context.Background(), ports.HTTPRequest{
+		Method:       "GET",
+		URL:          "https://example.com",
+		MaxRedirects: &one,
+		TLS:          &ports.TLSConfig{ServerName: "example.com"},
+	}, &defaultRedirects, defaultTLS, host, host)
+	require.NoError(t, err)
+
+	_, err = doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com"},
+		nil, nil, host, host)
+	require.NoError(t, err)
+
+	require.Len(t, host.requests, 3)
+	assert.Equal(t, 5, *host.requests[0].MaxRedirects, "adapter default")
+	assert.True(t, host.requests[0].TLS.InsecureSkipVerify, "adapter default")
+	assert.Equal(t, 1, *host.requests[1].MaxRedirects, "request overrides the default")
+	assert.Equal(t, &entities.HTTPTLSConfig{ServerName: "example.com"}, host.requests[1].TLS)
+	assert.Nil(t, host.requests[2].MaxRedirects, "left to the host")
+	assert.Nil(t, host.requests[2].TLS, "left to the host")
+
+	raw, err := json.Marshal(host.requests[2])
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "max_redirects")
+	assert.NotContains(t, string(raw), "tls")
+}
+
+func TestDoHTTP_InvalidSettingsNeverReachHost(t *testing.T) {
+	certPEM, _ := selfSignedPEM(t)
+	_, otherKey := selfSignedPEM(t)
+	negative := -1
+
+	tests := map[string]ports.HTTPRequest{
+		"negative redirects":  {MaxRedirects: &negative},
+		"unknown TLS version": {TLS: &ports.TLSConfig{MinVersion: 0x0300}},
+		"root CAs not PEM":    {TLS: &ports.TLSConfig{RootCAs: []byte("not a certificate")}},
+		"cert without key":    {TLS: &ports.TLSConfig{ClientCert: certPEM}},
+		"mismatched key":      {TLS: &ports.TLSConfig{ClientCert: certPEM, ClientKey: otherKey}},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			host := &fakeHost{t: t}
+			req.Method, req.URL = "GET", "https://example.com"
+			_, err := doHTTP(context.Background(), req, nil, nil, host, host)
+			assert.Error(t, err)
+			assert.Empty(t, host.requests)
+		})
+	}
+}
+
+func TestDoHTTP_CapabilityDenied(t *testing.T) {
+	host := &fakeHost{t: t}
+	ctx := capability.WithDeclared(context.Background(), &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{"example.com"}, Ports: []string{"443"}}}},
+	})
+
+	_, err := doHTTP(ctx, ports.HTTPRequest{Method: "GET", URL: "https://other.example.com"}, nil, nil, host, host)
+	assert.Error(t, err)
+	assert.Empty(t, host.requests)
+}
+
+func TestDoHTTP_HostError(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{Error: &entities.ErrorDetail{Type: "network", Message: "x509: certificate signed by unknown authority"}}}
+
+	_, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com"}, nil, nil, host, host)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown authority")
+}
+
+func TestDoHTTP_RedirectChainAndTimings(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{
+		StatusCode: 200,
+		Redirects: []entities.HTTPRedirect{
+			{URL: "http://example.com/", StatusCode: 301, Location: "https://example.com/"},
+			{URL: "https://example.com/", StatusCode: 302, Location: "/home"},
+		},
+		Timings: &entities.HTTPTimings{DNSMs: 3, ConnectMs: 12, TLSMs: 25, FirstByteMs: 40, TotalMs: 95},
+	}}
+
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "http://example.com/"}, nil, nil, host, host)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ports.HTTPRedirect{
+		{URL: "http://example.com/", StatusCode: 301, Location: "https://example.com/"},
+		{URL: "https://example.com/", StatusCode: 302, Location: "/home"},
+	}, resp.Redirects)
+	assert.Equal(t, &ports.HTTPTimings{
+		DNS:          3 * time.Millisecond,
+		Connect:      12 * time.Millisecond,
+		TLSHandshake: 25 * time.Millisecond,
+		FirstByte:    40 * time.Millisecond,
+		Total:        95 * time.Millisecond,
+	}, resp.Timings)
+
+	host.resp = entities.HTTPResponse{StatusCode: 200}
+	resp, err = doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com/"}, nil, nil, host, host)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Redirects)
+	assert.Nil(t, resp.Timings, "host did not measure timings")
+}
+
+func TestDoHTTP_MultiValueHeadersQueryAndStreamedBody(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 201}}
+
+	_, err := doHTTP(context.Background(), ports.HTTPRequest{
+		Method:       "POST",
+		URL:          "https://example.com/upload?v=2",
+		Headers:      map[string]string{"Content-Type": "text/csv", "accept": "application/json"},
+		HeaderValues: map[string][]string{"Accept": {"text/plain"}},
+		Query:        url.Values{"tag": {"a", "b"}},
+		BodyReader:   strings.NewReader("host,status\n"),
+	}, nil, nil, host, host)
+	require.NoError(t, err)
+
+	require.Len(t, host.requests, 1)
+	req := host.requests[0]
+	assert.Equal(t, "https://example.com/upload?v=2&tag=a&tag=b", req.URL)
+	assert.Equal(t, map[string][]string{
+		"Content-Type": {"text/csv"},
+		"Accept":       {"application/json", "text/plain"},
+	}, req.Headers)
+	assert.Equal(t, "aG9zdCxzdGF0dXMK", req.Body)
+
+	_, err = doHTTP(context.Background(), ports.HTTPRequest{
+		Method:     "POST",
+		URL:        "https://example.com/upload",
+		BodyReader: strings.NewReader(strings.Repeat("x", MaxHTTPBodySize+1)),
+	}, nil, nil, host, host)
+	assert.ErrorContains(t, err, "exceeds")
+	assert.Len(t, host.requests, 1, "oversized body never reaches the host")
+}
+
+func TestDoHTTP_StreamedBody(t *testing.T) {
+	artifact := []byte(strings.Repeat("0123456789abcdef", httpBodyChunkSize/8)) // two chunks
+	artifact = append(artifact, "tail"...)
+	host := &fakeHost{
+		t:      t,
+		resp:   entities.HTTPResponse{StatusCode: 200, BodyHandle: 7},
+		bodies: map[uint64][]byte{7: artifact},
+	}
+
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{
+		Method:     "GET",
+		URL:        "https://example.com/artifact.tar.gz",
+		StreamBody: true,
+	}, nil, nil, host, host)
+	require.NoError(t, err)
+	require.True(t, host.requests[0].StreamBody)
+	assert.Nil(t, resp.Body)
+	require.NotNil(t, resp.BodyReader)
+
+	got, err := io.ReadAll(resp.BodyReader)
+	require.NoError(t, err)
+	assert.Equal(t, artifact, got)
+	require.Len(t, host.reads, 3)
+	for _, read := range host.reads {
+		assert.Equal(t, entities.HTTPBodyReadRequest{Handle: 7, MaxBytes: httpBodyChunkSize}, read)
+	}
+
+	require.NoError(t, resp.BodyReader.Close())
+	require.NoError(t, resp.BodyReader.Close())
+	assert.Equal(t, []uint64{7}, host.closed, "closed once")
+	_, err = resp.BodyReader.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, errBodyClosed)
+}
+
+func TestDoHTTP_StreamedBodyReadError(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 200, BodyHandle: 7}}
+
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com", StreamBody: true}, nil, nil, host, host)
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.BodyReader)
+	assert.ErrorContains(t, err, "unknown body handle")
+	_, err = resp.BodyReader.Read(make([]byte, 1))
+	assert.ErrorContains(t, err, "unknown body handle", "errors are sticky")
+	assert.Len(t, host.reads, 1)
+}
+
+func TestDoHTTP_StreamedBodyInlinedByHost(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 200, Body: "c21hbGw="}}
+
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com", StreamBody: true}, nil, nil, host, host)
+	require.NoError(t, err)
+	assert.Nil(t, resp.Body)
+	got, err := io.ReadAll(resp.BodyReader)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), got)
+	assert.NoError(t, resp.BodyReader.Close())
+	assert.Empty(t, host.reads)
+}
+
+func TestDoHTTP_StreamBodyWithoutBodyHost(t *testing.T) {
+	req := ports.HTTPRequest{Method: "GET", URL: "https://example.com", StreamBody: true}
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 200, Body: "c21hbGw="}}
+
+	resp, err := doHTTP(context.Background(), req, nil, nil, host, nil)
+	require.NoError(t, err)
+	assert.False(t, host.requests[0].StreamBody, "the host is asked for the whole body")
+	got, err := io.ReadAll(resp.BodyReader)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), got)
+
+	host.resp = entities.HTTPResponse{StatusCode: 200, Body: "cGFydA==", BodyTruncated: true}
+	_, err = doHTTP(context.Background(), req, nil, nil, host, nil)
+	assert.ErrorContains(t, err, "cannot stream")
+
+	host.resp = entities.HTTPResponse{StatusCode: 200, BodyHandle: 7}
+	_, err = doHTTP(context.Background(), req, nil, nil, host, nil)
+	assert.ErrorContains(t, err, "did not ask to stream")
+}
+
+func TestDoHTTP_TruncatedBody(t *testing.T) {
+	host := &fakeHost{t: t, resp: entities.HTTPResponse{StatusCode: 200, Body: "cGFydA==", BodyTruncated: true}}
+
+	_, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com"}, nil, nil, host, host)
+	assert.ErrorContains(t, err, "response body too large")
+
+	resp, err := doHTTP(context.Background(), ports.HTTPRequest{Method: "GET", URL: "https://example.com", AllowTruncatedBody: true}, nil, nil, host, host)
+	require.NoError(t, err)
+	assert.True(t, resp.BodyTruncated)
+	assert.Equal(t, []byte("part"), resp.Body)
+}
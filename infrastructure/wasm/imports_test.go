@@ -0,0 +1,158 @@
+//go:build !wasip1
+
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Optional host functions must only be imported by plugins that use them: a
+// host without them cannot instantiate a module that imports them.
+func TestOptionalHostImports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds WASM plugins")
+	}
+
+	tests := []struct {
+		plugin  string
+		present []string
+		absent  []string
+	}{
+		{plugin: "minimal", absent: []string{"reglet_host.context_canceled"}},
+		{plugin: "cancel", present: []string{"reglet_host.context_canceled"}},
+		{
+			plugin:  "httpget",
+			present: []string{"reglet_host.http_request"},
+			absent:  []string{"reglet_host.http_body_read", "reglet_host.http_body_close"},
+		},
+		{
+			plugin:  "stream",
+			present: []string{"reglet_host.http_request", "reglet_host.http_body_read", "reglet_host.http_body_close"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plugin, func(t *testing.T) {
+			imports := wasmImports(t, buildPlugin(t, tt.plugin))
+			for _, name := range tt.present {
+				assert.Contains(t, imports, name)
+			}
+			for _, name := range tt.absent {
+				assert.NotContains(t, imports, name)
+			}
+		})
+	}
+}
+
+// buildPlugin compiles testdata/<name> for wasip1 and returns the module.
+func buildPlugin(t *testing.T, name string) []byte {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), name+".wasm")
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-o", out, "./testdata/"+name)
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "%s", output)
+	module, err := os.ReadFile(out)
+	require.NoError(t, err)
+	return module
+}
+
+// wasmImports lists the imports of a WASM module as "module.name".
+func wasmImports(t *testing.T, module []byte) []string {
+	t.Helper()
+	r := bytes.NewReader(module)
+	header := make([]byte, 8)
+	_, err := r.Read(header)
+	require.NoError(t, err)
+	require.Equal(t, []byte("\x00asm\x01\x00\x00\x00"), header, "not a WASM module")
+
+	for r.Len() > 0 {
+		id, err := r.ReadByte()
+		require.NoError(t, err)
+		size, err := binary.ReadUvarint(r)
+		require.NoError(t, err)
+		section := make([]byte, size)
+		_, err = r.Read(section)
+		require.NoError(t, err)
+		if id == 2 {
+			imports, err := parseImportSection(bytes.NewReader(section))
+			require.NoError(t, err)
+			return imports
+		}
+	}
+	return nil
+}
+
+func parseImportSection(r *bytes.Reader) ([]string, error) {
+	readName := func() (string, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", err
+		}
+		name := make([]byte, n)
+		if _, err := r.Read(name); err != nil {
+			return "", err
+		}
+		return string(name), nil
+	}
+	readLimits := func() error {
+		flags, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if _, err := binary.ReadUvarint(r); err != nil {
+			return err
+		}
+		if flags&1 != 0 {
+			_, err = binary.ReadUvarint(r)
+		}
+		return err
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		module, err := readName()
+		if err != nil {
+			return nil, err
+		}
+		name, err := readName()
+		if err != nil {
+			return nil, err
+		}
+		kind, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case 0: // function: type index
+			_, err = binary.ReadUvarint(r)
+		case 1: // table: element type and limits
+			if _, err = r.ReadByte(); err == nil {
+				err = readLimits()
+			}
+		case 2: // memory: limits
+			err = readLimits()
+		case 3: // global: value type and mutability
+			_, err = r.Seek(2, 1)
+		default:
+			err = fmt.Errorf("unknown import kind %d", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, module+"."+name)
+	}
+	return imports, nil
+}
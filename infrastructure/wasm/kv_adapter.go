@@ -0,0 +1,140 @@
+//go:build wasip1
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
+)
+
+// Compile-time interface compliance check
+var _ ports.KeyValueStore = (*KVAdapter)(nil)
+
+// KVAdapter implements ports.KeyValueStore for the WASM environment.
+type KVAdapter struct{}
+
+// NewKVAdapter creates a new KVAdapter.
+func NewKVAdapter() *KVAdapter {
+	return &KVAdapter{}
+}
+
+// Get returns the entry for key, or nil if it does not exist.
+// It first checks key against the declared capabilities.
+func (a *KVAdapter) Get(ctx context.Context, key string) (*ports.KeyValueEntry, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpRead, key); err != nil {
+		return nil, err
+	}
+	res, err := a.call(ctx, entities.KVRequest{Op: "get", Key: key})
+	if err != nil {
+		return nil, err
+	}
+	return toKeyValueEntry(res.Entry), nil
+}
+
+// Set stores value under key.
+// It first checks key against the declared capabilities.
+func (a *KVAdapter) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (*ports.KeyValueEntry, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return nil, err
+	}
+	res, err := a.call(ctx, entities.KVRequest{Op: "set", Key: key, Value: value, TTLMs: ttl.Milliseconds()})
+	if err != nil {
+		return nil, err
+	}
+	return toKeyValueEntry(res.Entry), nil
+}
+
+// Delete removes key.
+// It first checks key against the declared capabilities.
+func (a *KVAdapter) Delete(ctx context.Context, key string) error {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return err
+	}
+	_, err := a.call(ctx, entities.KVRequest{Op: "delete", Key: key})
+	return err
+}
+
+// List returns the keys starting with prefix, omitting keys the declared
+// capabilities do not allow reading.
+func (a *KVAdapter) List(ctx context.Context, prefix string) ([]string, error) {
+	res, err := a.call(ctx, entities.KVRequest{Op: "list", Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	return capability.ReadableKeys(ctx, res.Keys), nil
+}
+
+// CompareAndSwap stores value under key if its revision matches.
+// It first checks key against the declared capabilities.
+func (a *KVAdapter) CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*ports.KeyValueEntry, bool, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return nil, false, err
+	}
+	res, err := a.call(ctx, entities.KVRequest{
+		Op:       "cas",
+		Key:      key,
+		Value:    value,
+		Revision: revision,
+		TTLMs:    ttl.Milliseconds(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return toKeyValueEntry(res.Entry), res.Swapped, nil
+}
+
+// call sends a request to the host and decodes the response.
+func (a *KVAdapter) call(ctx context.Context, req entities.KVRequest) (*entities.KVResponse, error) {
+	// 1. Prepare wire request with context
+	req.Context = wasmcontext.ContextToWire(ctx)
+
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// 2. Send to host
+	reqPacked := abi.PtrFromBytes(reqData)
+	defer abi.DeallocatePacked(reqPacked)
+
+	resPacked := host_kv_operation(reqPacked)
+
+	// 3. Read response
+	resBytes := abi.BytesFromPtr(resPacked)
+	if resBytes == nil {
+		return nil, fmt.Errorf("host returned null response")
+	}
+	defer abi.DeallocatePacked(resPacked) // Free host-allocated response memory
+
+	var wireRes entities.KVResponse
+	if err := json.Unmarshal(resBytes, &wireRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// 4. Handle errors
+	if wireRes.Error != nil {
+		return nil, wireRes.Error
+	}
+	return &wireRes, nil
+}
+
+// toKeyValueEntry converts a wire entry to the port type.
+func toKeyValueEntry(e *entities.KVEntry) *ports.KeyValueEntry {
+	if e == nil {
+		return nil
+	}
+	return &ports.KeyValueEntry{
+		ExpiresAt: e.ExpiresAt,
+		Key:       e.Key,
+		Value:     e.Value,
+		Revision:  e.Revision,
+	}
+}
@@ -0,0 +1,48 @@
+//go:build !wasip1
+
+package wasm
+
+import (
+	"context"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// Compile-time interface compliance check
+var _ ports.KeyValueStore = (*KVAdapter)(nil)
+
+// KVAdapter stub for native builds.
+type KVAdapter struct{}
+
+// NewKVAdapter creates a new KVAdapter stub.
+func NewKVAdapter() *KVAdapter {
+	return &KVAdapter{}
+}
+
+const kvStubPanic = "WASM KV adapter not available in native build. Use kv.WithStore() to inject a fake."
+
+// Get panics because the WASM key-value store is not available natively.
+func (a *KVAdapter) Get(ctx context.Context, key string) (*ports.KeyValueEntry, error) {
+	panic(kvStubPanic)
+}
+
+// Set panics because the WASM key-value store is not available natively.
+func (a *KVAdapter) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (*ports.KeyValueEntry, error) {
+	panic(kvStubPanic)
+}
+
+// Delete panics because the WASM key-value store is not available natively.
+func (a *KVAdapter) Delete(ctx context.Context, key string) error {
+	panic(kvStubPanic)
+}
+
+// List panics because the WASM key-value store is not available natively.
+func (a *KVAdapter) List(ctx context.Context, prefix string) ([]string, error) {
+	panic(kvStubPanic)
+}
+
+// CompareAndSwap panics because the WASM key-value store is not available natively.
+func (a *KVAdapter) CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*ports.KeyValueEntry, bool, error) {
+	panic(kvStubPanic)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
 	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
 )
 
@@ -27,6 +28,10 @@ func NewSMTPAdapter() *SMTPAdapter {
 
 // Connect establishes an SMTP connection to the given host and port.
 func (a *SMTPAdapter) Connect(ctx context.Context, host, port string, timeout time.Duration, useTLS, useStartTLS bool) (*ports.SMTPConnectResult, error) {
+	if err := capability.CheckNetwork(ctx, host, port); err != nil {
+		return nil, err
+	}
+
 	request := entities.SMTPRequest{
 		Context:   wasmcontext.ContextToWire(ctx),
 		Host:      host,
@@ -12,6 +12,7 @@ import (
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/reglet-dev/reglet-plugin-sdk/internal/abi"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
 	wasmcontext "github.com/reglet-dev/reglet-plugin-sdk/internal/wasmcontext"
 )
 
@@ -42,6 +43,9 @@ func (a *TCPAdapter) DialSecure(ctx context.Context, address string, timeoutMs i
 	if err != nil {
 		return nil, fmt.Errorf("invalid address: %w", err)
 	}
+	if err := capability.CheckNetwork(ctx, host, port); err != nil {
+		return nil, err
+	}
 
 	request := entities.TCPRequest{
 		Context:   wasmcontext.ContextToWire(ctx),
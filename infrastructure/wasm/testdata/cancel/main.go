@@ -0,0 +1,39 @@
+// Command cancel polls the host for cancellation, so it imports context_canceled.
+package main
+
+import (
+	"context"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+)
+
+type Input struct {
+	Targets []string `json:"targets"`
+}
+
+type Output struct {
+	Checked int `json:"checked"`
+}
+
+type LoopService struct {
+	plugin.Service `name:"loop" desc:"Long-running loop"`
+
+	Run plugin.Op[Input, Output] `desc:"Check every target" method:"RunHandler"`
+}
+
+func (s *LoopService) RunHandler(ctx context.Context, in *Input) (*Output, error) {
+	out := &Output{}
+	for range in.Targets {
+		if err := plugin.CheckCanceled(ctx); err != nil {
+			return nil, err
+		}
+		out.Checked++
+	}
+	return out, nil
+}
+
+func main() {
+	p := plugin.DefinePlugin(plugin.PluginDef{Name: "cancel", Version: "1.0.0"})
+	plugin.MustRegisterService(p, &LoopService{})
+	plugin.Register(p)
+}
@@ -0,0 +1,38 @@
+// Command httpget fetches URLs without streaming, so it imports neither
+// http_body_read nor http_body_close.
+package main
+
+import (
+	"context"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	sdknet "github.com/reglet-dev/reglet-plugin-sdk/net"
+)
+
+type Input struct {
+	URL string `json:"url"`
+}
+
+type Output struct {
+	Status int `json:"status"`
+}
+
+type FetchService struct {
+	plugin.Service `name:"fetch" desc:"Fetch a URL"`
+
+	Get plugin.Op[Input, Output] `desc:"GET the URL" method:"GetHandler"`
+}
+
+func (s *FetchService) GetHandler(ctx context.Context, in *Input) (*Output, error) {
+	resp, err := sdknet.NewTransport().Get(ctx, in.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &Output{Status: resp.StatusCode}, nil
+}
+
+func main() {
+	p := plugin.DefinePlugin(plugin.PluginDef{Name: "httpget", Version: "1.0.0"})
+	plugin.MustRegisterService(p, &FetchService{})
+	plugin.Register(p)
+}
@@ -0,0 +1,33 @@
+// Command minimal is the smallest plugin: it must import nothing beyond the
+// host functions every plugin needs.
+package main
+
+import (
+	"context"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+)
+
+type Input struct {
+	Name string `json:"name"`
+}
+
+type Output struct {
+	Greeting string `json:"greeting"`
+}
+
+type GreetService struct {
+	plugin.Service `name:"greet" desc:"Greetings"`
+
+	Greet plugin.Op[Input, Output] `desc:"Greet someone" method:"GreetHandler"`
+}
+
+func (s *GreetService) GreetHandler(ctx context.Context, in *Input) (*Output, error) {
+	return &Output{Greeting: "hello, " + in.Name}, nil
+}
+
+func main() {
+	p := plugin.DefinePlugin(plugin.PluginDef{Name: "minimal", Version: "1.0.0"})
+	plugin.MustRegisterService(p, &GreetService{})
+	plugin.Register(p)
+}
@@ -0,0 +1,48 @@
+// Command stream reads response bodies in chunks, so it imports
+// http_body_read and http_body_close.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	sdknet "github.com/reglet-dev/reglet-plugin-sdk/net"
+)
+
+type Input struct {
+	URL string `json:"url"`
+}
+
+type Output struct {
+	SHA256 string `json:"sha256"`
+}
+
+type HashService struct {
+	plugin.Service `name:"hash" desc:"Hash a download"`
+
+	Hash plugin.Op[Input, Output] `desc:"Hash the body of the URL" method:"HashHandler"`
+}
+
+func (s *HashService) HashHandler(ctx context.Context, in *Input) (*Output, error) {
+	client := sdknet.NewTransport(sdknet.WithStreamBody())
+	resp, err := client.Do(ctx, ports.HTTPRequest{Method: "GET", URL: in.URL, StreamBody: true})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.BodyReader.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.BodyReader); err != nil {
+		return nil, err
+	}
+	return &Output{SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func main() {
+	p := plugin.DefinePlugin(plugin.PluginDef{Name: "stream", Version: "1.0.0"})
+	plugin.MustRegisterService(p, &HashService{})
+	plugin.Register(p)
+}
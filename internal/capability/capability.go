@@ -0,0 +1,458 @@
+// Package capability checks host calls against the capabilities a plugin
+// declared, so undeclared access fails in the guest with a clear error
+// instead of being denied by the host.
+package capability
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+)
+
+// Key-value operations, as used in entities.KeyValueRule.Operation.
+const (
+	OpRead      = "read"
+	OpWrite     = "write"
+	OpReadWrite = "read-write"
+)
+
+// Filesystem operations, matching the FileSystemRule fields.
+const (
+	FSRead  = "read"
+	FSWrite = "write"
+)
+
+// declaredKey is the context key for the declared grants.
+type declaredKey struct{}
+
+// WithDeclared returns a new context carrying the grants declared for the
+// current operation.
+func WithDeclared(ctx context.Context, grants *entities.GrantSet) context.Context {
+	return context.WithValue(ctx, declaredKey{}, grants)
+}
+
+// Declared returns the grants declared for the current operation.
+// It returns false when the context carries none, in which case only the
+// host enforces capabilities.
+func Declared(ctx context.Context) (*entities.GrantSet, bool) {
+	grants, ok := ctx.Value(declaredKey{}).(*entities.GrantSet)
+	return grants, ok && grants != nil
+}
+
+// recorderKey is the context key for the capability recorder.
+type recorderKey struct{}
+
+// WithRecorder returns a new context that passes every capability request
+// checked under it to record instead of enforcing the declared grants, so a
+// whole run can be observed even when the declaration is incomplete. record
+// gets the context of the check, so it can tell which operation asked.
+func WithRecorder(ctx context.Context, record func(ctx context.Context, requested *entities.GrantSet)) context.Context {
+	return context.WithValue(ctx, recorderKey{}, record)
+}
+
+// check returns a *errors.CapabilityError naming what is missing if the
+// declared grants in ctx do not cover requested.
+func check(ctx context.Context, requested *entities.GrantSet, required, pattern string) error {
+	if record, ok := ctx.Value(recorderKey{}).(func(context.Context, *entities.GrantSet)); ok && record != nil {
+		record(ctx, requested)
+		return nil
+	}
+
+	declared, ok := Declared(ctx)
+	if !ok {
+		return nil
+	}
+	missing := Missing(declared, requested)
+	if missing == nil {
+		return nil
+	}
+	return &errors.CapabilityError{Required: required, Pattern: pattern, Missing: missing}
+}
+
+// Missing returns the parts of requested that declared does not cover, or nil
+// if declared covers all of it. It follows entities.GrantSet.Contains, except
+// that declared rules may use wildcards: hosts such as "*" or "*.example.com",
+// ports such as "*" or "8000-9000", "*" in commands, variables and keys, and
+// path patterns as described in MatchPath.
+func Missing(declared, requested *entities.GrantSet) *entities.GrantSet {
+	if requested == nil {
+		return nil
+	}
+
+	missing := &entities.GrantSet{}
+	if requested.Network != nil {
+		var rules []entities.NetworkRule
+		for _, rule := range requested.Network.Rules {
+			for _, host := range rule.Hosts {
+				for _, port := range rule.Ports {
+					if !AllowsNetwork(declared, host, port) {
+						rules = append(rules, entities.NetworkRule{Hosts: []string{host}, Ports: []string{port}})
+					}
+				}
+			}
+		}
+		if len(rules) > 0 {
+			missing.Network = &entities.NetworkCapability{Rules: rules}
+		}
+	}
+	if requested.FS != nil {
+		var rule entities.FileSystemRule
+		for _, r := range requested.FS.Rules {
+			rule.Read = append(rule.Read, notAllowed(r.Read, func(p string) bool {
+				return AllowsFileSystem(declared, FSRead, p)
+			})...)
+			rule.Write = append(rule.Write, notAllowed(r.Write, func(p string) bool {
+				return AllowsFileSystem(declared, FSWrite, p)
+			})...)
+		}
+		if len(rule.Read) > 0 || len(rule.Write) > 0 {
+			missing.FS = &entities.FileSystemCapability{Rules: []entities.FileSystemRule{rule}}
+		}
+	}
+	if requested.Env != nil {
+		if vars := notAllowed(requested.Env.Variables, func(name string) bool {
+			return AllowsEnvironment(declared, name)
+		}); len(vars) > 0 {
+			missing.Env = &entities.EnvironmentCapability{Variables: vars}
+		}
+	}
+	if requested.Exec != nil {
+		if cmds := notAllowed(requested.Exec.Commands, func(cmd string) bool {
+			return AllowsExec(declared, cmd)
+		}); len(cmds) > 0 {
+			missing.Exec = &entities.ExecCapability{Commands: cmds}
+		}
+	}
+	if requested.KV != nil {
+		var rules []entities.KeyValueRule
+		for _, r := range requested.KV.Rules {
+			if keys := notAllowed(r.Keys, func(key string) bool {
+				if r.Operation == OpReadWrite {
+					return AllowsKeyValue(declared, OpRead, key) && AllowsKeyValue(declared, OpWrite, key)
+				}
+				return AllowsKeyValue(declared, r.Operation, key)
+			}); len(keys) > 0 {
+				rules = append(rules, entities.KeyValueRule{Operation: r.Operation, Keys: keys})
+			}
+		}
+		if len(rules) > 0 {
+			missing.KV = &entities.KeyValueCapability{Rules: rules}
+		}
+	}
+
+	if missing.IsEmpty() {
+		return nil
+	}
+	return missing
+}
+
+// notAllowed returns the values that allowed rejects.
+func notAllowed(values []string, allowed func(string) bool) []string {
+	var out []string
+	for _, v := range values {
+		if !allowed(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// CheckNetwork returns a *errors.CapabilityError if the declared grants in
+// ctx do not allow connecting to host on port.
+func CheckNetwork(ctx context.Context, host, port string) error {
+	requested := &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{host}, Ports: []string{port}}}},
+	}
+	return check(ctx, requested, "network:outbound", net.JoinHostPort(host, port))
+}
+
+// CheckAddress is CheckNetwork for a "host:port" address.
+func CheckAddress(ctx context.Context, address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// Leave malformed addresses to the dialer, which reports them properly.
+		return nil
+	}
+	return CheckNetwork(ctx, host, port)
+}
+
+// CheckURL is CheckNetwork for the host and port of rawURL. A URL without a
+// port uses the scheme's default: 443 for https and 80 otherwise.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		// Leave malformed URLs to the client, which reports them properly.
+		return nil
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			port = "443"
+		}
+	}
+	return CheckNetwork(ctx, u.Hostname(), port)
+}
+
+// CheckDNS returns a *errors.CapabilityError if the declared grants in ctx do
+// not allow a DNS query for hostname through nameserver, which needs port 53
+// of nameserver unless it names a port. Without a nameserver the host's own
+// resolver answers, which never connects to hostname, so nothing is checked.
+func CheckDNS(ctx context.Context, hostname, nameserver string) error {
+	if nameserver == "" {
+		return nil
+	}
+	if host, port, err := net.SplitHostPort(nameserver); err == nil {
+		return CheckNetwork(ctx, host, port)
+	}
+	return CheckNetwork(ctx, nameserver, "53")
+}
+
+// AllowsNetwork reports whether grants allow connecting to host on port.
+func AllowsNetwork(grants *entities.GrantSet, host, port string) bool {
+	if grants == nil || grants.Network == nil {
+		return false
+	}
+	for _, rule := range grants.Network.Rules {
+		if !matchAny(rule.Hosts, host, MatchHost) {
+			continue
+		}
+		if matchAny(rule.Ports, port, MatchPort) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether value matches any of patterns.
+func matchAny(patterns []string, value string, match func(pattern, value string) bool) bool {
+	for _, pattern := range patterns {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchHost reports whether host matches pattern, ignoring case and any
+// trailing dot. A "*" in pattern matches any sequence of characters, so
+// "*.example.com" matches every subdomain of example.com but not example.com.
+func MatchHost(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	return MatchKey(pattern, host)
+}
+
+// MatchPort reports whether port matches pattern. Either may be a single
+// port, a range such as "8000-9000", or "*" for every port; port matches
+// when its whole range lies within pattern's.
+func MatchPort(pattern, port string) bool {
+	plo, phi, ok := portRange(pattern)
+	if !ok {
+		return false
+	}
+	lo, hi, ok := portRange(port)
+	return ok && plo <= lo && hi <= phi
+}
+
+// portRange parses a port, range or "*" into an inclusive range.
+func portRange(s string) (lo, hi int, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return 0, 65535, true
+	}
+	first, last, isRange := strings.Cut(s, "-")
+	lo, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, false
+	}
+	hi = lo
+	if isRange {
+		if hi, err = strconv.Atoi(last); err != nil {
+			return 0, 0, false
+		}
+	}
+	return lo, hi, lo >= 0 && lo <= hi && hi <= 65535
+}
+
+// CheckExec returns a *errors.CapabilityError if the declared grants in ctx
+// do not allow running command.
+func CheckExec(ctx context.Context, command string) error {
+	requested := &entities.GrantSet{
+		Exec: &entities.ExecCapability{Commands: []string{command}},
+	}
+	return check(ctx, requested, "exec", command)
+}
+
+// AllowsExec reports whether grants allow running command. Command patterns
+// use "*" as a wildcard, e.g. "/usr/bin/*".
+func AllowsExec(grants *entities.GrantSet, command string) bool {
+	if grants == nil || grants.Exec == nil {
+		return false
+	}
+	return matchAny(grants.Exec.Commands, command, MatchKey)
+}
+
+// CheckKeyValue returns a *errors.CapabilityError if the declared grants in
+// ctx do not allow op ("read" or "write") on key.
+func CheckKeyValue(ctx context.Context, op, key string) error {
+	requested := &entities.GrantSet{
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{{Operation: op, Keys: []string{key}}}},
+	}
+	return check(ctx, requested, "kv:"+op, key)
+}
+
+// AllowsKeyValue reports whether grants allow op ("read" or "write") on key.
+func AllowsKeyValue(grants *entities.GrantSet, op, key string) bool {
+	if grants == nil || grants.KV == nil {
+		return false
+	}
+	for _, rule := range grants.KV.Rules {
+		if rule.Operation != op && rule.Operation != OpReadWrite {
+			continue
+		}
+		for _, pattern := range rule.Keys {
+			if MatchKey(pattern, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadableKeys returns the keys the declared grants in ctx allow reading, in
+// their original order. Without declared grants every key is returned.
+func ReadableKeys(ctx context.Context, keys []string) []string {
+	grants, ok := Declared(ctx)
+	if !ok {
+		return keys
+	}
+	readable := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if AllowsKeyValue(grants, OpRead, key) {
+			readable = append(readable, key)
+		}
+	}
+	return readable
+}
+
+// MatchKey reports whether key matches pattern. A "*" in pattern matches any
+// sequence of characters, including "/"; every other character matches itself.
+func MatchKey(pattern, key string) bool {
+	// Iterative wildcard matching with backtracking to the last "*".
+	p, k := 0, 0
+	star, mark := -1, 0
+	for k < len(key) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, k
+			p++
+		case p < len(pattern) && pattern[p] == key[k]:
+			p++
+			k++
+		case star >= 0:
+			p = star + 1
+			mark++
+			k = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// CheckFileSystem returns a *errors.CapabilityError if the declared grants in
+// ctx do not allow op ("read" or "write") on the file at p.
+func CheckFileSystem(ctx context.Context, op, p string) error {
+	p = path.Clean(p)
+	rule := entities.FileSystemRule{Read: []string{p}}
+	if op == FSWrite {
+		rule = entities.FileSystemRule{Write: []string{p}}
+	}
+	requested := &entities.GrantSet{
+		FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{rule}},
+	}
+	return check(ctx, requested, "fs:"+op, p)
+}
+
+// AllowsFileSystem reports whether grants allow op ("read" or "write") on the file at p.
+func AllowsFileSystem(grants *entities.GrantSet, op, p string) bool {
+	if grants == nil || grants.FS == nil {
+		return false
+	}
+	for _, rule := range grants.FS.Rules {
+		patterns := rule.Read
+		if op == FSWrite {
+			patterns = rule.Write
+		}
+		for _, pattern := range patterns {
+			if MatchPath(pattern, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether the file at p matches pattern. Both are cleaned
+// first, so "/etc/../root" is checked as "/root". A pattern ending in "/**"
+// matches the directory and everything below it; otherwise pattern uses
+// path.Match syntax, where "*" does not cross "/".
+func MatchPath(pattern, p string) bool {
+	p = path.Clean(p)
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if dir == "" || dir == "/" {
+			return strings.HasPrefix(p, "/")
+		}
+		dir = path.Clean(dir)
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+		matched, _ := path.Match(dir, p)
+		return matched || matchParent(dir, p)
+	}
+	matched, err := path.Match(path.Clean(pattern), p)
+	return err == nil && matched
+}
+
+// matchParent reports whether some parent directory of p matches the glob dir.
+func matchParent(dir, p string) bool {
+	for parent := path.Dir(p); parent != p; p, parent = parent, path.Dir(parent) {
+		if matched, _ := path.Match(dir, parent); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckEnvironment returns a *errors.CapabilityError if the declared grants
+// in ctx do not allow reading the named environment variable.
+func CheckEnvironment(ctx context.Context, name string) error {
+	requested := &entities.GrantSet{
+		Env: &entities.EnvironmentCapability{Variables: []string{name}},
+	}
+	return check(ctx, requested, "env", name)
+}
+
+// AllowsEnvironment reports whether grants allow reading the named variable.
+// Variable patterns use "*" as a wildcard, e.g. "AWS_*".
+func AllowsEnvironment(grants *entities.GrantSet, name string) bool {
+	if grants == nil || grants.Env == nil {
+		return false
+	}
+	for _, pattern := range grants.Env.Variables {
+		if MatchKey(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+package capability
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "anything/at/all", true},
+		{"certs/fingerprint", "certs/fingerprint", true},
+		{"certs/fingerprint", "certs/fingerprint2", false},
+		{"certs/*", "certs/example.com", true},
+		{"certs/*", "certs/a/b", true},
+		{"certs/*", "dns/example.com", false},
+		{"*/answers", "dns/example.com/answers", true},
+		{"*/answers", "dns/example.com/answers/old", false},
+		{"dns/*/a*", "dns/x/aaaa", true},
+		{"", "", true},
+		{"", "k", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchKey(tt.pattern, tt.key), "%q vs %q", tt.pattern, tt.key)
+	}
+}
+
+func TestCheckKeyValue(t *testing.T) {
+	grants := &entities.GrantSet{
+		KV: &entities.KeyValueCapability{
+			Rules: []entities.KeyValueRule{
+				{Operation: OpRead, Keys: []string{"shared/*"}},
+				{Operation: OpReadWrite, Keys: []string{"certs/*"}},
+			},
+		},
+	}
+
+	assert.NoError(t, CheckKeyValue(context.Background(), OpWrite, "anything"), "no declared grants")
+
+	ctx := WithDeclared(context.Background(), grants)
+	assert.NoError(t, CheckKeyValue(ctx, OpRead, "shared/config"))
+	assert.NoError(t, CheckKeyValue(ctx, OpRead, "certs/example.com"))
+	assert.NoError(t, CheckKeyValue(ctx, OpWrite, "certs/example.com"))
+
+	err := CheckKeyValue(ctx, OpWrite, "shared/config")
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "kv:write", capErr.Required)
+	assert.Equal(t, "shared/config", capErr.Pattern)
+
+	ctx = WithDeclared(context.Background(), &entities.GrantSet{})
+	assert.Error(t, CheckKeyValue(ctx, OpRead, "certs/example.com"), "declared grants without KV")
+}
+
+func TestReadableKeys(t *testing.T) {
+	keys := []string{"certs/example.com", "secret/b", "shared/a"}
+	assert.Equal(t, keys, ReadableKeys(context.Background(), keys), "no declared grants")
+
+	ctx := WithDeclared(context.Background(), &entities.GrantSet{
+		KV: &entities.KeyValueCapability{
+			Rules: []entities.KeyValueRule{
+				{Operation: OpRead, Keys: []string{"shared/*"}},
+				{Operation: OpWrite, Keys: []string{"secret/*"}},
+				{Operation: OpReadWrite, Keys: []string{"certs/*"}},
+			},
+		},
+	})
+	assert.Equal(t, []string{"certs/example.com", "shared/a"}, ReadableKeys(ctx, keys))
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/etc/ssh/sshd_config", "/etc/ssh/sshd_config", true},
+		{"/etc/ssh/sshd_config", "/etc/ssh/ssh_config", false},
+		{"/etc/sysctl.d/*.conf", "/etc/sysctl.d/10-net.conf", true},
+		{"/etc/sysctl.d/*.conf", "/etc/sysctl.d/sub/10-net.conf", false},
+		{"/etc/ssh/**", "/etc/ssh", true},
+		{"/etc/ssh/**", "/etc/ssh/sshd_config.d/50-cloud.conf", true},
+		{"/etc/ssh/**", "/etc/sshd", false},
+		{"/etc/ssh/**", "/etc/ssh/../shadow", false},
+		{"/home/*/.ssh/**", "/home/alice/.ssh/authorized_keys", true},
+		{"/home/*/.ssh/**", "/home/alice/notes", false},
+		{"/**", "/anything/at/all", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPath(tt.pattern, tt.path), "%q vs %q", tt.pattern, tt.path)
+	}
+}
+
+func TestCheckFileSystem(t *testing.T) {
+	grants := &entities.GrantSet{
+		FS: &entities.FileSystemCapability{
+			Rules: []entities.FileSystemRule{
+				{Read: []string{"/etc/ssh/**"}, Write: []string{"/tmp/**"}},
+			},
+		},
+	}
+
+	assert.NoError(t, CheckFileSystem(context.Background(), FSRead, "/etc/shadow"), "no declared grants")
+
+	ctx := WithDeclared(context.Background(), grants)
+	assert.NoError(t, CheckFileSystem(ctx, FSRead, "/etc/ssh/sshd_config"))
+	assert.NoError(t, CheckFileSystem(ctx, FSWrite, "/tmp/out"))
+
+	err := CheckFileSystem(ctx, FSRead, "/etc/ssh/../shadow")
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "fs:read", capErr.Required)
+	assert.Equal(t, "/etc/shadow", capErr.Pattern)
+
+	assert.Error(t, CheckFileSystem(ctx, FSRead, "/tmp/out"), "write grant does not imply read")
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	ctx := WithDeclared(context.Background(), &entities.GrantSet{
+		Env: &entities.EnvironmentCapability{Variables: []string{"AWS_*", "HTTPS_PROXY"}},
+	})
+
+	assert.NoError(t, CheckEnvironment(ctx, "AWS_REGION"))
+	assert.NoError(t, CheckEnvironment(ctx, "HTTPS_PROXY"))
+
+	err := CheckEnvironment(ctx, "HOME")
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "env", capErr.Required)
+	assert.Equal(t, "HOME", capErr.Pattern)
+
+	assert.NoError(t, CheckEnvironment(context.Background(), "HOME"), "no declared grants")
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"*", "anything.example.com", true},
+		{"api.example.com", "api.example.com", true},
+		{"api.example.com", "API.Example.com.", true},
+		{"api.example.com", "www.example.com", false},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "example.org", false},
+		{"10.0.0.1", "10.0.0.1", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchHost(tt.pattern, tt.host), "%q vs %q", tt.pattern, tt.host)
+	}
+}
+
+func TestMatchPort(t *testing.T) {
+	tests := []struct {
+		pattern string
+		port    string
+		want    bool
+	}{
+		{"*", "443", true},
+		{"443", "443", true},
+		{"443", "80", false},
+		{"8000-9000", "8000", true},
+		{"8000-9000", "9000", true},
+		{"8000-9000", "9001", false},
+		{"8000-9000", "8080-8090", true},
+		{"8000-9000", "7000-8080", false},
+		{"443", "*", false},
+		{"9000-8000", "8500", false},
+		{"http", "80", false},
+		{"443", "https", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchPort(tt.pattern, tt.port), "%q vs %q", tt.pattern, tt.port)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	declared := &entities.GrantSet{
+		Network: &entities.NetworkCapability{
+			Rules: []entities.NetworkRule{{Hosts: []string{"*.example.com"}, Ports: []string{"443", "8000-9000"}}},
+		},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/*"}},
+		KV: &entities.KeyValueCapability{
+			Rules: []entities.KeyValueRule{{Operation: OpRead, Keys: []string{"shared/*"}}},
+		},
+	}
+
+	assert.Nil(t, Missing(declared, nil))
+	assert.Nil(t, Missing(declared, &entities.GrantSet{}))
+	assert.Nil(t, Missing(declared, &entities.GrantSet{
+		Network: &entities.NetworkCapability{
+			Rules: []entities.NetworkRule{{Hosts: []string{"api.example.com", "www.example.com"}, Ports: []string{"443", "8080"}}},
+		},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+	}))
+
+	missing := Missing(declared, &entities.GrantSet{
+		Network: &entities.NetworkCapability{
+			Rules: []entities.NetworkRule{{Hosts: []string{"api.example.com", "example.org"}, Ports: []string{"443"}}},
+		},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/id", "/bin/sh"}},
+		KV: &entities.KeyValueCapability{
+			Rules: []entities.KeyValueRule{{Operation: OpReadWrite, Keys: []string{"shared/a"}}},
+		},
+	})
+	require.NotNil(t, missing)
+	assert.Equal(t, []entities.NetworkRule{{Hosts: []string{"example.org"}, Ports: []string{"443"}}}, missing.Network.Rules)
+	assert.Equal(t, []string{"/bin/sh"}, missing.Exec.Commands)
+	assert.Equal(t, []entities.KeyValueRule{{Operation: OpReadWrite, Keys: []string{"shared/a"}}}, missing.KV.Rules)
+	assert.Nil(t, missing.FS)
+	assert.Nil(t, missing.Env)
+
+	exact := &entities.GrantSet{Exec: &entities.ExecCapability{Commands: []string{"/bin/sh"}}}
+	assert.Equal(t, exact.Contains(exact), Missing(exact, exact) == nil, "agrees with Contains for exact grants")
+}
+
+func TestCheckNetwork(t *testing.T) {
+	grants := &entities.GrantSet{
+		Network: &entities.NetworkCapability{
+			Rules: []entities.NetworkRule{
+				{Hosts: []string{"*.example.com"}, Ports: []string{"443"}},
+				{Hosts: []string{"*"}, Ports: []string{"53"}},
+			},
+		},
+	}
+
+	assert.NoError(t, CheckNetwork(context.Background(), "example.org", "22"), "no declared grants")
+
+	ctx := WithDeclared(context.Background(), grants)
+	assert.NoError(t, CheckNetwork(ctx, "api.example.com", "443"))
+	assert.NoError(t, CheckAddress(ctx, "api.example.com:443"))
+	assert.NoError(t, CheckURL(ctx, "https://api.example.com/v1/status"))
+	assert.NoError(t, CheckDNS(ctx, "anything.org", ""))
+	assert.NoError(t, CheckDNS(ctx, "anything.org", "8.8.8.8"))
+	assert.NoError(t, CheckURL(ctx, "::not a url"), "malformed URLs are left to the client")
+
+	err := CheckURL(ctx, "http://api.example.com/v1/status")
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "network:outbound", capErr.Required)
+	assert.Equal(t, "api.example.com:80", capErr.Pattern)
+	require.NotNil(t, capErr.Missing)
+	assert.Equal(t, []entities.NetworkRule{{Hosts: []string{"api.example.com"}, Ports: []string{"80"}}}, capErr.Missing.Network.Rules)
+
+	require.ErrorAs(t, CheckAddress(ctx, "[2001:db8::1]:8443"), &capErr)
+	assert.Equal(t, "[2001:db8::1]:8443", capErr.Pattern)
+
+	require.ErrorAs(t, CheckDNS(ctx, "example.com", "10.0.0.53:5353"), &capErr)
+	assert.Equal(t, "10.0.0.53:5353", capErr.Pattern)
+
+	// The host's resolver never connects to the queried name
+	httpsOnly := WithDeclared(context.Background(), &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{{Hosts: []string{"example.com"}, Ports: []string{"443"}}}},
+	})
+	assert.NoError(t, CheckDNS(httpsOnly, "example.com", ""))
+	require.ErrorAs(t, CheckDNS(httpsOnly, "example.com", "8.8.8.8"), &capErr)
+	assert.Equal(t, "8.8.8.8:53", capErr.Pattern)
+}
+
+func TestCheckExec(t *testing.T) {
+	grants := &entities.GrantSet{
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl", "/usr/sbin/*"}},
+	}
+
+	assert.NoError(t, CheckExec(context.Background(), "/bin/sh"), "no declared grants")
+
+	ctx := WithDeclared(context.Background(), grants)
+	assert.NoError(t, CheckExec(ctx, "/usr/bin/systemctl"))
+	assert.NoError(t, CheckExec(ctx, "/usr/sbin/sshd"))
+
+	err := CheckExec(ctx, "/bin/sh")
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "exec", capErr.Required)
+	assert.Equal(t, "/bin/sh", capErr.Pattern)
+	assert.Equal(t, []string{"/bin/sh"}, capErr.Missing.Exec.Commands)
+}
+
+func TestWithRecorder(t *testing.T) {
+	var recorded []*entities.GrantSet
+	declared := &entities.GrantSet{}
+	ctx := WithRecorder(context.Background(), func(ctx context.Context, requested *entities.GrantSet) {
+		grants, _ := Declared(ctx)
+		assert.Same(t, declared, grants, "the recorder sees the context of the check")
+		recorded = append(recorded, requested)
+	})
+	ctx = WithDeclared(ctx, declared)
+
+	assert.NoError(t, CheckExec(ctx, "/bin/sh"), "recording does not enforce")
+	assert.NoError(t, CheckNetwork(ctx, "example.com", "443"))
+	require.Len(t, recorded, 2)
+	assert.Equal(t, []string{"/bin/sh"}, recorded[0].Exec.Commands)
+	assert.Equal(t, []entities.NetworkRule{{Hosts: []string{"example.com"}, Ports: []string{"443"}}}, recorded[1].Network.Rules)
+}
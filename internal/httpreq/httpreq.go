@@ -0,0 +1,73 @@
+// Package httpreq resolves the parts of a ports.HTTPRequest that callers can
+// set in more than one way, so that host adapters and fakes send the same
+// request.
+package httpreq
+
+import (
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// MaxBodySize is the largest request body sent to the host, and the largest
+// body a fake accepts.
+const MaxBodySize = 10 * 1024 * 1024 // 10 MB
+
+// Header merges req.Headers and req.HeaderValues under canonical header
+// names. Values from Headers come first. It returns nil if there are none.
+func Header(req *ports.HTTPRequest) map[string][]string {
+	if len(req.Headers) == 0 && len(req.HeaderValues) == 0 {
+		return nil
+	}
+	header := make(map[string][]string, len(req.Headers)+len(req.HeaderValues))
+	for name, value := range req.Headers {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		header[name] = append(header[name], value)
+	}
+	for name, values := range req.HeaderValues {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+		header[name] = append(header[name], values...)
+	}
+	return header
+}
+
+// URL returns req.URL with req.Query appended to its query string.
+func URL(req *ports.HTTPRequest) (string, error) {
+	if len(req.Query) == 0 {
+		return req.URL, nil
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&"
+	}
+	u.RawQuery += req.Query.Encode()
+	return u.String(), nil
+}
+
+// Body returns req.Body, or the contents of req.BodyReader when Body is
+// empty. A body longer than limit bytes is an error.
+func Body(req *ports.HTTPRequest, limit int64) ([]byte, error) {
+	if req.BodyReader == nil {
+		if int64(len(req.Body)) > limit {
+			return nil, fmt.Errorf("request body exceeds %d bytes", limit)
+		}
+		return req.Body, nil
+	}
+	if len(req.Body) > 0 {
+		return nil, fmt.Errorf("request sets both Body and BodyReader")
+	}
+	body, err := io.ReadAll(io.LimitReader(req.BodyReader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("request body exceeds %d bytes", limit)
+	}
+	return body, nil
+}
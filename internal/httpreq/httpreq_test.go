@@ -0,0 +1,60 @@
+package httpreq
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	assert.Nil(t, Header(&ports.HTTPRequest{}))
+
+	header := Header(&ports.HTTPRequest{
+		Headers:      map[string]string{"accept": "application/json", "X-Token": "t"},
+		HeaderValues: map[string][]string{"Accept": {"text/plain"}, "cookie": {"a=1", "b=2"}},
+	})
+	assert.Equal(t, map[string][]string{
+		"Accept":  {"application/json", "text/plain"},
+		"X-Token": {"t"},
+		"Cookie":  {"a=1", "b=2"},
+	}, header)
+}
+
+func TestURL(t *testing.T) {
+	got, err := URL(&ports.HTTPRequest{URL: "https://example.com/search"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/search", got)
+
+	got, err = URL(&ports.HTTPRequest{
+		URL:   "https://example.com/search?lang=en",
+		Query: url.Values{"q": {"a b", "c&d"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/search?lang=en&q=a+b&q=c%26d", got)
+
+	_, err = URL(&ports.HTTPRequest{URL: "://bad", Query: url.Values{"q": {"x"}}})
+	assert.Error(t, err)
+}
+
+func TestBody(t *testing.T) {
+	body, err := Body(&ports.HTTPRequest{Body: []byte("inline")}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("inline"), body)
+
+	body, err = Body(&ports.HTTPRequest{BodyReader: strings.NewReader("streamed")}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("streamed"), body)
+
+	_, err = Body(&ports.HTTPRequest{BodyReader: strings.NewReader("much too long")}, 10)
+	assert.ErrorContains(t, err, "exceeds 10 bytes")
+
+	_, err = Body(&ports.HTTPRequest{Body: []byte("much too long")}, 10)
+	assert.ErrorContains(t, err, "exceeds 10 bytes")
+
+	_, err = Body(&ports.HTTPRequest{Body: []byte("a"), BodyReader: strings.NewReader("b")}, 10)
+	assert.ErrorContains(t, err, "both")
+}
@@ -0,0 +1,8 @@
+//go:build !wasip1
+
+package wasmcontext
+
+// pollHost reports no cancellation outside WASM, where there is no host to ask.
+func pollHost() bool {
+	return false
+}
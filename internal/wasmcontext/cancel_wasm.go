@@ -0,0 +1,17 @@
+//go:build wasip1
+
+package wasmcontext
+
+// Define the host function signature for cancellation polling.
+// It returns 1 when the host has canceled the current invocation.
+//
+//go:wasmimport reglet_host context_canceled
+//nolint:revive // intentional snake_case to match WASM import convention
+func host_context_canceled() uint32
+
+// pollHost asks the host whether the current invocation has been canceled.
+// It is only referenced from PollCancellation, so plugins that never poll do
+// not import context_canceled.
+func pollHost() bool {
+	return host_context_canceled() != 0
+}
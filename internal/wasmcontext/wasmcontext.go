@@ -17,6 +17,16 @@ type contextKey string
 // RequestIDKey is the context key for request ID.
 const RequestIDKey contextKey = "request_id"
 
+// cancelKey is the context key for the CancelFunc created by WireToContext.
+const cancelKey contextKey = "cancel"
+
+// cancelableKey is the context key marking an invocation whose host
+// implements the context_canceled import.
+const cancelableKey contextKey = "cancelable"
+
+// hostCanceled replaces pollHost when set, so tests can simulate the host.
+var hostCanceled func() bool
+
 // contextStore holds the current context for the plugin execution.
 // Since WASM is single-threaded, we can use a simple global variable.
 // The host sets this when calling into the plugin via SetCurrentContext.
@@ -126,6 +136,12 @@ func WireToContext(parent stdcontext.Context, wire entities.ContextWire) (stdcon
 		ctx = stdcontext.WithValue(ctx, RequestIDKey, wire.RequestID)
 	}
 
+	// Keep the cancel func so PollCancellation can cancel on the host's behalf
+	ctx = stdcontext.WithValue(ctx, cancelKey, cancel)
+	if wire.Cancelable {
+		ctx = stdcontext.WithValue(ctx, cancelableKey, true)
+	}
+
 	// If context is already Canceled, cancel immediately
 	if wire.Canceled {
 		cancel()
@@ -133,3 +149,30 @@ func WireToContext(parent stdcontext.Context, wire entities.ContextWire) (stdcon
 
 	return ctx, cancel
 }
+
+// PollCancellation asks the host whether the current invocation has been
+// canceled. If it has, the context created by WireToContext is canceled, so
+// ctx.Done() is closed and ContextToWire reports it to later host calls.
+// The host is only asked when the invocation's wire context was Cancelable.
+//
+// It returns ctx.Err() once the context is done, and nil otherwise.
+func PollCancellation(ctx stdcontext.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cancelable, _ := ctx.Value(cancelableKey).(bool); !cancelable {
+		return nil
+	}
+	poll := pollHost
+	if hostCanceled != nil {
+		poll = hostCanceled
+	}
+	if !poll() {
+		return nil
+	}
+	if cancel, ok := ctx.Value(cancelKey).(stdcontext.CancelFunc); ok {
+		cancel()
+		return ctx.Err()
+	}
+	return stdcontext.Canceled
+}
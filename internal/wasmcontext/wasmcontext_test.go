@@ -94,3 +94,36 @@ func TestWireToContext(t *testing.T) {
 	defer cancel()
 	assert.ErrorIs(t, ctx.Err(), context.Canceled)
 }
+
+func TestPollCancellation(t *testing.T) {
+	canceled := false
+	hostCanceled = func() bool { return canceled }
+	defer func() { hostCanceled = nil }()
+
+	ctx, cancel := WireToContext(context.Background(), entities.ContextWire{RequestID: "req-789", Cancelable: true})
+	defer cancel()
+	child := context.WithValue(ctx, contextKey("key"), "value")
+
+	assert.NoError(t, PollCancellation(child))
+	assert.False(t, ContextToWire(child).Canceled)
+
+	canceled = true
+	assert.ErrorIs(t, PollCancellation(child), context.Canceled)
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("polled cancellation must close ctx.Done()")
+	}
+	assert.True(t, ContextToWire(child).Canceled, "cancellation is propagated to host calls")
+
+	// Hosts that do not declare support for polling are never asked
+	ctx, cancel = WireToContext(context.Background(), entities.ContextWire{RequestID: "req-790"})
+	defer cancel()
+	assert.NoError(t, PollCancellation(ctx))
+	assert.NoError(t, PollCancellation(context.Background()))
+
+	hostCanceled = nil
+	ctx, cancel = WireToContext(context.Background(), entities.ContextWire{Cancelable: true})
+	defer cancel()
+	assert.NoError(t, PollCancellation(ctx), "native builds have no host to ask")
+}
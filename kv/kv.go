@@ -0,0 +1,120 @@
+// Package kv provides access to the host's persistent key-value store, so
+// plugins can remember state between runs.
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/infrastructure/wasm"
+)
+
+// Re-export types from ports for API compatibility
+type Entry = ports.KeyValueEntry
+
+// kvConfig holds the configuration for key-value operations.
+// This struct is unexported to enforce the functional options pattern.
+type kvConfig struct {
+	store ports.KeyValueStore
+	ttl   time.Duration // Entry lifetime for Set and CompareAndSwap (default: no expiry)
+}
+
+// defaultKVConfig returns the default configuration.
+func defaultKVConfig() kvConfig {
+	return kvConfig{
+		store: wasm.NewKVAdapter(),
+	}
+}
+
+// Option is a functional option for configuring key-value operations.
+// Use With* functions to create options.
+type Option func(*kvConfig)
+
+// WithStore sets the key-value store to use.
+// This is useful for injecting fakes during testing.
+func WithStore(s ports.KeyValueStore) Option {
+	return func(c *kvConfig) {
+		if s != nil {
+			c.store = s
+		}
+	}
+}
+
+// WithTTL sets how long an entry written by Set or CompareAndSwap lives.
+// A zero or negative duration is ignored (the entry does not expire).
+func WithTTL(d time.Duration) Option {
+	return func(c *kvConfig) {
+		if d > 0 {
+			c.ttl = d
+		}
+	}
+}
+
+// applyOptions applies functional options and returns the configuration.
+func applyOptions(opts ...Option) kvConfig {
+	cfg := defaultKVConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Get returns the entry stored under key, or nil if it does not exist.
+// Requires a "read" KeyValue rule covering key.
+//
+// Example:
+//
+//	entry, err := kv.Get(ctx, "certs/example.com")
+//	if err == nil && entry != nil && string(entry.Value) != fingerprint {
+//	    // certificate changed since the last run
+//	}
+func Get(ctx context.Context, key string, opts ...Option) (*Entry, error) {
+	cfg := applyOptions(opts...)
+	return cfg.store.Get(ctx, key)
+}
+
+// Set stores value under key and returns the new entry.
+// Requires a "write" KeyValue rule covering key.
+//
+// Options:
+//   - WithTTL(d): Expire the entry after d (default: never)
+//   - WithStore(s): Inject custom store (for testing)
+func Set(ctx context.Context, key string, value []byte, opts ...Option) (*Entry, error) {
+	cfg := applyOptions(opts...)
+	return cfg.store.Set(ctx, key, value, cfg.ttl)
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+// Requires a "write" KeyValue rule covering key.
+func Delete(ctx context.Context, key string, opts ...Option) error {
+	cfg := applyOptions(opts...)
+	return cfg.store.Delete(ctx, key)
+}
+
+// List returns the keys starting with prefix, in sorted order.
+// When the plugin declares its capabilities, keys not covered by a "read"
+// KeyValue rule are omitted.
+func List(ctx context.Context, prefix string, opts ...Option) ([]string, error) {
+	cfg := applyOptions(opts...)
+	return cfg.store.List(ctx, prefix)
+}
+
+// CompareAndSwap stores value under key only if the key's current revision
+// equals revision; pass 0 to create a key that must not exist yet.
+// It returns the stored entry and true on success, or the current entry and
+// false if another writer got there first.
+// Requires a "write" KeyValue rule covering key.
+//
+// Example:
+//
+//	entry, _ := kv.Get(ctx, "dns/example.com")
+//	var rev uint64
+//	if entry != nil {
+//	    rev = entry.Revision
+//	}
+//	_, swapped, err := kv.CompareAndSwap(ctx, "dns/example.com", rev, answers)
+func CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, opts ...Option) (*Entry, bool, error) {
+	cfg := applyOptions(opts...)
+	return cfg.store.CompareAndSwap(ctx, key, revision, value, cfg.ttl)
+}
@@ -0,0 +1,170 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKV_WithFakeStore(t *testing.T) {
+	ctx := context.Background()
+	store := &plugintest.FakeKeyValueStore{}
+
+	entry, err := Get(ctx, "certs/example.com", WithStore(store))
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	entry, err = Set(ctx, "certs/example.com", []byte("ab:cd"), WithStore(store))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), entry.Revision)
+	assert.Nil(t, entry.ExpiresAt)
+
+	entry, err = Get(ctx, "certs/example.com", WithStore(store))
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "ab:cd", string(entry.Value))
+
+	_, err = Set(ctx, "dns/example.com", []byte("1.2.3.4"), WithStore(store))
+	require.NoError(t, err)
+	keys, err := List(ctx, "certs/", WithStore(store))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"certs/example.com"}, keys)
+
+	require.NoError(t, Delete(ctx, "certs/example.com", WithStore(store)))
+	entry, err = Get(ctx, "certs/example.com", WithStore(store))
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestKV_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	store := &plugintest.FakeKeyValueStore{}
+
+	entry, swapped, err := CompareAndSwap(ctx, "dns/example.com", 0, []byte("v1"), WithStore(store))
+	require.NoError(t, err)
+	assert.True(t, swapped)
+	assert.Equal(t, uint64(1), entry.Revision)
+
+	// Creating an existing key fails and returns the current entry
+	entry, swapped, err = CompareAndSwap(ctx, "dns/example.com", 0, []byte("other"), WithStore(store))
+	require.NoError(t, err)
+	assert.False(t, swapped)
+	assert.Equal(t, "v1", string(entry.Value))
+
+	entry, swapped, err = CompareAndSwap(ctx, "dns/example.com", 1, []byte("v2"), WithStore(store))
+	require.NoError(t, err)
+	assert.True(t, swapped)
+	assert.Equal(t, uint64(2), entry.Revision)
+
+	// A missing key with a non-zero revision does not match
+	entry, swapped, err = CompareAndSwap(ctx, "dns/missing", 3, []byte("v"), WithStore(store))
+	require.NoError(t, err)
+	assert.False(t, swapped)
+	assert.Nil(t, entry)
+}
+
+func TestKV_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &plugintest.FakeKeyValueStore{Now: func() time.Time { return now }}
+
+	entry, err := Set(ctx, "k", []byte("v"), WithStore(store), WithTTL(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, entry.ExpiresAt)
+	assert.Equal(t, now.Add(time.Minute), *entry.ExpiresAt)
+
+	now = now.Add(2 * time.Minute)
+	entry, err = Get(ctx, "k", WithStore(store))
+	require.NoError(t, err)
+	assert.Nil(t, entry, "expired")
+
+	keys, err := List(ctx, "", WithStore(store))
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestKV_DeclaredRules(t *testing.T) {
+	store := &plugintest.FakeKeyValueStore{}
+	ctx := capability.WithDeclared(context.Background(), &entities.GrantSet{
+		KV: &entities.KeyValueCapability{
+			Rules: []entities.KeyValueRule{
+				{Operation: "read", Keys: []string{"shared/*"}},
+				{Operation: "read-write", Keys: []string{"certs/*"}},
+			},
+		},
+	})
+	_, _ = store.Set(context.Background(), "shared/a", []byte("1"), 0)
+	_, _ = store.Set(context.Background(), "secret/b", []byte("2"), 0)
+
+	_, err := Set(ctx, "certs/example.com", []byte("v"), WithStore(store))
+	assert.NoError(t, err)
+	_, err = Get(ctx, "shared/a", WithStore(store))
+	assert.NoError(t, err)
+
+	_, err = Set(ctx, "shared/a", []byte("v"), WithStore(store))
+	var capErr *errors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "kv:write", capErr.Required)
+
+	assert.Error(t, Delete(ctx, "shared/a", WithStore(store)))
+	_, _, err = CompareAndSwap(ctx, "secret/b", 1, []byte("v"), WithStore(store))
+	assert.Error(t, err)
+	_, err = Get(ctx, "secret/b", WithStore(store))
+	assert.Error(t, err)
+
+	keys, err := List(ctx, "", WithStore(store))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"certs/example.com", "shared/a"}, keys, "undeclared keys are hidden")
+}
+
+type driftInput struct {
+	Key string `json:"key"`
+}
+
+type driftOutput struct {
+	Revision uint64 `json:"revision"`
+}
+
+type driftService struct {
+	plugin.Service `name:"drift" desc:"Drift detection"`
+	Remember       plugin.Op[driftInput, driftOutput] `desc:"Remember a value" method:"RememberHandler"`
+}
+
+func (s *driftService) RememberHandler(ctx context.Context, in *driftInput) (*driftOutput, error) {
+	entry, err := Set(ctx, in.Key, []byte("seen"), WithStore(plugin.GetClient[*plugintest.FakeKeyValueStore](ctx)))
+	if err != nil {
+		return nil, err
+	}
+	return &driftOutput{Revision: entry.Revision}, nil
+}
+
+func TestKV_PluginDeclaredCapabilities(t *testing.T) {
+	def := plugin.DefinePlugin(plugin.PluginDef{
+		Name: "drift",
+		Capabilities: entities.GrantSet{
+			KV: &entities.KeyValueCapability{
+				Rules: []entities.KeyValueRule{{Operation: "write", Keys: []string{"drift/*"}}},
+			},
+		},
+	})
+	require.NoError(t, plugin.RegisterService(def, &driftService{}))
+	ctx := plugin.WithClient(context.Background(), &plugintest.FakeKeyValueStore{})
+
+	res, err := def.Check(ctx, []byte(`{"input":{"key":"drift/example.com"}}`))
+	require.NoError(t, err)
+	plugintest.AssertSuccess(t, res)
+
+	res, err = def.Check(ctx, []byte(`{"input":{"key":"other/example.com"}}`))
+	require.NoError(t, err)
+	require.Equal(t, entities.ResultStatusError, res.Status)
+	assert.Equal(t, "capability", res.Error.Type)
+	assert.Equal(t, "kv:write", res.Error.Code)
+}
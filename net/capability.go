@@ -0,0 +1,18 @@
+package sdknet
+
+import (
+	stderrors "errors"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+)
+
+// capabilityError returns the *errors.CapabilityError in err's chain, if any,
+// so checks report an undeclared host or port as a capability failure rather
+// than a network one.
+func capabilityError(err error) *errors.CapabilityError {
+	var capErr *errors.CapabilityError
+	if stderrors.As(err, &capErr) {
+		return capErr
+	}
+	return nil
+}
@@ -80,6 +80,10 @@ func RunDNSCheck(ctx context.Context, cfg config.Config, opts ...DNSCheckOption)
 	latency := time.Since(start)
 	metadata := entities.NewRunMetadata(start, time.Now())
 
+	if capErr := capabilityError(lookupErr); capErr != nil {
+		return entities.ResultError(capErr.ToErrorDetail()).WithMetadata(metadata), capErr
+	}
+
 	// Build result data
 	resultData := make(map[string]any)
 	resultData["query_time_ms"] = latency.Milliseconds()
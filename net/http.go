@@ -1,11 +1,16 @@
 package sdknet
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,16 +43,35 @@ func WithHTTPClient(c ports.HTTPClient) HTTPCheckOption {
 // Expected config fields:
 //   - url (string, required): Target URL
 //   - method (string, optional): HTTP method (default: GET)
-//   - headers (map[string]string, optional): Request headers
+//   - headers (map, optional): Request headers; a list value sends the header once per item
+//   - query (map, optional): Query parameters appended to the URL; a list value repeats the parameter
 //   - body (string, optional): Request body
 //   - timeout_ms (int, optional): Request timeout in milliseconds (default: 30000)
 //   - expected_status (int, optional): Expected HTTP status code for validation
 //   - follow_redirects (bool, optional): Whether to follow redirects (default: true)
-//   - max_redirects (int, optional): Maximum redirects to follow (default: 10)
+//   - max_redirects (int, optional): Maximum redirects to follow, at least 0 (default: 10)
+//   - tls_server_name (string, optional): Server name for SNI and certificate verification
+//   - tls_min_version (string, optional): Minimum TLS version: "1.0", "1.1", "1.2" or "1.3"
+//   - ca_cert (string, optional): PEM bundle of CA certificates to trust instead of the system roots
+//   - client_cert, client_key (string, optional): PEM client certificate and key for mutual TLS
+//   - insecure_skip_verify (bool, optional): Disable certificate verification (critical risk)
+//   - assertions (list, optional): Response assertions on status, headers, JSON body paths,
+//     body regex, response time and body SHA-256; see the package README
+//   - stream_body (bool, optional): Read the response body from the host in chunks, so bodies
+//     over the host's buffer limit can be hashed; excludes expected_body_contains and json and
+//     body assertions. Plugins that call RunHTTPCheck import the host's body functions (see
+//     WithStreamBody)
+//   - allow_truncated_body (bool, optional): Accept a body cut at the host's buffer limit
+//     instead of failing, and report "response_truncated"
 //
 // Returns a Result with:
-//   - Status: "success" if request succeeded and matches expectations, "failure" if status mismatch, "error" if request failed
-//   - Data: map containing "status_code", "headers", "body", "latency_ms", "body_truncated"
+//   - Status: "success" if request succeeded and matches expectations, "failure" if status mismatch
+//     or any assertion failed, "error" if request failed
+//   - Data: map containing "status_code", "headers", "body", "response_time_ms", "body_truncated",
+//     "redirects" (each hop's "url", "status_code" and "location") when redirects were followed,
+//     and "timings" ("dns_ms", "connect_ms", "tls_ms", "first_byte_ms", "total_ms") when the
+//     host measured them, and "assertions" with one entry per assertion ("index", "type",
+//     "passed" and, for failures, "message"), and "response_truncated" when the host cut the body
 //   - Error: structured error details if request failed
 //
 // RunHTTPCheck performs an HTTP request check.
@@ -57,6 +81,29 @@ func RunHTTPCheck(ctx context.Context, cfg config.Config, opts ...HTTPCheckOptio
 	if err != nil {
 		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("MISSING_URL")), nil
 	}
+	if parsedCfg.MaxRedirects < 0 {
+		msg := fmt.Sprintf("max_redirects must not be negative, got %d", parsedCfg.MaxRedirects)
+		return entities.ResultError(entities.NewErrorDetail("config", msg).WithCode("INVALID_MAX_REDIRECTS")), nil
+	}
+	parsedCfg.Request.HeaderValues, err = parseMultiValues(cfg, "headers")
+	if err != nil {
+		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("INVALID_HEADERS")), nil
+	}
+	parsedCfg.Request.Query, err = parseMultiValues(cfg, "query")
+	if err != nil {
+		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("INVALID_QUERY")), nil
+	}
+	parsedCfg.Request.TLS, err = parseHTTPTLSConfig(cfg)
+	if err != nil {
+		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("INVALID_TLS_CONFIG")), nil
+	}
+	parsedCfg.Assertions, err = parseHTTPAssertions(cfg)
+	if err != nil {
+		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("INVALID_ASSERTION")), nil
+	}
+	if err := checkStreamBody(parsedCfg); err != nil {
+		return entities.ResultError(entities.NewErrorDetail("config", err.Error()).WithCode("STREAM_BODY_CONFLICT")), nil
+	}
 
 	// Configure check dependencies
 	checkCfg := httpCheckConfig{}
@@ -70,6 +117,9 @@ func RunHTTPCheck(ctx context.Context, cfg config.Config, opts ...HTTPCheckOptio
 			WithHTTPTimeout(time.Duration(parsedCfg.TimeoutMs) * time.Millisecond),
 			WithMaxRedirects(parsedCfg.MaxRedirects),
 		}
+		if parsedCfg.Request.StreamBody {
+			transportOpts = append(transportOpts, WithStreamBody())
+		}
 		checkCfg.client = NewTransport(transportOpts...)
 	}
 
@@ -82,16 +132,26 @@ func RunHTTPCheck(ctx context.Context, cfg config.Config, opts ...HTTPCheckOptio
 	metadata := entities.NewRunMetadata(start, time.Now())
 
 	if err != nil {
+		if capErr := capabilityError(err); capErr != nil {
+			return entities.ResultError(capErr.ToErrorDetail()).WithMetadata(metadata), capErr
+		}
 		errDetail := entities.NewErrorDetail("network", err.Error()).WithCode("REQUEST_FAILED")
 		return entities.ResultError(errDetail).WithMetadata(metadata), errDetail
 	}
 
-	return buildHTTPResult(resp, latency, parsedCfg, metadata), nil
+	body, err := digestHTTPBody(resp, parsedCfg.BodyPreviewLength)
+	if err != nil {
+		errDetail := entities.NewErrorDetail("network", err.Error()).WithCode("BODY_READ_FAILED")
+		return entities.ResultError(errDetail).WithMetadata(metadata), errDetail
+	}
+
+	return buildHTTPResult(resp, body, latency, parsedCfg, metadata), nil
 }
 
 type parsedHTTPConfig struct {
 	ExpectedBodyContains string
 	Request              ports.HTTPRequest
+	Assertions           []*httpAssertion
 	TimeoutMs            int
 	BodyPreviewLength    int
 	ExpectedStatus       int
@@ -121,15 +181,15 @@ func parseHTTPCheckConfig(cfg config.Config) (*parsedHTTPConfig, error) {
 		pc.MaxRedirects = 0
 	}
 
-	// Parse headers
-	headers := parseHeaders(cfg)
 	body := config.GetStringDefault(cfg, "body", "")
 
 	pc.Request = ports.HTTPRequest{
-		Method:  config.GetStringDefault(cfg, "method", "GET"),
-		URL:     url,
-		Headers: headers,
-		Timeout: pc.TimeoutMs,
+		Method:             config.GetStringDefault(cfg, "method", "GET"),
+		URL:                url,
+		Timeout:            pc.TimeoutMs,
+		MaxRedirects:       &pc.MaxRedirects,
+		StreamBody:         config.GetBoolDefault(cfg, "stream_body", false),
+		AllowTruncatedBody: config.GetBoolDefault(cfg, "allow_truncated_body", false),
 	}
 	if body != "" {
 		pc.Request.Body = []byte(body)
@@ -138,20 +198,112 @@ func parseHTTPCheckConfig(cfg config.Config) (*parsedHTTPConfig, error) {
 	return pc, nil
 }
 
-func parseHeaders(cfg config.Config) map[string]string {
-	var headers map[string]string
-	if headersRaw, ok := cfg["headers"].(map[string]interface{}); ok {
-		headers = make(map[string]string)
-		for k, v := range headersRaw {
-			if vStr, ok := v.(string); ok {
-				headers[k] = vStr
+// checkStreamBody rejects validations that need the whole body in memory
+// when the body is streamed.
+func checkStreamBody(pc *parsedHTTPConfig) error {
+	if !pc.Request.StreamBody {
+		return nil
+	}
+	if pc.ExpectedBodyContains != "" {
+		return fmt.Errorf("expected_body_contains needs the whole body and cannot be used with stream_body")
+	}
+	for i, a := range pc.Assertions {
+		if a.Type == assertJSON || a.Type == assertBody {
+			return fmt.Errorf("assertions[%d]: %s assertions need the whole body and cannot be used with stream_body", i, a.Type)
+		}
+	}
+	return nil
+}
+
+// parseHTTPTLSConfig returns the TLS settings in cfg, or nil if there are none.
+func parseHTTPTLSConfig(cfg config.Config) (*ports.TLSConfig, error) {
+	tlsCfg := &ports.TLSConfig{
+		ServerName:         config.GetStringDefault(cfg, "tls_server_name", ""),
+		RootCAs:            getPEM(cfg, "ca_cert"),
+		ClientCert:         getPEM(cfg, "client_cert"),
+		ClientKey:          getPEM(cfg, "client_key"),
+		InsecureSkipVerify: config.GetBoolDefault(cfg, "insecure_skip_verify", false),
+	}
+	if v, ok := config.GetString(cfg, "tls_min_version"); ok {
+		version, known := tlsVersions[v]
+		if !known {
+			return nil, fmt.Errorf("unsupported tls_min_version %q: use 1.0, 1.1, 1.2 or 1.3", v)
+		}
+		tlsCfg.MinVersion = version
+	}
+	if len(tlsCfg.ClientCert) > 0 != (len(tlsCfg.ClientKey) > 0) {
+		return nil, fmt.Errorf("client_cert and client_key must be set together")
+	}
+
+	if tlsCfg.ServerName == "" && tlsCfg.MinVersion == 0 && !tlsCfg.InsecureSkipVerify &&
+		len(tlsCfg.RootCAs) == 0 && len(tlsCfg.ClientCert) == 0 {
+		return nil, nil
+	}
+	return tlsCfg, nil
+}
+
+// getPEM returns the PEM string at key as bytes, or nil if it is unset or empty.
+func getPEM(cfg config.Config, key string) []byte {
+	if s, ok := config.GetString(cfg, key); ok && s != "" {
+		return []byte(s)
+	}
+	return nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseMultiValues reads the map at key in cfg, such as "headers" or
+// "query". Each entry is a scalar or a list of scalars; numbers and booleans
+// are formatted as text. It returns nil if key is not set.
+func parseMultiValues(cfg config.Config, key string) (map[string][]string, error) {
+	raw, ok := cfg[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	entries, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a map", key)
+	}
+	values := make(map[string][]string, len(entries))
+	for name, v := range entries {
+		list, isList := v.([]any)
+		if !isList {
+			list = []any{v}
+		}
+		for _, item := range list {
+			s, ok := scalarString(item)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s must be a string, number, boolean or list of them", key, name)
 			}
+			values[name] = append(values[name], s)
 		}
 	}
-	return headers
+	return values, nil
 }
 
-func buildHTTPResult(resp *ports.HTTPResponse, latency time.Duration, cfg *parsedHTTPConfig, metadata *entities.RunMetadata) entities.Result {
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func buildHTTPResult(resp *ports.HTTPResponse, body *httpBodyDigest, latency time.Duration, cfg *parsedHTTPConfig, metadata *entities.RunMetadata) entities.Result {
 	resultData := map[string]any{
 		"status_code":      resp.StatusCode,
 		"response_time_ms": latency.Milliseconds(),
@@ -162,8 +314,42 @@ func buildHTTPResult(resp *ports.HTTPResponse, latency time.Duration, cfg *parse
 		resultData["headers"] = resp.Headers
 	}
 
-	if len(resp.Body) > 0 {
-		addBodyInfo(resultData, resp.Body, cfg.BodyPreviewLength)
+	if body.size > 0 {
+		resultData["body_sha256"] = body.sha256
+		resultData["body_size"] = body.size
+		resultData["body_length"] = body.size // Compat alias
+		resultData["body"] = string(body.preview.buf)
+		resultData["body_truncated"] = body.preview.truncated
+	}
+	if resp.BodyTruncated {
+		resultData["response_truncated"] = true
+	}
+
+	if len(resp.Redirects) > 0 {
+		redirects := make([]map[string]any, 0, len(resp.Redirects))
+		for _, hop := range resp.Redirects {
+			redirects = append(redirects, map[string]any{
+				"url":         hop.URL,
+				"status_code": hop.StatusCode,
+				"location":    hop.Location,
+			})
+		}
+		resultData["redirects"] = redirects
+	}
+
+	if t := resp.Timings; t != nil {
+		resultData["timings"] = map[string]any{
+			"dns_ms":        t.DNS.Milliseconds(),
+			"connect_ms":    t.Connect.Milliseconds(),
+			"tls_ms":        t.TLSHandshake.Milliseconds(),
+			"first_byte_ms": t.FirstByte.Milliseconds(),
+			"total_ms":      t.Total.Milliseconds(),
+		}
+	}
+
+	var assertionFailures []string
+	if len(cfg.Assertions) > 0 {
+		resultData["assertions"], assertionFailures = evaluateHTTPAssertions(cfg.Assertions, resp, body.sha256, latency)
 	}
 
 	// Validations
@@ -182,32 +368,74 @@ func buildHTTPResult(resp *ports.HTTPResponse, latency time.Duration, cfg *parse
 		}
 	}
 
+	if len(assertionFailures) > 0 {
+		message := fmt.Sprintf("%d of %d HTTP assertions failed: %s",
+			len(assertionFailures), len(cfg.Assertions), strings.Join(assertionFailures, "; "))
+		return entities.ResultFailure(message, resultData).WithMetadata(metadata)
+	}
+
 	message := fmt.Sprintf("HTTP %s request successful: %d", cfg.Request.Method, resp.StatusCode)
 	return entities.ResultSuccess(message, resultData).WithMetadata(metadata)
 }
 
-func addBodyInfo(data map[string]any, body []byte, previewLen int) {
-	hash := sha256.Sum256(body)
-	data["body_sha256"] = hex.EncodeToString(hash[:])
-	data["body_size"] = len(body)
-	data["body_length"] = len(body) // Compat alias
+// httpBodyDigest summarizes a response body, so that a streamed body never
+// has to be held in memory.
+type httpBodyDigest struct {
+	sha256  string
+	preview bodyPreview
+	size    int
+}
+
+// digestHTTPBody hashes and measures resp's body, keeping a preview of its
+// first previewLen bytes, or all of it if previewLen is negative. A streamed
+// body is read to the end and closed.
+func digestHTTPBody(resp *ports.HTTPResponse, previewLen int) (*httpBodyDigest, error) {
+	var r io.Reader = bytes.NewReader(resp.Body)
+	if resp.BodyReader != nil {
+		defer resp.BodyReader.Close()
+		r = resp.BodyReader
+	}
 
-	preview := body
-	truncated := false
-	if previewLen >= 0 && len(preview) > previewLen {
-		preview = preview[:previewLen]
-		truncated = true
+	hash := sha256.New()
+	digest := &httpBodyDigest{preview: bodyPreview{limit: previewLen}}
+	n, err := io.Copy(io.MultiWriter(hash, &digest.preview), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
-	data["body"] = string(preview)
-	data["body_truncated"] = truncated
+	digest.size = int(n)
+	digest.sha256 = hex.EncodeToString(hash.Sum(nil))
+	return digest, nil
+}
+
+// bodyPreview keeps the first limit bytes written to it, or all of them if
+// limit is negative.
+type bodyPreview struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (p *bodyPreview) Write(data []byte) (int, error) {
+	keep := data
+	if p.limit >= 0 && len(p.buf)+len(keep) > p.limit {
+		keep = keep[:p.limit-len(p.buf)]
+		p.truncated = true
+	}
+	p.buf = append(p.buf, keep...)
+	return len(data), nil
 }
 
 // transportConfig holds the configuration for a WasmTransport.
 // This struct is unexported to enforce the functional options pattern.
 type transportConfig struct {
 	tlsConfig    *tls.Config   // Optional TLS configuration
+	rootCAs      []byte        // PEM CA bundle (default: system roots)
+	clientCert   []byte        // PEM client certificate chain for mutual TLS
+	clientKey    []byte        // PEM client private key for mutual TLS
 	timeout      time.Duration // HTTP request timeout (default: 30s)
 	maxRedirects int           // Maximum number of redirects to follow (default: 10)
+	// newAdapter creates the host adapter (default: wasm.NewHTTPAdapter).
+	newAdapter func(time.Duration) *wasm.HTTPAdapter
 }
 
 // defaultTransportConfig returns secure defaults for HTTP transport.
@@ -217,6 +445,7 @@ func defaultTransportConfig() transportConfig {
 		timeout:      30 * time.Second,
 		maxRedirects: 10,
 		tlsConfig:    nil, // Use system defaults
+		newAdapter:   wasm.NewHTTPAdapter,
 	}
 }
 
@@ -248,18 +477,56 @@ func WithMaxRedirects(n int) TransportOption {
 
 // WithTLSConfig sets a custom TLS configuration.
 // If nil is passed, the system default TLS configuration is used.
+//
+// The host makes the connection, so only the settings that can be sent to it
+// are honored: MinVersion, ServerName, InsecureSkipVerify and the first entry
+// of Certificates as the client certificate. RootCAs cannot be read back from
+// an x509.CertPool; use WithRootCAs instead.
 func WithTLSConfig(cfg *tls.Config) TransportOption {
 	return func(c *transportConfig) {
 		c.tlsConfig = cfg
 	}
 }
 
+// WithRootCAs trusts the PEM-encoded CA certificates in pem instead of the
+// system roots, e.g. for servers with certificates from a private CA.
+func WithRootCAs(pem []byte) TransportOption {
+	return func(c *transportConfig) {
+		c.rootCAs = pem
+	}
+}
+
+// WithClientCertificate presents the PEM-encoded certificate chain and private
+// key to servers that require mutual TLS. It takes precedence over
+// Certificates in WithTLSConfig.
+func WithClientCertificate(certPEM, keyPEM []byte) TransportOption {
+	return func(c *transportConfig) {
+		c.clientCert = certPEM
+		c.clientKey = keyPEM
+	}
+}
+
+// WithStreamBody lets requests that set StreamBody read the response body
+// from the host in chunks, so bodies over the host's buffer limit can be read.
+// The plugin then needs a host with the http_body_read and http_body_close
+// functions. Without this option the host buffers every body, and StreamBody
+// requests get it as BodyReader.
+func WithStreamBody() TransportOption {
+	return func(c *transportConfig) {
+		// Only this option refers to the streaming adapter, so plugins that
+		// never stream do not import the body host functions.
+		c.newAdapter = wasm.NewStreamingHTTPAdapter
+	}
+}
+
 // NewTransport creates a new HTTP client with the given options.
 // Without any options, secure defaults are applied:
 //   - timeout: 30 seconds
 //   - maxRedirects: 10
 //   - tlsConfig: system defaults
 //
+// Requests that set their own MaxRedirects or TLS override these options.
+//
 // Example:
 //
 //	// Use defaults
@@ -275,6 +542,42 @@ func NewTransport(opts ...TransportOption) ports.HTTPClient {
 	for _, opt := range opts {
 		opt(&cfg)
 	}
-	// Note: maxRedirects and tlsConfig are currently ignored by the underlying WASM adapter
-	return wasm.NewHTTPAdapter(cfg.timeout)
+	adapter := cfg.newAdapter(cfg.timeout)
+	adapter.DefaultMaxRedirects = &cfg.maxRedirects
+	adapter.DefaultTLS = cfg.tlsSettings()
+	return adapter
+}
+
+// tlsSettings returns the TLS settings to send to the host, or nil for system defaults.
+func (c *transportConfig) tlsSettings() *ports.TLSConfig {
+	if c.tlsConfig == nil && c.rootCAs == nil && c.clientCert == nil && c.clientKey == nil {
+		return nil
+	}
+	out := &ports.TLSConfig{
+		RootCAs:    c.rootCAs,
+		ClientCert: c.clientCert,
+		ClientKey:  c.clientKey,
+	}
+	if c.tlsConfig != nil {
+		out.MinVersion = c.tlsConfig.MinVersion
+		out.ServerName = c.tlsConfig.ServerName
+		out.InsecureSkipVerify = c.tlsConfig.InsecureSkipVerify
+		if out.ClientCert == nil && len(c.tlsConfig.Certificates) > 0 {
+			// A key that cannot be encoded is left empty, so requests
+			// fail with an invalid client certificate error.
+			out.ClientCert, out.ClientKey = encodeCertificate(c.tlsConfig.Certificates[0])
+		}
+	}
+	return out
+}
+
+// encodeCertificate returns the PEM encoding of cert's chain and private key.
+func encodeCertificate(cert tls.Certificate) (certPEM, keyPEM []byte) {
+	for _, der := range cert.Certificate {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	if der, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey); err == nil {
+		keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+	return certPEM, keyPEM
 }
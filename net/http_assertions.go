@@ -0,0 +1,434 @@
+package sdknet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/config"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// Assertion types accepted in the "assertions" list of an HTTP check.
+const (
+	assertStatus       = "status"
+	assertHeader       = "header"
+	assertJSON         = "json"
+	assertBody         = "body"
+	assertResponseTime = "response_time"
+	assertBodySHA256   = "body_sha256"
+)
+
+// httpAssertion is one entry of the "assertions" list of an HTTP check:
+//
+//	{type: status, in: [200, 204]}               status in a set
+//	{type: status, range: "200-299"}             status in a range
+//	{type: header, name: ETag, exists: true}     header presence (or absence)
+//	{type: header, name: Content-Type, matches: "^application/json"}
+//	{type: json, path: "$.items[0].id", equals: 42}
+//	{type: json, path: "$.latency", lt: 250}     gt, gte, lt and lte compare numbers
+//	{type: body, matches: "(?i)healthy"}
+//	{type: response_time, max_ms: 500}
+//	{type: body_sha256, equals: "9f86d0..."}
+//
+// Every operator set on an assertion must hold for it to pass.
+type httpAssertion struct {
+	Type    string          `json:"type"`
+	Name    string          `json:"name,omitempty"`
+	Path    string          `json:"path,omitempty"`
+	Range   string          `json:"range,omitempty"`
+	Matches string          `json:"matches,omitempty"`
+	Equals  json.RawMessage `json:"equals,omitempty"`
+	In      []int           `json:"in,omitempty"`
+	Exists  *bool           `json:"exists,omitempty"`
+	GT      *float64        `json:"gt,omitempty"`
+	GTE     *float64        `json:"gte,omitempty"`
+	LT      *float64        `json:"lt,omitempty"`
+	LTE     *float64        `json:"lte,omitempty"`
+	MaxMs   *int64          `json:"max_ms,omitempty"`
+
+	re                 *regexp.Regexp
+	path               []jsonPathStep
+	equals             any
+	statusLo, statusHi int
+}
+
+// parseHTTPAssertions decodes and validates the "assertions" list in cfg, so
+// that mistakes are reported before any request is made.
+func parseHTTPAssertions(cfg config.Config) ([]*httpAssertion, error) {
+	raw, ok := cfg["assertions"]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("assertions: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var assertions []*httpAssertion
+	if err := dec.Decode(&assertions); err != nil {
+		return nil, fmt.Errorf("assertions: must be a list of assertions: %w", err)
+	}
+	for i, a := range assertions {
+		if err := a.compile(); err != nil {
+			return nil, fmt.Errorf("assertions[%d]: %w", i, err)
+		}
+	}
+	return assertions, nil
+}
+
+func (a *httpAssertion) compile() error {
+	if a.Matches != "" {
+		re, err := regexp.Compile(a.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid matches pattern: %w", err)
+		}
+		a.re = re
+	}
+	if a.Equals != nil {
+		if err := json.Unmarshal(a.Equals, &a.equals); err != nil {
+			return fmt.Errorf("invalid equals value: %w", err)
+		}
+	}
+
+	numeric := a.GT != nil || a.GTE != nil || a.LT != nil || a.LTE != nil
+	switch a.Type {
+	case assertStatus:
+		if a.Range != "" {
+			lo, hi, ok := strings.Cut(a.Range, "-")
+			var errLo, errHi error
+			a.statusLo, errLo = strconv.Atoi(strings.TrimSpace(lo))
+			a.statusHi, errHi = strconv.Atoi(strings.TrimSpace(hi))
+			if !ok || errLo != nil || errHi != nil || a.statusLo > a.statusHi {
+				return fmt.Errorf("status range %q must look like \"200-299\"", a.Range)
+			}
+		}
+		if _, ok := a.equals.(float64); a.equals != nil && !ok {
+			return fmt.Errorf("status equals must be a number")
+		}
+		return a.require(len(a.In) > 0 || a.Range != "" || a.equals != nil, "in, range or equals")
+	case assertHeader:
+		if a.Name == "" {
+			return fmt.Errorf("header assertion requires name")
+		}
+		if _, ok := a.equals.(string); a.equals != nil && !ok {
+			return fmt.Errorf("header equals must be a string")
+		}
+		return a.require(a.Exists != nil || a.equals != nil || a.re != nil, "exists, equals or matches")
+	case assertJSON:
+		path, err := parseJSONPath(a.Path)
+		if err != nil {
+			return err
+		}
+		a.path = path
+		return a.require(a.Exists != nil || a.Equals != nil || a.re != nil || numeric, "exists, equals, matches, gt, gte, lt or lte")
+	case assertBody:
+		return a.require(a.re != nil, "matches")
+	case assertResponseTime:
+		return a.require(a.MaxMs != nil, "max_ms")
+	case assertBodySHA256:
+		if s, ok := a.equals.(string); !ok || len(s) != sha256.Size*2 {
+			return fmt.Errorf("body_sha256 equals must be a hex SHA-256 digest")
+		}
+		return nil
+	case "":
+		return fmt.Errorf("assertion type is required")
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+}
+
+func (a *httpAssertion) require(ok bool, operators string) error {
+	if !ok {
+		return fmt.Errorf("%s assertion requires %s", a.Type, operators)
+	}
+	return nil
+}
+
+// assertionContext holds the response being asserted on.
+type assertionContext struct {
+	resp       *ports.HTTPResponse
+	bodySHA256 string
+	latency    time.Duration
+
+	json    any
+	jsonErr error
+	parsed  bool
+}
+
+// body returns the response body decoded as JSON, decoding it only once.
+func (c *assertionContext) body() (any, error) {
+	if !c.parsed {
+		c.parsed = true
+		c.jsonErr = json.Unmarshal(c.resp.Body, &c.json)
+	}
+	return c.json, c.jsonErr
+}
+
+// evaluateHTTPAssertions checks every assertion against resp, whose body
+// hashes to bodySHA256, and returns one result per assertion for
+// Result.Data, with the messages of the failed ones.
+func evaluateHTTPAssertions(assertions []*httpAssertion, resp *ports.HTTPResponse, bodySHA256 string, latency time.Duration) ([]map[string]any, []string) {
+	ctx := &assertionContext{resp: resp, bodySHA256: bodySHA256, latency: latency}
+	if resp.Timings != nil && resp.Timings.Total > 0 {
+		ctx.latency = resp.Timings.Total
+	}
+
+	results := make([]map[string]any, 0, len(assertions))
+	var failures []string
+	for i, a := range assertions {
+		result := map[string]any{"index": i, "type": a.Type}
+		switch a.Type {
+		case assertHeader:
+			result["name"] = a.Name
+		case assertJSON:
+			result["path"] = a.Path
+		}
+
+		msg := a.evaluate(ctx)
+		result["passed"] = msg == ""
+		if msg != "" {
+			msg = a.subject() + ": " + msg
+			result["message"] = msg
+			failures = append(failures, msg)
+		}
+		results = append(results, result)
+	}
+	return results, failures
+}
+
+func (a *httpAssertion) subject() string {
+	switch a.Type {
+	case assertHeader:
+		return "header " + a.Name
+	case assertJSON:
+		return "json " + a.Path
+	default:
+		return a.Type
+	}
+}
+
+// evaluate returns why the assertion fails, or "" if it holds.
+func (a *httpAssertion) evaluate(c *assertionContext) string {
+	switch a.Type {
+	case assertStatus:
+		return a.evaluateStatus(c.resp.StatusCode)
+	case assertHeader:
+		return a.evaluateHeader(c.resp.Headers)
+	case assertJSON:
+		doc, err := c.body()
+		if err != nil {
+			return "body is not valid JSON"
+		}
+		value, found := lookupJSONPath(doc, a.path)
+		return a.evaluateValue(value, found)
+	case assertBody:
+		if !a.re.Match(c.resp.Body) {
+			return fmt.Sprintf("body does not match %q", a.Matches)
+		}
+	case assertResponseTime:
+		if ms := c.latency.Milliseconds(); ms > *a.MaxMs {
+			return fmt.Sprintf("took %dms, more than %dms", ms, *a.MaxMs)
+		}
+	case assertBodySHA256:
+		if got := c.bodySHA256; !strings.EqualFold(got, a.equals.(string)) {
+			return fmt.Sprintf("expected %s, got %s", a.equals, got)
+		}
+	}
+	return ""
+}
+
+func (a *httpAssertion) evaluateStatus(status int) string {
+	if len(a.In) > 0 {
+		found := false
+		for _, s := range a.In {
+			found = found || s == status
+		}
+		if !found {
+			return fmt.Sprintf("expected one of %v, got %d", a.In, status)
+		}
+	}
+	if a.Range != "" && (status < a.statusLo || status > a.statusHi) {
+		return fmt.Sprintf("expected %s, got %d", a.Range, status)
+	}
+	if a.equals != nil && float64(status) != a.equals.(float64) {
+		return fmt.Sprintf("expected %v, got %d", a.equals, status)
+	}
+	return ""
+}
+
+func (a *httpAssertion) evaluateHeader(headers map[string][]string) string {
+	var values []string
+	found := false
+	for k, v := range headers {
+		if strings.EqualFold(k, a.Name) {
+			values, found = append(values, v...), true
+		}
+	}
+	if a.Exists != nil && found != *a.Exists {
+		if found {
+			return "expected header to be absent"
+		}
+		return "expected header to be present"
+	}
+	if !found {
+		if a.equals != nil || a.re != nil {
+			return "header is missing"
+		}
+		return ""
+	}
+	if a.equals != nil && !anyString(values, func(v string) bool { return v == a.equals }) {
+		return fmt.Sprintf("expected %q, got %q", a.equals, values)
+	}
+	if a.re != nil && !anyString(values, a.re.MatchString) {
+		return fmt.Sprintf("%q does not match %q", values, a.Matches)
+	}
+	return ""
+}
+
+// evaluateValue checks a value found at a JSON path.
+func (a *httpAssertion) evaluateValue(value any, found bool) string {
+	if a.Exists != nil && found != *a.Exists {
+		if found {
+			return "expected path to be absent"
+		}
+		return "expected path to exist"
+	}
+	if !found {
+		if a.Equals != nil || a.re != nil || a.GT != nil || a.GTE != nil || a.LT != nil || a.LTE != nil {
+			return "path not found"
+		}
+		return ""
+	}
+
+	if a.Equals != nil && !reflect.DeepEqual(value, a.equals) {
+		return fmt.Sprintf("expected %s, got %s", a.Equals, jsonString(value))
+	}
+	if a.re != nil {
+		s, ok := value.(string)
+		if !ok {
+			s = jsonString(value)
+		}
+		if !a.re.MatchString(s) {
+			return fmt.Sprintf("%s does not match %q", jsonString(value), a.Matches)
+		}
+	}
+
+	if a.GT == nil && a.GTE == nil && a.LT == nil && a.LTE == nil {
+		return ""
+	}
+	n, ok := value.(float64)
+	if !ok {
+		return fmt.Sprintf("expected a number, got %s", jsonString(value))
+	}
+	for _, cmp := range []struct {
+		bound *float64
+		op    string
+		ok    func(n, bound float64) bool
+	}{
+		{a.GT, ">", func(n, b float64) bool { return n > b }},
+		{a.GTE, ">=", func(n, b float64) bool { return n >= b }},
+		{a.LT, "<", func(n, b float64) bool { return n < b }},
+		{a.LTE, "<=", func(n, b float64) bool { return n <= b }},
+	} {
+		if cmp.bound != nil && !cmp.ok(n, *cmp.bound) {
+			return fmt.Sprintf("expected %s %v, got %v", cmp.op, *cmp.bound, n)
+		}
+	}
+	return ""
+}
+
+func anyString(values []string, match func(string) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// jsonPathStep is one step of a JSON path: an object key, or an array index
+// when index is not negative.
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+// parseJSONPath parses the JSONPath subset used by json assertions: "$"
+// followed by ".key", "['key']" or "[index]" steps, e.g. "$.items[0]['id']".
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with \"$\"", path)
+	}
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			key := rest[1:end]
+			if key == "" {
+				return nil, fmt.Errorf("json path %q has an empty key", path)
+			}
+			steps = append(steps, jsonPathStep{key: key, index: -1})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q has an unclosed \"[\"", path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1], index: -1})
+			} else if i, err := strconv.Atoi(inner); err == nil && i >= 0 {
+				steps = append(steps, jsonPathStep{index: i})
+			} else {
+				return nil, fmt.Errorf("json path %q: %q is not a quoted key or array index", path, inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("json path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+// lookupJSONPath returns the value at path in doc and whether it exists.
+func lookupJSONPath(doc any, path []jsonPathStep) (any, bool) {
+	for _, step := range path {
+		if step.index < 0 {
+			obj, ok := doc.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = obj[step.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		arr, ok := doc.([]any)
+		if !ok || step.index >= len(arr) {
+			return nil, false
+		}
+		doc = arr[step.index]
+	}
+	return doc, true
+}
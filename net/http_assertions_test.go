@@ -0,0 +1,179 @@
+package sdknet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/config"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const healthBody = `{"status":"ok","checks":[{"name":"db","latency_ms":12},{"name":"cache","latency_ms":340}],"version":"1.4.2"}`
+
+func healthResponse() *ports.HTTPResponse {
+	return &ports.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:       []byte(healthBody),
+		Timings:    &ports.HTTPTimings{Total: 120 * time.Millisecond},
+	}
+}
+
+func runAssertions(t *testing.T, assertions ...map[string]any) entities.Result {
+	t.Helper()
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.Anything).Return(healthResponse(), nil)
+
+	list := make([]any, len(assertions))
+	for i, a := range assertions {
+		list[i] = a
+	}
+	result, err := RunHTTPCheck(context.Background(), config.Config{
+		"url":        "https://example.com/health",
+		"assertions": list,
+	}, WithHTTPClient(mockClient))
+	require.NoError(t, err)
+	return result
+}
+
+func TestRunHTTPCheck_Assertions_AllPass(t *testing.T) {
+	sum := sha256.Sum256([]byte(healthBody))
+
+	result := runAssertions(t,
+		map[string]any{"type": "status", "in": []any{200, 204}},
+		map[string]any{"type": "status", "range": "200-299"},
+		map[string]any{"type": "header", "name": "content-type", "matches": "^application/json"},
+		map[string]any{"type": "header", "name": "X-Debug", "exists": false},
+		map[string]any{"type": "json", "path": "$.status", "equals": "ok"},
+		map[string]any{"type": "json", "path": "$.checks[0]['name']", "equals": "db"},
+		map[string]any{"type": "json", "path": "$.checks[1].latency_ms", "gte": 100, "lt": 500},
+		map[string]any{"type": "json", "path": "$.version", "matches": `^1\.\d+\.\d+$`},
+		map[string]any{"type": "json", "path": "$.maintenance", "exists": false},
+		map[string]any{"type": "body", "matches": `"status":\s*"ok"`},
+		map[string]any{"type": "response_time", "max_ms": 200},
+		map[string]any{"type": "body_sha256", "equals": hex.EncodeToString(sum[:])},
+	)
+
+	assert.True(t, result.IsSuccess(), result.Message)
+	results := result.Data["assertions"].([]map[string]any)
+	require.Len(t, results, 12)
+	for _, r := range results {
+		assert.True(t, r["passed"].(bool), r)
+	}
+	assert.Equal(t, "$.status", results[4]["path"])
+}
+
+func TestRunHTTPCheck_Assertions_ReportsEveryFailure(t *testing.T) {
+	result := runAssertions(t,
+		map[string]any{"type": "status", "in": []any{201, 204}},
+		map[string]any{"type": "header", "name": "ETag", "exists": true},
+		map[string]any{"type": "json", "path": "$.status", "equals": "ok"},
+		map[string]any{"type": "json", "path": "$.checks[1].latency_ms", "lt": 250},
+		map[string]any{"type": "json", "path": "$.checks[5].name", "exists": true},
+		map[string]any{"type": "response_time", "max_ms": 100},
+		map[string]any{"type": "body_sha256", "equals": "0000000000000000000000000000000000000000000000000000000000000000"},
+	)
+
+	assert.True(t, result.IsFailure())
+	assert.Contains(t, result.Message, "6 of 7 HTTP assertions failed")
+
+	results := result.Data["assertions"].([]map[string]any)
+	require.Len(t, results, 7)
+	var messages []string
+	for _, r := range results {
+		if !r["passed"].(bool) {
+			messages = append(messages, r["message"].(string))
+		}
+	}
+	assert.Equal(t, []string{
+		"status: expected one of [201 204], got 200",
+		"header ETag: expected header to be present",
+		"json $.checks[1].latency_ms: expected < 250, got 340",
+		"json $.checks[5].name: expected path to exist",
+		"response_time: took 120ms, more than 100ms",
+		"body_sha256: expected 0000000000000000000000000000000000000000000000000000000000000000, got " +
+			result.Data["body_sha256"].(string),
+	}, messages)
+}
+
+func TestRunHTTPCheck_Assertions_NonJSONBody(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.Anything).Return(&ports.HTTPResponse{StatusCode: 200, Body: []byte("OK")}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{
+		"url":        "https://example.com",
+		"assertions": []any{map[string]any{"type": "json", "path": "$.status", "exists": true}},
+	}, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsFailure())
+	assert.Contains(t, result.Message, "json $.status: body is not valid JSON")
+}
+
+func TestRunHTTPCheck_Assertions_Invalid(t *testing.T) {
+	invalid := map[string]any{
+		"not a list":       "status",
+		"missing type":     []any{map[string]any{"in": []any{200}}},
+		"unknown type":     []any{map[string]any{"type": "cookie"}},
+		"unknown field":    []any{map[string]any{"type": "status", "equal": 200}},
+		"no operator":      []any{map[string]any{"type": "json", "path": "$.a"}},
+		"bad range":        []any{map[string]any{"type": "status", "range": "299-200"}},
+		"bad regex":        []any{map[string]any{"type": "body", "matches": "("}},
+		"bad path":         []any{map[string]any{"type": "json", "path": "status", "exists": true}},
+		"header no name":   []any{map[string]any{"type": "header", "exists": true}},
+		"short sha256":     []any{map[string]any{"type": "body_sha256", "equals": "abc"}},
+		"no max response":  []any{map[string]any{"type": "response_time"}},
+		"status not a num": []any{map[string]any{"type": "status", "equals": "200"}},
+	}
+	for name, assertions := range invalid {
+		t.Run(name, func(t *testing.T) {
+			// No client: an invalid assertion must fail before any request.
+			result, err := RunHTTPCheck(context.Background(), config.Config{
+				"url":        "https://example.com",
+				"assertions": assertions,
+			})
+			require.NoError(t, err)
+			require.True(t, result.IsError())
+			assert.Equal(t, "INVALID_ASSERTION", result.Error.Code)
+		})
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"a.b":   "dotted",
+		"items": []any{map[string]any{"id": 1.0}, map[string]any{"id": 2.0}},
+	}
+	for path, want := range map[string]any{
+		"$":                doc,
+		"$.items[1].id":    2.0,
+		"$['a.b']":         "dotted",
+		`$["items"][0]`:    map[string]any{"id": 1.0},
+		"$.items[0]['id']": 1.0,
+	} {
+		steps, err := parseJSONPath(path)
+		require.NoError(t, err, path)
+		got, found := lookupJSONPath(doc, steps)
+		assert.True(t, found, path)
+		assert.Equal(t, want, got, path)
+	}
+
+	for _, path := range []string{"$.items[2]", "$.items.id", "$.missing", "$['a.b'].c"} {
+		steps, err := parseJSONPath(path)
+		require.NoError(t, err, path)
+		_, found := lookupJSONPath(doc, steps)
+		assert.False(t, found, path)
+	}
+
+	for _, path := range []string{"items", "$.", "$[", "$[-1]", "$[x]", "$items"} {
+		_, err := parseJSONPath(path)
+		assert.Error(t, err, path)
+	}
+}
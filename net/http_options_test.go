@@ -61,19 +61,24 @@ func TestNewTransport_WithHTTPTimeout_IgnoresInvalid(t *testing.T) {
 }
 
 func TestNewTransport_WithMaxRedirects(t *testing.T) {
-	// MaxRedirects is parsed into config but ignored by WASM adapter currently.
 	transport := NewTransport(WithMaxRedirects(5))
-	require.NotNil(t, transport)
+	adapter, ok := transport.(*wasm.HTTPAdapter)
+	require.True(t, ok)
+	assert.Equal(t, 5, *adapter.DefaultMaxRedirects)
 }
 
 func TestNewTransport_WithMaxRedirects_ZeroDisables(t *testing.T) {
 	transport := NewTransport(WithMaxRedirects(0))
-	require.NotNil(t, transport)
+	adapter, ok := transport.(*wasm.HTTPAdapter)
+	require.True(t, ok)
+	assert.Equal(t, 0, *adapter.DefaultMaxRedirects)
 }
 
 func TestNewTransport_WithMaxRedirects_IgnoresNegative(t *testing.T) {
 	transport := NewTransport(WithMaxRedirects(-1))
-	require.NotNil(t, transport)
+	adapter, ok := transport.(*wasm.HTTPAdapter)
+	require.True(t, ok)
+	assert.Equal(t, 10, *adapter.DefaultMaxRedirects)
 }
 
 func TestNewTransport_MultipleOptions(t *testing.T) {
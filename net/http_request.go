@@ -0,0 +1,168 @@
+package sdknet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+)
+
+// RequestOption is a functional option for building a request with NewRequest.
+type RequestOption func(*requestBuilder)
+
+type requestBuilder struct {
+	err error
+	req ports.HTTPRequest
+}
+
+// NewRequest builds a request for HTTPClient.Do.
+//
+// Example:
+//
+//	req, err := NewRequest("POST", "https://api.example.com/upload",
+//	    WithHeader("Accept", "application/json", "text/plain"),
+//	    WithQuery(url.Values{"dry_run": {"true"}}),
+//	    WithMultipartBody(
+//	        MultipartField("description", "nightly report"),
+//	        MultipartFile("report", "report.csv", file),
+//	    ),
+//	)
+func NewRequest(method, rawURL string, opts ...RequestOption) (ports.HTTPRequest, error) {
+	b := &requestBuilder{req: ports.HTTPRequest{Method: method, URL: rawURL}}
+	for _, opt := range opts {
+		opt(b)
+		if b.err != nil {
+			return ports.HTTPRequest{}, b.err
+		}
+	}
+	return b.req, nil
+}
+
+func (b *requestBuilder) addHeader(name string, values ...string) {
+	if b.req.HeaderValues == nil {
+		b.req.HeaderValues = make(map[string][]string)
+	}
+	name = textproto.CanonicalMIMEHeaderKey(name)
+	b.req.HeaderValues[name] = append(b.req.HeaderValues[name], values...)
+}
+
+func (b *requestBuilder) setBody(contentType string, body []byte, r io.Reader) {
+	if len(b.req.Body) > 0 || b.req.BodyReader != nil {
+		b.err = fmt.Errorf("request body is already set")
+		return
+	}
+	b.req.Body, b.req.BodyReader = body, r
+	if b.req.HeaderValues != nil {
+		delete(b.req.HeaderValues, "Content-Type")
+	}
+	b.addHeader("Content-Type", contentType)
+}
+
+// WithHeader adds values to the request header name. Repeated calls for the
+// same name add further values rather than replacing them.
+func WithHeader(name string, values ...string) RequestOption {
+	return func(b *requestBuilder) {
+		b.addHeader(name, values...)
+	}
+}
+
+// WithQuery adds query parameters to the request URL.
+func WithQuery(values url.Values) RequestOption {
+	return func(b *requestBuilder) {
+		if b.req.Query == nil {
+			b.req.Query = make(url.Values)
+		}
+		for k, v := range values {
+			b.req.Query[k] = append(b.req.Query[k], v...)
+		}
+	}
+}
+
+// WithBody streams the request body from r with the given content type.
+func WithBody(contentType string, r io.Reader) RequestOption {
+	return func(b *requestBuilder) {
+		b.setBody(contentType, nil, r)
+	}
+}
+
+// WithFormBody sets an application/x-www-form-urlencoded body.
+func WithFormBody(values url.Values) RequestOption {
+	return func(b *requestBuilder) {
+		b.setBody("application/x-www-form-urlencoded", []byte(values.Encode()), nil)
+	}
+}
+
+// MultipartPart is one part of a multipart/form-data body. Create parts with
+// MultipartField and MultipartFile.
+type MultipartPart struct {
+	Content     io.Reader
+	Name        string
+	FileName    string
+	ContentType string
+}
+
+// MultipartField returns a form field part.
+func MultipartField(name, value string) MultipartPart {
+	return MultipartPart{Name: name, Content: strings.NewReader(value)}
+}
+
+// MultipartFile returns a file part read from content, sent as
+// application/octet-stream unless ContentType is set on the result.
+func MultipartFile(name, fileName string, content io.Reader) MultipartPart {
+	return MultipartPart{Name: name, FileName: fileName, Content: content}
+}
+
+// WithMultipartBody sets a multipart/form-data body made of parts, in order.
+func WithMultipartBody(parts ...MultipartPart) RequestOption {
+	return func(b *requestBuilder) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, part := range parts {
+			if err := writePart(w, part); err != nil {
+				b.err = fmt.Errorf("multipart part %q: %w", part.Name, err)
+				return
+			}
+		}
+		if err := w.Close(); err != nil {
+			b.err = fmt.Errorf("multipart body: %w", err)
+			return
+		}
+		b.setBody(w.FormDataContentType(), buf.Bytes(), nil)
+	}
+}
+
+// quoteEscaper escapes quoted Content-Disposition parameters as mime/multipart does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writePart(w *multipart.Writer, part MultipartPart) error {
+	if part.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	header := make(textproto.MIMEHeader)
+	disposition := `form-data; name="` + quoteEscaper.Replace(part.Name) + `"`
+	if part.FileName != "" {
+		disposition += `; filename="` + quoteEscaper.Replace(part.FileName) + `"`
+	}
+	header.Set("Content-Disposition", disposition)
+	switch {
+	case part.ContentType != "":
+		header.Set("Content-Type", part.ContentType)
+	case part.FileName != "":
+		header.Set("Content-Type", "application/octet-stream")
+	}
+
+	pw, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if part.Content == nil {
+		return nil
+	}
+	_, err = io.Copy(pw, part.Content)
+	return err
+}
@@ -0,0 +1,109 @@
+package sdknet
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequest_HeadersAndQuery(t *testing.T) {
+	req, err := NewRequest("GET", "https://example.com/items?page=2",
+		WithHeader("accept", "application/json"),
+		WithHeader("Accept", "text/plain"),
+		WithHeader("Cookie", "a=1", "b=2"),
+		WithQuery(url.Values{"tag": {"x", "y"}}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com/items?page=2", req.URL)
+	assert.Equal(t, map[string][]string{
+		"Accept": {"application/json", "text/plain"},
+		"Cookie": {"a=1", "b=2"},
+	}, req.HeaderValues)
+	assert.Equal(t, url.Values{"tag": {"x", "y"}}, req.Query)
+}
+
+func TestNewRequest_FormBody(t *testing.T) {
+	req, err := NewRequest("POST", "https://example.com/login",
+		WithHeader("Content-Type", "text/plain"),
+		WithFormBody(url.Values{"user": {"alice"}, "scope": {"read write"}}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"application/x-www-form-urlencoded"}, req.HeaderValues["Content-Type"], "replaces the earlier content type")
+	assert.Equal(t, "scope=read+write&user=alice", string(req.Body))
+}
+
+func TestNewRequest_StreamedBody(t *testing.T) {
+	r := strings.NewReader(`{"a":1}`)
+	req, err := NewRequest("PUT", "https://example.com/doc", WithBody("application/json", r))
+	require.NoError(t, err)
+
+	assert.Same(t, r, req.BodyReader)
+	assert.Nil(t, req.Body)
+	assert.Equal(t, []string{"application/json"}, req.HeaderValues["Content-Type"])
+
+	_, err = NewRequest("PUT", "https://example.com/doc",
+		WithBody("application/json", r),
+		WithFormBody(url.Values{"a": {"1"}}),
+	)
+	assert.ErrorContains(t, err, "already set")
+}
+
+func TestNewRequest_MultipartBody(t *testing.T) {
+	report := MultipartFile("report", `daily "summary".csv`, strings.NewReader("host,status\na,up\n"))
+	report.ContentType = "text/csv"
+
+	req, err := NewRequest("POST", "https://example.com/upload",
+		WithMultipartBody(
+			MultipartField("description", "nightly report"),
+			report,
+			MultipartFile("raw", "raw.bin", strings.NewReader("\x00\x01")),
+		),
+	)
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(req.HeaderValues["Content-Type"][0])
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	mr := multipart.NewReader(strings.NewReader(string(req.Body)), params["boundary"])
+	type part struct{ name, file, contentType, content string }
+	var parts []part
+	for {
+		p, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(content)})
+	}
+	assert.Equal(t, []part{
+		{"description", "", "", "nightly report"},
+		{"report", `daily "summary".csv`, "text/csv", "host,status\na,up\n"},
+		{"raw", "raw.bin", "application/octet-stream", "\x00\x01"},
+	}, parts)
+}
+
+func TestNewRequest_MultipartErrors(t *testing.T) {
+	_, err := NewRequest("POST", "https://example.com/upload",
+		WithMultipartBody(MultipartFile("report", "r.csv", io.MultiReader(strings.NewReader("a"), errReader{}))),
+	)
+	assert.ErrorContains(t, err, `multipart part "report": read failed`)
+
+	_, err = NewRequest("POST", "https://example.com/upload", WithMultipartBody(MultipartField("", "x")))
+	assert.ErrorContains(t, err, "name is required")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
@@ -1,12 +1,26 @@
 package sdknet
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
+	"io"
+	"math/big"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/application/config"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/infrastructure/wasm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -53,6 +67,21 @@ func TestRunHTTPCheck_Validation(t *testing.T) {
 			cfg:     config.Config{"method": "GET"},
 			errCode: "MISSING_URL",
 		},
+		{
+			name:    "Negative max redirects",
+			cfg:     config.Config{"url": "https://example.com", "max_redirects": -1},
+			errCode: "INVALID_MAX_REDIRECTS",
+		},
+		{
+			name:    "Unsupported TLS version",
+			cfg:     config.Config{"url": "https://example.com", "tls_min_version": "1.4"},
+			errCode: "INVALID_TLS_CONFIG",
+		},
+		{
+			name:    "Client certificate without key",
+			cfg:     config.Config{"url": "https://example.com", "client_cert": "-----BEGIN CERTIFICATE-----"},
+			errCode: "INVALID_TLS_CONFIG",
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,7 +120,247 @@ func TestRunHTTPCheck_Mock_Success_GET(t *testing.T) {
 	assert.Equal(t, 200, result.Data["status_code"])
 	assert.Equal(t, "OK", result.Data["body"])
 	assert.Greater(t, result.Data["response_time_ms"].(int64), int64(-1))
+	assert.NotContains(t, result.Data, "redirects")
+	assert.NotContains(t, result.Data, "timings")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestRunHTTPCheck_Mock_RedirectsAndTLS(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.MatchedBy(func(req ports.HTTPRequest) bool {
+		return req.MaxRedirects != nil && *req.MaxRedirects == 0 &&
+			assert.ObjectsAreEqual(&ports.TLSConfig{
+				ServerName:         "internal",
+				RootCAs:            []byte("CA PEM"),
+				MinVersion:         tls.VersionTLS12,
+				InsecureSkipVerify: true,
+			}, req.TLS)
+	})).Return(&ports.HTTPResponse{StatusCode: 301}, nil)
+
+	cfg := config.Config{
+		"url":                  "https://internal.example.com",
+		"follow_redirects":     false,
+		"expected_status":      301,
+		"tls_server_name":      "internal",
+		"tls_min_version":      "1.2",
+		"ca_cert":              "CA PEM",
+		"insecure_skip_verify": true,
+	}
+
+	result, err := RunHTTPCheck(context.Background(), cfg, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess())
+	mockClient.AssertExpectations(t)
+}
+
+func TestRunHTTPCheck_Mock_DefaultRedirects(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.MatchedBy(func(req ports.HTTPRequest) bool {
+		return *req.MaxRedirects == 10 && req.TLS == nil
+	})).Return(&ports.HTTPResponse{StatusCode: 200}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{"url": "https://example.com"}, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess())
+	mockClient.AssertExpectations(t)
+}
+
+func TestNewTransport_RedirectsAndTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	transport := NewTransport(
+		WithMaxRedirects(0),
+		WithRootCAs([]byte("CA PEM")),
+		WithTLSConfig(&tls.Config{
+			MinVersion:         tls.VersionTLS13,
+			ServerName:         "internal",
+			InsecureSkipVerify: true,
+			Certificates:       []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		}),
+	)
+	adapter, ok := transport.(*wasm.HTTPAdapter)
+	require.True(t, ok)
+
+	require.NotNil(t, adapter.DefaultMaxRedirects)
+	assert.Equal(t, 0, *adapter.DefaultMaxRedirects)
+	require.NotNil(t, adapter.DefaultTLS)
+	assert.Equal(t, "internal", adapter.DefaultTLS.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), adapter.DefaultTLS.MinVersion)
+	assert.True(t, adapter.DefaultTLS.InsecureSkipVerify)
+	assert.Equal(t, []byte("CA PEM"), adapter.DefaultTLS.RootCAs)
+
+	_, err = tls.X509KeyPair(adapter.DefaultTLS.ClientCert, adapter.DefaultTLS.ClientKey)
+	assert.NoError(t, err, "client certificate is sent as a PEM key pair")
+
+	assert.Nil(t, NewTransport().(*wasm.HTTPAdapter).DefaultTLS, "system defaults")
+}
+
+func TestRunHTTPCheck_Mock_RedirectsAndTimings(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.Anything).Return(&ports.HTTPResponse{
+		StatusCode: 200,
+		Redirects: []ports.HTTPRedirect{
+			{URL: "http://example.com", StatusCode: 301, Location: "https://example.com/"},
+		},
+		Timings: &ports.HTTPTimings{
+			DNS:          2 * time.Millisecond,
+			Connect:      10 * time.Millisecond,
+			TLSHandshake: 20 * time.Millisecond,
+			FirstByte:    35 * time.Millisecond,
+			Total:        80 * time.Millisecond,
+		},
+	}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{"url": "http://example.com"}, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess())
+	assert.Equal(t, []map[string]any{
+		{"url": "http://example.com", "status_code": 301, "location": "https://example.com/"},
+	}, result.Data["redirects"])
+	assert.Equal(t, map[string]any{
+		"dns_ms":        int64(2),
+		"connect_ms":    int64(10),
+		"tls_ms":        int64(20),
+		"first_byte_ms": int64(35),
+		"total_ms":      int64(80),
+	}, result.Data["timings"])
+}
 
+func TestRunHTTPCheck_Mock_HeadersAndQuery(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.MatchedBy(func(req ports.HTTPRequest) bool {
+		return assert.ObjectsAreEqual(map[string][]string{
+			"Accept":    {"application/json", "text/plain"},
+			"X-Retries": {"3"},
+			"X-Debug":   {"true"},
+		}, req.HeaderValues) && req.Query.Encode() == "id=1&id=2&verbose=true"
+	})).Return(&ports.HTTPResponse{StatusCode: 200}, nil)
+
+	cfg := config.Config{
+		"url": "https://example.com/items",
+		"headers": map[string]any{
+			"Accept":    []any{"application/json", "text/plain"},
+			"X-Retries": float64(3),
+			"X-Debug":   true,
+		},
+		"query": map[string]any{"id": []any{1, 2}, "verbose": "true"},
+	}
+
+	result, err := RunHTTPCheck(context.Background(), cfg, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess())
+	mockClient.AssertExpectations(t)
+}
+
+func TestRunHTTPCheck_InvalidHeadersAndQuery(t *testing.T) {
+	for cfg, code := range map[string]string{
+		"headers": "INVALID_HEADERS",
+		"query":   "INVALID_QUERY",
+	} {
+		for _, value := range []any{"Accept: text/plain", map[string]any{"X-Nested": map[string]any{"a": "b"}}} {
+			result, err := RunHTTPCheck(context.Background(), config.Config{"url": "https://example.com", cfg: value})
+			require.NoError(t, err)
+			require.True(t, result.IsError())
+			assert.Equal(t, code, result.Error.Code)
+		}
+	}
+}
+
+// trackedBody is a streamed response body that records whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRunHTTPCheck_Mock_StreamBody(t *testing.T) {
+	artifact := bytes.Repeat([]byte("reglet"), 4<<20) // 24 MB, over the host's buffer limit
+	sum := sha256.Sum256(artifact)
+	body := &trackedBody{Reader: bytes.NewReader(artifact)}
+
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.MatchedBy(func(req ports.HTTPRequest) bool {
+		return req.StreamBody
+	})).Return(&ports.HTTPResponse{StatusCode: 200, BodyReader: body}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{
+		"url":                 "https://example.com/release.tar.gz",
+		"stream_body":         true,
+		"body_preview_length": 12,
+		"assertions": []any{
+			map[string]any{"type": "body_sha256", "equals": hex.EncodeToString(sum[:])},
+		},
+	}, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess(), result.Message)
+	assert.Equal(t, hex.EncodeToString(sum[:]), result.Data["body_sha256"])
+	assert.Equal(t, len(artifact), result.Data["body_size"])
+	assert.Equal(t, "regletreglet", result.Data["body"])
+	assert.Equal(t, true, result.Data["body_truncated"])
+	assert.True(t, body.closed)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRunHTTPCheck_Mock_StreamBodyReadError(t *testing.T) {
+	body := &trackedBody{Reader: io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))}
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.Anything).Return(&ports.HTTPResponse{StatusCode: 200, BodyReader: body}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{"url": "https://example.com", "stream_body": true}, WithHTTPClient(mockClient))
+
+	require.Error(t, err)
+	require.True(t, result.IsError())
+	assert.Equal(t, "BODY_READ_FAILED", result.Error.Code)
+	assert.Contains(t, result.Error.Message, "connection reset")
+	assert.True(t, body.closed)
+}
+
+func TestRunHTTPCheck_StreamBodyConflicts(t *testing.T) {
+	for name, cfg := range map[string]config.Config{
+		"expected_body_contains": {"expected_body_contains": "ok"},
+		"json assertion":         {"assertions": []any{map[string]any{"type": "json", "path": "$.ok", "exists": true}}},
+		"body assertion":         {"assertions": []any{map[string]any{"type": "body", "matches": "ok"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg["url"], cfg["stream_body"] = "https://example.com", true
+			result, err := RunHTTPCheck(context.Background(), cfg)
+			require.NoError(t, err)
+			require.True(t, result.IsError())
+			assert.Equal(t, "STREAM_BODY_CONFLICT", result.Error.Code)
+		})
+	}
+}
+
+func TestRunHTTPCheck_Mock_AllowTruncatedBody(t *testing.T) {
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", mock.Anything, mock.MatchedBy(func(req ports.HTTPRequest) bool {
+		return req.AllowTruncatedBody && !req.StreamBody
+	})).Return(&ports.HTTPResponse{StatusCode: 200, Body: []byte("first 10 MB"), BodyTruncated: true}, nil)
+
+	result, err := RunHTTPCheck(context.Background(), config.Config{
+		"url":                    "https://example.com/large.log",
+		"allow_truncated_body":   true,
+		"expected_body_contains": "first",
+	}, WithHTTPClient(mockClient))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsSuccess(), result.Message)
+	assert.Equal(t, true, result.Data["response_truncated"])
+	assert.Equal(t, false, result.Data["body_truncated"], "the preview holds the whole received body")
 	mockClient.AssertExpectations(t)
 }
 
@@ -85,6 +85,9 @@ func RunSMTPCheck(ctx context.Context, cfg config.Config, opts ...SMTPCheckOptio
 	metadata := entities.NewRunMetadata(start, time.Now())
 
 	if err != nil {
+		if capErr := capabilityError(err); capErr != nil {
+			return entities.ResultError(capErr.ToErrorDetail()).WithMetadata(metadata), capErr
+		}
 		// Connection failed
 		errDetail := entities.NewErrorDetail("network", err.Error()).WithCode("CONNECTION_FAILED")
 		return entities.ResultError(errDetail).WithMetadata(metadata), errDetail
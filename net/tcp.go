@@ -88,6 +88,9 @@ func RunTCPCheck(ctx context.Context, cfg config.Config, opts ...TCPCheckOption)
 	metadata := entities.NewRunMetadata(start, time.Now())
 
 	if err != nil {
+		if capErr := capabilityError(err); capErr != nil {
+			return entities.ResultError(capErr.ToErrorDetail()).WithMetadata(metadata), capErr
+		}
 		// Connection failed - convert error to ErrorDetail
 		// Note: We lost granular error codes from hostfuncs unless we parse error string
 		// or if err satisfies an interface. For now we use generic "CONNECTION_FAILED"
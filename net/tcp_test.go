@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/reglet-dev/reglet-plugin-sdk/application/config"
+	sdkerrors "github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
 	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -187,6 +188,25 @@ func TestRunTCPCheck_WithMockDialer_ConnectionFailed(t *testing.T) {
 	mockDialer.AssertExpectations(t)
 }
 
+func TestRunTCPCheck_CapabilityDenied(t *testing.T) {
+	mockDialer := new(MockTCPDialer)
+
+	denied := &sdkerrors.CapabilityError{Required: "network:outbound", Pattern: "example.com:22"}
+	mockDialer.On("DialSecure", mock.Anything, "example.com:22", 5000, false).Return(nil, denied)
+
+	cfg := config.Config{"host": "example.com", "port": 22}
+
+	result, err := RunTCPCheck(context.Background(), cfg, WithTCPDialer(mockDialer))
+
+	var capErr *sdkerrors.CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.True(t, result.IsError())
+	assert.Equal(t, "capability", result.Error.Type)
+	assert.Equal(t, "network:outbound", result.Error.Code)
+
+	mockDialer.AssertExpectations(t)
+}
+
 func TestRunTCPCheck_DefaultClient_PanicsOnNative(t *testing.T) {
 	cfg := config.Config{"host": "example.com", "port": 80}
 
@@ -0,0 +1,496 @@
+package plugintest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/errors"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/httpreq"
+)
+
+// The fakes in this file implement the domain ports in memory so plugins can be
+// exercised natively without a Reglet host. Each fake records the calls it
+// receives and answers from canned data, or from a handler func when set.
+
+var (
+	_ ports.HTTPClient    = (*FakeHTTPClient)(nil)
+	_ ports.DNSResolver   = (*FakeDNSResolver)(nil)
+	_ ports.TCPDialer     = (*FakeTCPDialer)(nil)
+	_ ports.SMTPClient    = (*FakeSMTPClient)(nil)
+	_ ports.CommandRunner = (*FakeCommandRunner)(nil)
+	_ ports.KeyValueStore = (*FakeKeyValueStore)(nil)
+	_ ports.FileSystem    = (*FakeFileSystem)(nil)
+	_ ports.Environment   = (*FakeEnvironment)(nil)
+)
+
+// FakeHTTPClient is an in-memory ports.HTTPClient.
+type FakeHTTPClient struct {
+	// Responses maps "METHOD URL" (e.g. "GET https://example.com?q=1") to a
+	// canned response. The URL includes the request's Query parameters.
+	Responses map[string]*ports.HTTPResponse
+	// Handler, if set, answers requests not found in Responses.
+	Handler func(ctx context.Context, req ports.HTTPRequest) (*ports.HTTPResponse, error)
+	// Requests records every request received, with Query applied to URL and
+	// BodyReader read into Body as the host adapter sends them.
+	Requests []ports.HTTPRequest
+	mu       sync.Mutex
+}
+
+// Do returns the canned response for the request's method and URL. Like the
+// host adapter, it first checks the URL against the declared capabilities.
+func (f *FakeHTTPClient) Do(ctx context.Context, req ports.HTTPRequest) (*ports.HTTPResponse, error) {
+	if err := capability.CheckURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	url, err := httpreq.URL(&req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := httpreq.Body(&req, httpreq.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	req.URL, req.Query = url, nil
+	req.Body, req.BodyReader = body, nil
+
+	f.mu.Lock()
+	f.Requests = append(f.Requests, req)
+	resp, ok := f.Responses[req.Method+" "+req.URL]
+	f.mu.Unlock()
+
+	if !ok {
+		if f.Handler == nil {
+			return nil, fmt.Errorf("plugintest: no fake HTTP response for %s %s", req.Method, req.URL)
+		}
+		if resp, err = f.Handler(ctx, req); err != nil || resp == nil {
+			return resp, err
+		}
+	}
+	return fakeHTTPBody(req, resp)
+}
+
+// fakeHTTPBody applies the request's body options to resp as the host
+// adapter does: a truncated body is an error unless allowed, and a streamed
+// body is served from a copy of resp with Body moved to BodyReader.
+func fakeHTTPBody(req ports.HTTPRequest, resp *ports.HTTPResponse) (*ports.HTTPResponse, error) {
+	if resp.BodyTruncated && !req.AllowTruncatedBody {
+		return nil, fmt.Errorf("response body too large: stream it with StreamBody or set AllowTruncatedBody")
+	}
+	if !req.StreamBody || resp.BodyReader != nil {
+		return resp, nil
+	}
+	streamed := *resp
+	streamed.Body, streamed.BodyReader = nil, io.NopCloser(bytes.NewReader(resp.Body))
+	return &streamed, nil
+}
+
+// Get performs a GET request.
+func (f *FakeHTTPClient) Get(ctx context.Context, url string) (*ports.HTTPResponse, error) {
+	return f.Do(ctx, ports.HTTPRequest{Method: "GET", URL: url})
+}
+
+// Post performs a POST request.
+func (f *FakeHTTPClient) Post(ctx context.Context, url string, contentType string, body []byte) (*ports.HTTPResponse, error) {
+	return f.Do(ctx, ports.HTTPRequest{
+		Method:  "POST",
+		URL:     url,
+		Headers: map[string]string{"Content-Type": contentType},
+		Body:    body,
+	})
+}
+
+// FakeDNSResolver is an in-memory ports.DNSResolver. Lookups for names
+// without an entry return an error. Like the host's resolver, it needs no
+// network grant for the queried names.
+type FakeDNSResolver struct {
+	Hosts  map[string][]string
+	CNAMEs map[string]string
+	MX     map[string][]ports.MXRecord
+	TXT    map[string][]string
+	NS     map[string][]string
+}
+
+func lookup[V any](records map[string]V, kind, name string) (V, error) {
+	v, ok := records[name]
+	if !ok {
+		var zero V
+		return zero, fmt.Errorf("plugintest: no fake %s record for %s", kind, name)
+	}
+	return v, nil
+}
+
+// LookupHost returns the fake addresses for host.
+func (f *FakeDNSResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookup(f.Hosts, "A/AAAA", host)
+}
+
+// LookupCNAME returns the fake canonical name for host.
+func (f *FakeDNSResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return lookup(f.CNAMEs, "CNAME", host)
+}
+
+// LookupMX returns the fake MX records for domain.
+func (f *FakeDNSResolver) LookupMX(ctx context.Context, domain string) ([]ports.MXRecord, error) {
+	return lookup(f.MX, "MX", domain)
+}
+
+// LookupTXT returns the fake TXT records for domain.
+func (f *FakeDNSResolver) LookupTXT(ctx context.Context, domain string) ([]string, error) {
+	return lookup(f.TXT, "TXT", domain)
+}
+
+// LookupNS returns the fake NS records for domain.
+func (f *FakeDNSResolver) LookupNS(ctx context.Context, domain string) ([]string, error) {
+	return lookup(f.NS, "NS", domain)
+}
+
+// FakeTCPDialer is an in-memory ports.TCPDialer. Addresses without an entry
+// in Conns fail to connect.
+type FakeTCPDialer struct {
+	// Conns maps "host:port" to the connection returned when dialing it.
+	Conns map[string]*FakeTCPConnection
+}
+
+// Dial returns the fake connection for address.
+func (f *FakeTCPDialer) Dial(ctx context.Context, address string) (ports.TCPConnection, error) {
+	return f.DialSecure(ctx, address, 0, false)
+}
+
+// DialWithTimeout returns the fake connection for address.
+func (f *FakeTCPDialer) DialWithTimeout(ctx context.Context, address string, timeoutMs int) (ports.TCPConnection, error) {
+	return f.DialSecure(ctx, address, timeoutMs, false)
+}
+
+// DialSecure returns the fake connection for address. It fails if the
+// declared capabilities do not allow address, or if tls is requested and the
+// connection is not marked as TLS.
+func (f *FakeTCPDialer) DialSecure(ctx context.Context, address string, _ int, tls bool) (ports.TCPConnection, error) {
+	if err := capability.CheckAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	conn, ok := f.Conns[address]
+	if !ok {
+		return nil, fmt.Errorf("plugintest: connection refused: %s", address)
+	}
+	if tls && !conn.TLS {
+		return nil, fmt.Errorf("plugintest: TLS handshake failed: %s", address)
+	}
+	return conn, nil
+}
+
+// FakeTCPConnection is a ports.TCPConnection with fixed properties.
+type FakeTCPConnection struct {
+	CertNotAfter *time.Time
+	Remote       string
+	Local        string
+	Version      string
+	CipherSuite  string
+	ServerName   string
+	CertSubject  string
+	CertIssuer   string
+	TLS          bool
+	closed       bool
+}
+
+func (c *FakeTCPConnection) Close() error                { c.closed = true; return nil }
+func (c *FakeTCPConnection) RemoteAddr() string          { return c.Remote }
+func (c *FakeTCPConnection) IsConnected() bool           { return !c.closed }
+func (c *FakeTCPConnection) LocalAddr() string           { return c.Local }
+func (c *FakeTCPConnection) IsTLS() bool                 { return c.TLS }
+func (c *FakeTCPConnection) TLSVersion() string          { return c.Version }
+func (c *FakeTCPConnection) TLSCipherSuite() string      { return c.CipherSuite }
+func (c *FakeTCPConnection) TLSServerName() string       { return c.ServerName }
+func (c *FakeTCPConnection) TLSCertSubject() string      { return c.CertSubject }
+func (c *FakeTCPConnection) TLSCertIssuer() string       { return c.CertIssuer }
+func (c *FakeTCPConnection) TLSCertNotAfter() *time.Time { return c.CertNotAfter }
+
+// FakeSMTPClient is an in-memory ports.SMTPClient.
+type FakeSMTPClient struct {
+	// Servers maps "host:port" to the result of connecting to it.
+	Servers map[string]*ports.SMTPConnectResult
+}
+
+// Connect returns the fake result for host:port, if the declared
+// capabilities allow it.
+func (f *FakeSMTPClient) Connect(ctx context.Context, host, port string, _ time.Duration, _, _ bool) (*ports.SMTPConnectResult, error) {
+	if err := capability.CheckNetwork(ctx, host, port); err != nil {
+		return nil, err
+	}
+	res, ok := f.Servers[host+":"+port]
+	if !ok {
+		return nil, fmt.Errorf("plugintest: no fake SMTP server at %s:%s", host, port)
+	}
+	return res, nil
+}
+
+// FakeCommandRunner is an in-memory ports.CommandRunner.
+type FakeCommandRunner struct {
+	// Results maps a command name to its canned result.
+	Results map[string]*ports.CommandResult
+	// Handler, if set, answers commands not found in Results.
+	Handler func(ctx context.Context, req ports.CommandRequest) (*ports.CommandResult, error)
+	// Requests records every command run.
+	Requests []ports.CommandRequest
+	mu       sync.Mutex
+}
+
+// Run returns the canned result for the command, if the declared
+// capabilities allow it.
+func (f *FakeCommandRunner) Run(ctx context.Context, req ports.CommandRequest) (*ports.CommandResult, error) {
+	if err := capability.CheckExec(ctx, req.Command); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.Requests = append(f.Requests, req)
+	res, ok := f.Results[req.Command]
+	f.mu.Unlock()
+
+	if ok {
+		return res, nil
+	}
+	if f.Handler != nil {
+		return f.Handler(ctx, req)
+	}
+	return nil, fmt.Errorf("plugintest: no fake result for command %q", req.Command)
+}
+
+// FakeKeyValueStore is an in-memory ports.KeyValueStore with revisions and
+// TTLs. The zero value is an empty store ready to use.
+type FakeKeyValueStore struct {
+	// Now returns the current time for TTL expiry. Defaults to time.Now.
+	Now     func() time.Time
+	entries map[string]ports.KeyValueEntry
+	mu      sync.Mutex
+}
+
+// now returns the fake's current time.
+func (f *FakeKeyValueStore) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+// live returns the unexpired entry for key. Callers must hold f.mu.
+func (f *FakeKeyValueStore) live(key string) (ports.KeyValueEntry, bool) {
+	e, ok := f.entries[key]
+	if ok && e.ExpiresAt != nil && !f.now().Before(*e.ExpiresAt) {
+		delete(f.entries, key)
+		return ports.KeyValueEntry{}, false
+	}
+	return e, ok
+}
+
+// put stores value under key with the next revision. Callers must hold f.mu.
+func (f *FakeKeyValueStore) put(key string, value []byte, ttl time.Duration) *ports.KeyValueEntry {
+	current, _ := f.live(key)
+	e := ports.KeyValueEntry{
+		Key:      key,
+		Value:    append([]byte(nil), value...),
+		Revision: current.Revision + 1,
+	}
+	if ttl > 0 {
+		expires := f.now().Add(ttl)
+		e.ExpiresAt = &expires
+	}
+	if f.entries == nil {
+		f.entries = make(map[string]ports.KeyValueEntry)
+	}
+	f.entries[key] = e
+	return copyEntry(e)
+}
+
+// copyEntry returns a copy of e that callers may modify.
+func copyEntry(e ports.KeyValueEntry) *ports.KeyValueEntry {
+	e.Value = append([]byte(nil), e.Value...)
+	return &e
+}
+
+// Get returns the entry for key, or nil if it is missing or expired. Like the
+// host adapter, it first checks key against the declared capabilities.
+func (f *FakeKeyValueStore) Get(ctx context.Context, key string) (*ports.KeyValueEntry, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpRead, key); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.live(key)
+	if !ok {
+		return nil, nil
+	}
+	return copyEntry(e), nil
+}
+
+// Set stores value under key after checking it like the host adapter.
+func (f *FakeKeyValueStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (*ports.KeyValueEntry, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.put(key, value, ttl), nil
+}
+
+// Delete removes key after checking it like the host adapter.
+func (f *FakeKeyValueStore) Delete(ctx context.Context, key string) error {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, key)
+	return nil
+}
+
+// List returns the unexpired keys starting with prefix, in sorted order.
+// Like the host adapter, it omits keys the declared capabilities do not allow
+// reading.
+func (f *FakeKeyValueStore) List(ctx context.Context, prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.entries {
+		if _, ok := f.live(key); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return capability.ReadableKeys(ctx, keys), nil
+}
+
+// CompareAndSwap stores value under key if its current revision equals
+// revision, after checking key like the host adapter.
+func (f *FakeKeyValueStore) CompareAndSwap(ctx context.Context, key string, revision uint64, value []byte, ttl time.Duration) (*ports.KeyValueEntry, bool, error) {
+	if err := capability.CheckKeyValue(ctx, capability.OpWrite, key); err != nil {
+		return nil, false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.live(key)
+	if current.Revision != revision {
+		if !ok {
+			return nil, false, nil
+		}
+		return copyEntry(current), false, nil
+	}
+	return f.put(key, value, ttl), true, nil
+}
+
+// FakeFileSystem is a ports.FileSystem backed by an fs.FS, typically an
+// fstest.MapFS. Absolute paths are looked up relative to the root of FS:
+//
+//	fsys := &plugintest.FakeFileSystem{FS: fstest.MapFS{
+//	    "etc/ssh/sshd_config": {Data: []byte("PermitRootLogin no\n")},
+//	}}
+type FakeFileSystem struct {
+	FS fs.FS
+}
+
+// fsPath converts an absolute path to an fs.FS path.
+func fsPath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// ReadFile returns the contents of the file at p, up to maxBytes.
+func (f *FakeFileSystem) ReadFile(_ context.Context, p string, maxBytes int64) ([]byte, error) {
+	data, err := fs.ReadFile(f.FS, fsPath(p))
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "read", Path: p, Err: err}
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, &errors.FileSystemError{
+			Operation: "read",
+			Path:      p,
+			Err:       fmt.Errorf("file exceeds size limit of %d bytes", maxBytes),
+		}
+	}
+	return data, nil
+}
+
+// Stat returns information about the file or directory at p.
+func (f *FakeFileSystem) Stat(_ context.Context, p string) (*ports.FileInfo, error) {
+	info, err := fs.Stat(f.FS, fsPath(p))
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "stat", Path: p, Err: err}
+	}
+	fi := fakeFileInfo(path.Clean(p), info)
+	return &fi, nil
+}
+
+// ReadDir returns the entries of the directory at p, sorted by name.
+func (f *FakeFileSystem) ReadDir(_ context.Context, p string) ([]ports.FileInfo, error) {
+	entries, err := fs.ReadDir(f.FS, fsPath(p))
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "readdir", Path: p, Err: err}
+	}
+	infos := make([]ports.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, &errors.FileSystemError{Operation: "readdir", Path: p, Err: err}
+		}
+		infos = append(infos, fakeFileInfo(path.Join(p, entry.Name()), info))
+	}
+	return infos, nil
+}
+
+// Glob returns the absolute paths matching pattern.
+func (f *FakeFileSystem) Glob(_ context.Context, pattern string) ([]string, error) {
+	matches, err := fs.Glob(f.FS, fsPath(pattern))
+	if err != nil {
+		return nil, &errors.FileSystemError{Operation: "glob", Path: pattern, Err: err}
+	}
+	for i, m := range matches {
+		matches[i] = "/" + m
+	}
+	return matches, nil
+}
+
+// fakeFileInfo converts an fs.FileInfo to the port type.
+func fakeFileInfo(p string, info fs.FileInfo) ports.FileInfo {
+	return ports.FileInfo{
+		ModTime: info.ModTime(),
+		Name:    info.Name(),
+		Path:    p,
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		IsDir:   info.IsDir(),
+	}
+}
+
+// FakeEnvironment is an in-memory ports.Environment.
+type FakeEnvironment struct {
+	Vars map[string]string
+}
+
+// Lookup returns the fake value of the named variable.
+func (f *FakeEnvironment) Lookup(_ context.Context, name string) (string, bool, error) {
+	value, ok := f.Vars[name]
+	return value, ok, nil
+}
+
+// List returns a copy of the fake variables.
+func (f *FakeEnvironment) List(_ context.Context) (map[string]string, error) {
+	vars := make(map[string]string, len(f.Vars))
+	for name, value := range f.Vars {
+		vars[name] = value
+	}
+	return vars, nil
+}
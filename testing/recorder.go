@@ -0,0 +1,388 @@
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/internal/capability"
+)
+
+// CapabilityRecorder records every network destination, command, path,
+// environment variable and key-value key a plugin asks the host for, so the
+// least-privilege GrantSet can be inferred from a test run. It records through
+// the SDK's capability checks, so it works with the fakes in this package and
+// with the real host adapters alike. Requests made by an operation are also
+// recorded per operation, together with the grants declared for it.
+//
+//	rec := plugintest.NewCapabilityRecorder()
+//	ctx := rec.Context(plugin.WithClient(context.Background(), fakes))
+//	res, err := Plugin.Check(ctx, input)
+//	...
+//	plugintest.AssertLeastPrivilege(t, rec, Plugin)
+type CapabilityRecorder struct {
+	used entities.GrantSet
+	ops  map[plugin.OperationInfo]*operationUsage
+	mu   sync.Mutex
+}
+
+// operationUsage is what one operation used and the grants it could use.
+type operationUsage struct {
+	used     entities.GrantSet
+	declared *entities.GrantSet
+}
+
+// NewCapabilityRecorder returns an empty recorder.
+func NewCapabilityRecorder() *CapabilityRecorder {
+	return &CapabilityRecorder{}
+}
+
+// Context returns a context that records every capability requested under it.
+// The declared grants are not enforced under this context, so calls the
+// declaration misses still run and show up in the Report.
+func (r *CapabilityRecorder) Context(ctx context.Context) context.Context {
+	return capability.WithRecorder(ctx, r.record)
+}
+
+func (r *CapabilityRecorder) record(ctx context.Context, requested *entities.GrantSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.used.Merge(explode(requested))
+
+	info, ok := plugin.OperationFromContext(ctx)
+	if !ok {
+		return
+	}
+	if r.ops == nil {
+		r.ops = make(map[plugin.OperationInfo]*operationUsage)
+	}
+	usage := r.ops[info]
+	if usage == nil {
+		usage = &operationUsage{}
+		r.ops[info] = usage
+	}
+	usage.used.Merge(explode(requested))
+	if declared, ok := capability.Declared(ctx); ok {
+		usage.declared = declared
+	}
+}
+
+// Used returns the minimal GrantSet covering everything recorded so far, with
+// exact hosts, ports, paths, commands, variables and keys in sorted order.
+func (r *CapabilityRecorder) Used() *entities.GrantSet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return compact(&r.used)
+}
+
+// UsedBy returns the minimal GrantSet covering everything the named operation
+// used, or nil if it used nothing.
+func (r *CapabilityRecorder) UsedBy(service, operation string) *entities.GrantSet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	usage := r.ops[plugin.OperationInfo{Service: service, Operation: operation}]
+	if usage == nil {
+		return nil
+	}
+	return compact(&usage.used)
+}
+
+// Report compares the recorded capabilities with declared.
+func (r *CapabilityRecorder) Report(declared *entities.GrantSet) *CapabilityReport {
+	used := r.Used()
+	report := &CapabilityReport{Used: used}
+	if missing := capability.Missing(declared, used); missing != nil {
+		report.Missing = missing
+	}
+	if overBroad := explode(declared).Difference(explode(used)); !overBroad.IsEmpty() {
+		report.OverBroad = overBroad
+	}
+	return report
+}
+
+// CapabilityReport is the result of comparing recorded with declared capabilities.
+type CapabilityReport struct {
+	// Used is the minimal GrantSet for what the plugin did.
+	Used *entities.GrantSet
+	// Missing lists what the plugin used but did not declare, honouring
+	// wildcards in the declared grants. Nil if nothing is missing.
+	Missing *entities.GrantSet
+	// OverBroad lists declared grants that were never used exactly, as found
+	// by GrantSet.Difference: unused grants, and wildcards or port ranges
+	// where the plugin only needed specific values. Nil if there are none.
+	OverBroad *entities.GrantSet
+}
+
+// OK reports whether the declared grants are exactly what was used.
+func (r *CapabilityReport) OK() bool {
+	return r.Missing == nil && r.OverBroad == nil
+}
+
+func (r *CapabilityReport) String() string {
+	var b strings.Builder
+	for _, item := range describe(r.Missing) {
+		fmt.Fprintf(&b, "missing: %s\n", item)
+	}
+	for _, item := range describe(r.OverBroad) {
+		fmt.Fprintf(&b, "over-broad: %s\n", item)
+	}
+	if b.Len() == 0 {
+		return "declared capabilities match usage\n"
+	}
+	return b.String()
+}
+
+// ReportOperations compares what each operation in manifest used with the
+// grants declared for it, keyed by "service/operation". Missing lists what an
+// operation used beyond the plugin-wide grants and its own, such as grants
+// declared only for another operation; OverBroad lists the operation's own
+// grants it never used exactly. Operations that never ran are left out.
+func (r *CapabilityRecorder) ReportOperations(manifest *entities.Manifest) map[string]*CapabilityReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reports := make(map[string]*CapabilityReport)
+	for _, svc := range manifest.Services {
+		for _, op := range svc.Operations {
+			usage := r.ops[plugin.OperationInfo{Service: svc.Name, Operation: op.Name}]
+			if usage == nil {
+				continue
+			}
+			report := &CapabilityReport{Used: compact(&usage.used)}
+			report.Missing = capability.Missing(usage.declared, &usage.used)
+			if op.Capabilities != nil {
+				if overBroad := explode(op.Capabilities).Difference(&usage.used); !overBroad.IsEmpty() {
+					report.OverBroad = overBroad
+				}
+			}
+			reports[svc.Name+"/"+op.Name] = report
+		}
+	}
+	return reports
+}
+
+// AssertLeastPrivilege asserts that the capabilities declared in p's manifest,
+// plugin-wide and per operation, are exactly those recorded by rec: nothing
+// used is undeclared and nothing declared goes unused or is broader than needed.
+// The manifest's union of grants is checked with Report, then every operation
+// that ran with ReportOperations; items already reported for the union are
+// not repeated per operation.
+func AssertLeastPrivilege(t *testing.T, rec *CapabilityRecorder, p *plugin.PluginDefinition) {
+	t.Helper()
+	manifest, err := p.Manifest(context.Background())
+	if err != nil {
+		t.Fatalf("failed to build manifest: %v", err)
+	}
+
+	report := rec.Report(&manifest.Capabilities)
+	for _, item := range describe(report.Missing) {
+		t.Errorf("capability used but not declared: %s", item)
+	}
+	for _, item := range describe(report.OverBroad) {
+		t.Errorf("capability declared but not used as declared: %s", item)
+	}
+
+	reported := explode(report.Missing)
+	reported.Merge(explode(report.OverBroad))
+	opReports := rec.ReportOperations(manifest)
+	for _, name := range sortedKeys(opReports) {
+		opReport := opReports[name]
+		if opReport.Missing != nil {
+			for _, item := range describe(explode(opReport.Missing).Difference(reported)) {
+				t.Errorf("operation %s: capability used but not declared for it: %s", name, item)
+			}
+		}
+		if opReport.OverBroad != nil {
+			for _, item := range describe(opReport.OverBroad.Difference(reported)) {
+				t.Errorf("operation %s: capability declared for it but not used as declared: %s", name, item)
+			}
+		}
+	}
+}
+
+// explode splits g into one rule per host and port, path, and key operation,
+// so GrantSet.Difference compares single items rather than whole rules.
+func explode(g *entities.GrantSet) *entities.GrantSet {
+	out := &entities.GrantSet{}
+	if g == nil {
+		return out
+	}
+	if g.Network != nil {
+		out.Network = &entities.NetworkCapability{}
+		for _, rule := range g.Network.Rules {
+			for _, host := range rule.Hosts {
+				for _, port := range rule.Ports {
+					out.Network.Rules = append(out.Network.Rules, entities.NetworkRule{Hosts: []string{host}, Ports: []string{port}})
+				}
+			}
+		}
+	}
+	if g.FS != nil {
+		out.FS = &entities.FileSystemCapability{}
+		for _, rule := range g.FS.Rules {
+			for _, p := range rule.Read {
+				out.FS.Rules = append(out.FS.Rules, entities.FileSystemRule{Read: []string{p}})
+			}
+			for _, p := range rule.Write {
+				out.FS.Rules = append(out.FS.Rules, entities.FileSystemRule{Write: []string{p}})
+			}
+		}
+	}
+	if g.Env != nil {
+		out.Env = &entities.EnvironmentCapability{Variables: append([]string(nil), g.Env.Variables...)}
+	}
+	if g.Exec != nil {
+		out.Exec = &entities.ExecCapability{Commands: append([]string(nil), g.Exec.Commands...)}
+	}
+	if g.KV != nil {
+		out.KV = &entities.KeyValueCapability{}
+		for _, rule := range g.KV.Rules {
+			ops := []string{rule.Operation}
+			if rule.Operation == capability.OpReadWrite {
+				ops = []string{capability.OpRead, capability.OpWrite}
+			}
+			for _, op := range ops {
+				for _, key := range rule.Keys {
+					out.KV.Rules = append(out.KV.Rules, entities.KeyValueRule{Operation: op, Keys: []string{key}})
+				}
+			}
+		}
+	}
+	out.Deduplicate()
+	return out
+}
+
+// compact groups the single items of an exploded GrantSet into sorted rules:
+// one network rule per host, one filesystem rule, and one key-value rule per
+// operation, merging keys both read and written into a read-write rule.
+func compact(g *entities.GrantSet) *entities.GrantSet {
+	out := &entities.GrantSet{}
+
+	if g.Network != nil && len(g.Network.Rules) > 0 {
+		ports := make(map[string][]string)
+		for _, rule := range g.Network.Rules {
+			ports[rule.Hosts[0]] = append(ports[rule.Hosts[0]], rule.Ports[0])
+		}
+		out.Network = &entities.NetworkCapability{}
+		for _, host := range sortedKeys(ports) {
+			out.Network.Rules = append(out.Network.Rules, entities.NetworkRule{Hosts: []string{host}, Ports: sortedUnique(ports[host])})
+		}
+	}
+
+	if g.FS != nil && len(g.FS.Rules) > 0 {
+		var rule entities.FileSystemRule
+		for _, r := range g.FS.Rules {
+			rule.Read = append(rule.Read, r.Read...)
+			rule.Write = append(rule.Write, r.Write...)
+		}
+		rule.Read, rule.Write = sortedUnique(rule.Read), sortedUnique(rule.Write)
+		out.FS = &entities.FileSystemCapability{Rules: []entities.FileSystemRule{rule}}
+	}
+
+	if g.Env != nil && len(g.Env.Variables) > 0 {
+		out.Env = &entities.EnvironmentCapability{Variables: sortedUnique(g.Env.Variables)}
+	}
+	if g.Exec != nil && len(g.Exec.Commands) > 0 {
+		out.Exec = &entities.ExecCapability{Commands: sortedUnique(g.Exec.Commands)}
+	}
+
+	if g.KV != nil && len(g.KV.Rules) > 0 {
+		ops := make(map[string]map[string]bool)
+		for _, rule := range g.KV.Rules {
+			key := rule.Keys[0]
+			if ops[key] == nil {
+				ops[key] = make(map[string]bool)
+			}
+			ops[key][rule.Operation] = true
+		}
+		byOp := make(map[string][]string)
+		for _, key := range sortedKeys(ops) {
+			switch {
+			case ops[key][capability.OpRead] && ops[key][capability.OpWrite]:
+				byOp[capability.OpReadWrite] = append(byOp[capability.OpReadWrite], key)
+			case ops[key][capability.OpWrite]:
+				byOp[capability.OpWrite] = append(byOp[capability.OpWrite], key)
+			default:
+				byOp[capability.OpRead] = append(byOp[capability.OpRead], key)
+			}
+		}
+		out.KV = &entities.KeyValueCapability{}
+		for _, op := range []string{capability.OpRead, capability.OpWrite, capability.OpReadWrite} {
+			if keys := byOp[op]; len(keys) > 0 {
+				out.KV.Rules = append(out.KV.Rules, entities.KeyValueRule{Operation: op, Keys: keys})
+			}
+		}
+	}
+
+	return out
+}
+
+// describe lists every single item in g, e.g. "network api.example.com:443".
+func describe(g *entities.GrantSet) []string {
+	if g == nil {
+		return nil
+	}
+	var items []string
+	g = explode(g)
+	if g.Network != nil {
+		for _, rule := range g.Network.Rules {
+			items = append(items, "network "+net.JoinHostPort(rule.Hosts[0], rule.Ports[0]))
+		}
+	}
+	if g.FS != nil {
+		for _, rule := range g.FS.Rules {
+			for _, p := range rule.Read {
+				items = append(items, "fs read "+p)
+			}
+			for _, p := range rule.Write {
+				items = append(items, "fs write "+p)
+			}
+		}
+	}
+	if g.Env != nil {
+		for _, name := range g.Env.Variables {
+			items = append(items, "env "+name)
+		}
+	}
+	if g.Exec != nil {
+		for _, cmd := range g.Exec.Commands {
+			items = append(items, "exec "+cmd)
+		}
+	}
+	if g.KV != nil {
+		for _, rule := range g.KV.Rules {
+			items = append(items, "kv "+rule.Operation+" "+rule.Keys[0])
+		}
+	}
+	return items
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedUnique(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	n := 1
+	for i := 1; i < len(out); i++ {
+		if out[i] != out[n-1] {
+			out[n] = out[i]
+			n++
+		}
+	}
+	return out[:n]
+}
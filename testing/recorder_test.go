@@ -0,0 +1,285 @@
+package plugintest_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/reglet-dev/reglet-plugin-sdk/application/plugin"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/entities"
+	"github.com/reglet-dev/reglet-plugin-sdk/domain/ports"
+	"github.com/reglet-dev/reglet-plugin-sdk/env"
+	"github.com/reglet-dev/reglet-plugin-sdk/fs"
+	"github.com/reglet-dev/reglet-plugin-sdk/kv"
+	plugintest "github.com/reglet-dev/reglet-plugin-sdk/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditClients struct {
+	HTTP   *plugintest.FakeHTTPClient
+	Runner *plugintest.FakeCommandRunner
+	Store  *plugintest.FakeKeyValueStore
+	Env    *plugintest.FakeEnvironment
+}
+
+type auditInput struct {
+	URL string `json:"url"`
+}
+
+type auditOutput struct {
+	Status int `json:"status"`
+}
+
+type auditService struct {
+	plugin.Service `name:"audit" desc:"Audit"`
+	Run            plugin.Op[auditInput, auditOutput] `desc:"Run the audit" method:"RunHandler"`
+}
+
+func (s *auditService) RunHandler(ctx context.Context, in *auditInput) (*auditOutput, error) {
+	c := plugin.GetClient[*auditClients](ctx)
+	if _, _, err := env.Lookup(ctx, "AUDIT_TOKEN", env.WithEnvironment(c.Env)); err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTP.Get(ctx, in.URL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Runner.Run(ctx, ports.CommandRequest{Command: "/usr/bin/systemctl"}); err != nil {
+		return nil, err
+	}
+	if _, err := kv.Get(ctx, "audit/last", kv.WithStore(c.Store)); err != nil {
+		return nil, err
+	}
+	if _, err := kv.Set(ctx, "audit/last", []byte("done"), kv.WithStore(c.Store)); err != nil {
+		return nil, err
+	}
+	return &auditOutput{Status: resp.StatusCode}, nil
+}
+
+func newAuditPlugin(t *testing.T, declared entities.GrantSet) *plugin.PluginDefinition {
+	t.Helper()
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "audit", Capabilities: declared})
+	require.NoError(t, plugin.RegisterService(def, &auditService{}))
+	return def
+}
+
+func runAudit(t *testing.T, def *plugin.PluginDefinition, rec *plugintest.CapabilityRecorder, urls ...string) {
+	t.Helper()
+	clients := &auditClients{
+		HTTP: &plugintest.FakeHTTPClient{Responses: map[string]*ports.HTTPResponse{
+			"GET https://api.example.com/health":       {StatusCode: 200},
+			"GET https://status.example.com:8443/ping": {StatusCode: 200},
+		}},
+		Runner: &plugintest.FakeCommandRunner{Results: map[string]*ports.CommandResult{
+			"/usr/bin/systemctl": {},
+		}},
+		Store: &plugintest.FakeKeyValueStore{},
+		Env:   &plugintest.FakeEnvironment{Vars: map[string]string{"AUDIT_TOKEN": "t"}},
+	}
+	ctx := rec.Context(plugin.WithClient(context.Background(), clients))
+	for _, url := range urls {
+		_, err := def.Check(ctx, []byte(`{"input":{"url":"`+url+`"}}`))
+		require.NoError(t, err)
+	}
+}
+
+func TestCapabilityRecorder_Used(t *testing.T) {
+	def := newAuditPlugin(t, entities.GrantSet{})
+	rec := plugintest.NewCapabilityRecorder()
+
+	runAudit(t, def, rec, "https://status.example.com:8443/ping", "https://api.example.com/health", "https://api.example.com/health")
+
+	assert.Equal(t, &entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{"api.example.com"}, Ports: []string{"443"}},
+			{Hosts: []string{"status.example.com"}, Ports: []string{"8443"}},
+		}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"AUDIT_TOKEN"}},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "read-write", Keys: []string{"audit/last"}},
+		}},
+	}, rec.Used())
+}
+
+func TestCapabilityRecorder_Report(t *testing.T) {
+	declared := entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{"*.example.com"}, Ports: []string{"443"}},
+		}},
+		FS: &entities.FileSystemCapability{Rules: []entities.FileSystemRule{
+			{Read: []string{"/etc/hosts"}},
+		}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"AUDIT_TOKEN"}},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "read-write", Keys: []string{"audit/last"}},
+		}},
+	}
+	def := newAuditPlugin(t, declared)
+	rec := plugintest.NewCapabilityRecorder()
+
+	// The first call is outside the declared grants but still runs and is recorded.
+	runAudit(t, def, rec, "https://status.example.com:8443/ping", "https://api.example.com/health")
+
+	report := rec.Report(&declared)
+	assert.False(t, report.OK())
+	assert.Equal(t, []entities.NetworkRule{{Hosts: []string{"status.example.com"}, Ports: []string{"8443"}}}, report.Missing.Network.Rules)
+	require.NotNil(t, report.OverBroad)
+	assert.Equal(t, []entities.NetworkRule{{Hosts: []string{"*.example.com"}, Ports: []string{"443"}}}, report.OverBroad.Network.Rules)
+	assert.Equal(t, []entities.FileSystemRule{{Read: []string{"/etc/hosts"}}}, report.OverBroad.FS.Rules)
+	assert.Nil(t, report.OverBroad.KV, "read-write key was both read and written")
+	assert.Equal(t, "missing: network status.example.com:8443\n"+
+		"over-broad: network *.example.com:443\n"+
+		"over-broad: fs read /etc/hosts\n", report.String())
+}
+
+func TestAssertLeastPrivilege(t *testing.T) {
+	def := newAuditPlugin(t, entities.GrantSet{
+		Network: &entities.NetworkCapability{Rules: []entities.NetworkRule{
+			{Hosts: []string{"api.example.com"}, Ports: []string{"443"}},
+		}},
+		Env:  &entities.EnvironmentCapability{Variables: []string{"AUDIT_TOKEN"}},
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+		KV: &entities.KeyValueCapability{Rules: []entities.KeyValueRule{
+			{Operation: "read", Keys: []string{"audit/last"}},
+			{Operation: "write", Keys: []string{"audit/last"}},
+		}},
+	})
+	rec := plugintest.NewCapabilityRecorder()
+
+	runAudit(t, def, rec, "https://api.example.com/health")
+
+	plugintest.AssertLeastPrivilege(t, rec, def)
+}
+
+type inventoryClients struct {
+	Runner *plugintest.FakeCommandRunner
+	Env    *plugintest.FakeEnvironment
+	FS     *plugintest.FakeFileSystem
+}
+
+type inventoryInput struct{}
+
+type inventoryOutput struct {
+	Count int `json:"count"`
+}
+
+type inventoryService struct {
+	plugin.Service `name:"inventory" desc:"Inventory"`
+	ListOp         plugin.Op[inventoryInput, inventoryOutput] `desc:"List settings" method:"List"`
+	StatusOp       plugin.Op[inventoryInput, inventoryOutput] `desc:"Unit status" method:"Status"`
+	RestartOp      plugin.Op[inventoryInput, inventoryOutput] `desc:"Restart the unit" method:"Restart"`
+}
+
+func (s *inventoryService) List(ctx context.Context, _ *inventoryInput) (*inventoryOutput, error) {
+	c := plugin.GetClient[*inventoryClients](ctx)
+	vars, err := env.List(ctx, env.WithEnvironment(c.Env))
+	if err != nil {
+		return nil, err
+	}
+	confs, err := fs.Glob(ctx, "/etc/*/*.conf", fs.WithFileSystem(c.FS))
+	if err != nil {
+		return nil, err
+	}
+	return &inventoryOutput{Count: len(vars) + len(confs)}, nil
+}
+
+func (s *inventoryService) Status(ctx context.Context, _ *inventoryInput) (*inventoryOutput, error) {
+	return s.systemctl(ctx)
+}
+
+func (s *inventoryService) Restart(ctx context.Context, _ *inventoryInput) (*inventoryOutput, error) {
+	return s.systemctl(ctx)
+}
+
+func (s *inventoryService) systemctl(ctx context.Context) (*inventoryOutput, error) {
+	c := plugin.GetClient[*inventoryClients](ctx)
+	if _, err := c.Runner.Run(ctx, ports.CommandRequest{Command: "/usr/bin/systemctl"}); err != nil {
+		return nil, err
+	}
+	return &inventoryOutput{}, nil
+}
+
+func newInventoryPlugin(t *testing.T) *plugin.PluginDefinition {
+	t.Helper()
+	plugin.RegisterServiceOp[inventoryService, inventoryInput, inventoryOutput]("RestartOp",
+		plugin.WithCapabilities(entities.GrantSet{
+			Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+		}),
+	)
+	def := plugin.DefinePlugin(plugin.PluginDef{Name: "inventory", Capabilities: entities.GrantSet{
+		FS:  &entities.FileSystemCapability{Rules: []entities.FileSystemRule{{Read: []string{"/etc/app/*.conf"}}}},
+		Env: &entities.EnvironmentCapability{Variables: []string{"APP_HOME"}},
+	}})
+	require.NoError(t, plugin.RegisterService(def, &inventoryService{}))
+	return def
+}
+
+func runInventory(t *testing.T, def *plugin.PluginDefinition, rec *plugintest.CapabilityRecorder, ops ...string) {
+	t.Helper()
+	clients := &inventoryClients{
+		Runner: &plugintest.FakeCommandRunner{Results: map[string]*ports.CommandResult{
+			"/usr/bin/systemctl": {},
+		}},
+		Env: &plugintest.FakeEnvironment{Vars: map[string]string{"APP_HOME": "/opt/app", "DB_PASSWORD": "secret"}},
+		FS: &plugintest.FakeFileSystem{FS: fstest.MapFS{
+			"etc/app/app.conf": {Data: []byte("a=1\n")},
+			"etc/db/db.conf":   {Data: []byte("b=2\n")},
+		}},
+	}
+	ctx := rec.Context(plugin.WithClient(context.Background(), clients))
+	for _, op := range ops {
+		res, err := def.Check(ctx, []byte(`{"operation":"`+op+`"}`))
+		require.NoError(t, err)
+		require.Nil(t, res.Error)
+	}
+}
+
+func TestCapabilityRecorder_ListedItems(t *testing.T) {
+	def := newInventoryPlugin(t)
+	rec := plugintest.NewCapabilityRecorder()
+
+	runInventory(t, def, rec, "list_op")
+
+	manifest, err := def.Manifest(context.Background())
+	require.NoError(t, err)
+	report := rec.Report(&manifest.Capabilities)
+	require.NotNil(t, report.Missing)
+	assert.Equal(t, []string{"DB_PASSWORD"}, report.Missing.Env.Variables, "undeclared variables are reported, not hidden")
+	assert.Equal(t, []entities.FileSystemRule{{Read: []string{"/etc", "/etc/db/db.conf"}}}, report.Missing.FS.Rules,
+		"the directory searched by Glob is reported with the undeclared match")
+	require.NotNil(t, report.OverBroad)
+	assert.Nil(t, report.OverBroad.Env, "declared variables returned by List count as used")
+}
+
+func TestCapabilityRecorder_ReportOperations(t *testing.T) {
+	def := newInventoryPlugin(t)
+	rec := plugintest.NewCapabilityRecorder()
+
+	runInventory(t, def, rec, "status_op")
+
+	assert.Equal(t, &entities.GrantSet{
+		Exec: &entities.ExecCapability{Commands: []string{"/usr/bin/systemctl"}},
+	}, rec.UsedBy("inventory", "status_op"))
+	assert.Nil(t, rec.UsedBy("inventory", "restart_op"))
+
+	manifest, err := def.Manifest(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, rec.Report(&manifest.Capabilities).Missing, "the union covers the command")
+
+	reports := rec.ReportOperations(manifest)
+	require.Contains(t, reports, "inventory/status_op")
+	assert.NotContains(t, reports, "inventory/restart_op", "operations that never ran are left out")
+	status := reports["inventory/status_op"]
+	require.NotNil(t, status.Missing, "the command is declared only for restart_op")
+	assert.Equal(t, []string{"/usr/bin/systemctl"}, status.Missing.Exec.Commands)
+
+	rec = plugintest.NewCapabilityRecorder()
+	runInventory(t, def, rec, "restart_op")
+	restart := rec.ReportOperations(manifest)["inventory/restart_op"]
+	require.NotNil(t, restart)
+	assert.Nil(t, restart.Missing)
+	assert.Nil(t, restart.OverBroad)
+}